@@ -2,21 +2,28 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/yourusername/youdu-app-mcp/internal/config"
 	"github.com/yourusername/youdu-app-mcp/internal/mcp"
 )
 
 func main() {
-	if err := run(); err != nil {
+	listen := flag.String("listen", "", "serve MCP over HTTP on this address (e.g. :8090) instead of stdio")
+	insecure := flag.Bool("insecure", false, "allow --listen on a non-loopback address without token auth")
+	flag.Parse()
+
+	if err := run(*listen, *insecure); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
+func run(listen string, insecure bool) error {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -34,7 +41,29 @@ func run() error {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
 
+	// Reload the permission policy on SIGHUP; the tool list is re-synced
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for range reload {
+			if err := cfg.ReloadPermission(); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to reload permission policy: %v\n", err)
+				continue
+			}
+			fmt.Fprintln(os.Stderr, "permission policy reloaded")
+		}
+	}()
+
 	// Run server
 	ctx := context.Background()
-	return server.Run(ctx)
+	if listen == "" {
+		return server.Run(ctx)
+	}
+
+	// HTTP transports, stopped on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return server.ListenAndServe(ctx, listen, insecure)
 }
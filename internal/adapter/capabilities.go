@@ -0,0 +1,277 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// MaxBatchOperations 单次批量请求（POST /api/v1/batch）最多包含的操作数
+const MaxBatchOperations = 100
+
+// MaxSignedBodySize 签名请求的请求体上限（校验签名前需要完整读入内存）
+const MaxSignedBodySize = 1 << 20
+
+// capabilityResources 按固定顺序列出的资源
+var capabilityResources = []permission.Resource{
+	permission.ResourceDept,
+	permission.ResourceUser,
+	permission.ResourceGroup,
+	permission.ResourceSession,
+	permission.ResourceMessage,
+}
+
+// uploadFileTypes 上传支持的文件类型（与 UploadFileInput.FileType 的 enum 一致）
+var uploadFileTypes = []string{"image", "file", "voice", "video"}
+
+// capabilityText 能力说明文字（按 config.Language 选择）
+type capabilityText struct {
+	allowAll  string // 未启用权限检查
+	noActions string // 资源不允许任何操作
+	allowed   string // 资源允许的操作
+	onlyIDs   string // 行级白名单
+	allowSend string // 消息接收者限制
+	scoped    string // 调用方 token 的 scope 限制
+}
+
+var capabilityTexts = map[string]capabilityText{
+	config.LanguageZH: {
+		allowAll:  "权限检查未启用，允许所有资源的所有操作。",
+		noActions: "%s: 不允许任何操作",
+		allowed:   "%s: 允许 %s",
+		onlyIDs:   "，仅限 ID %s",
+		allowSend: "\n消息只能发送给 allow_send 中列出的用户和部门。",
+		scoped:    "\n当前 token 的 scope 为 %s，以上操作已按 scope 过滤。",
+	},
+	config.LanguageEN: {
+		allowAll:  "Permission checks are disabled; all actions on all resources are allowed.",
+		noActions: "%s: no actions allowed",
+		allowed:   "%s: allows %s",
+		onlyIDs:   ", only IDs %s",
+		allowSend: "\nMessages can only be sent to the users and departments listed in allow_send.",
+		scoped:    "\nThe current token is limited to the scopes %s; the actions above are filtered by them.",
+	},
+}
+
+// DescribeCapabilitiesInput 获取当前能力说明的输入参数（均为可选）
+type DescribeCapabilitiesInput struct {
+	ResolveNames bool `json:"resolve_names,omitempty" jsonschema:"description=是否从通讯录解析白名单和 allow_send 中 ID 的名称（每个用户/群组 ID 需要一次有度 API 调用），默认 false"`
+}
+
+// NamedID 带名称的 ID，名称未解析（resolve_names 为 false、不存在或无读取权限）时为空
+type NamedID struct {
+	ID   string `json:"id" jsonschema:"description=ID"`
+	Name string `json:"name,omitempty" jsonschema:"description=名称"`
+}
+
+// ResourceCapability 单个资源允许的操作
+type ResourceCapability struct {
+	Resource   string    `json:"resource" jsonschema:"description=资源类型"`
+	Actions    []string  `json:"actions" jsonschema:"description=允许的操作（create/read/update/delete）"`
+	AllowList  []NamedID `json:"allowlist,omitempty" jsonschema:"description=行级白名单，只能访问这些 ID；为空表示不限制 ID"`
+	Operations []string  `json:"operations" jsonschema:"description=可以调用的工具/endpoint 名称"`
+}
+
+// AllowSendCapability 消息接收者限制
+type AllowSendCapability struct {
+	Restricted bool      `json:"restricted" jsonschema:"description=是否限制接收者；false 表示可以发送给任何用户和部门"`
+	Users      []NamedID `json:"users,omitempty" jsonschema:"description=允许接收消息的用户"`
+	Depts      []NamedID `json:"depts,omitempty" jsonschema:"description=允许接收消息的部门"`
+}
+
+// UploadCapability 文件上传限制
+type UploadCapability struct {
+	MaxSize   int64    `json:"max_size" jsonschema:"description=单个文件大小上限（字节），0 表示不限制"`
+	FileTypes []string `json:"file_types" jsonschema:"description=支持的文件类型"`
+}
+
+// LockoutCapability 认证失败锁定策略（按来源 IP 统计）
+type LockoutCapability struct {
+	MaxAttempts     int   `json:"max_attempts" jsonschema:"description=时间窗口内允许的认证失败次数，0 表示不锁定"`
+	WindowSeconds   int64 `json:"window_seconds" jsonschema:"description=失败次数统计的时间窗口（秒）"`
+	DurationSeconds int64 `json:"duration_seconds" jsonschema:"description=锁定时长（秒）"`
+}
+
+// QuotaCapability 请求大小和认证失败的限制；当前不限制调用次数和频率
+type QuotaCapability struct {
+	MaxBatchOperations int               `json:"max_batch_operations" jsonschema:"description=单次批量请求最多包含的操作数"`
+	MaxSignedBodySize  int64             `json:"max_signed_body_size" jsonschema:"description=签名请求的请求体上限（字节）"`
+	Lockout            LockoutCapability `json:"lockout" jsonschema:"description=认证失败锁定策略"`
+}
+
+// DescribeCapabilitiesOutput 当前权限策略和限制
+type DescribeCapabilitiesOutput struct {
+	PermissionEnabled bool                 `json:"permission_enabled" jsonschema:"description=是否启用权限检查；false 表示允许所有操作"`
+	Summary           string               `json:"summary" jsonschema:"description=权限策略的文字说明"`
+	Resources         []ResourceCapability `json:"resources" jsonschema:"description=各资源允许的操作"`
+	AllowSend         AllowSendCapability  `json:"allow_send" jsonschema:"description=消息接收者限制"`
+	Upload            UploadCapability     `json:"upload" jsonschema:"description=文件上传限制"`
+	Quotas            QuotaCapability      `json:"quotas" jsonschema:"description=请求大小和认证失败的限制；文件大小上限见 upload"`
+	Scopes            []string             `json:"scopes,omitempty" jsonschema:"description=调用方 token 的 scope；为空表示不限制，resources 中的操作和工具已按 scope 过滤"`
+}
+
+// DescribeCapabilities 返回当前权限策略允许的操作、行级白名单、消息接收者限制、配额和上传限制
+// 供调用方在调用前规划合法的请求；不需要任何资源权限。summary 使用 config.Language 的语言
+// ctx 中带有限制了 scope 的 token（token.NewContext）时，操作和工具按 scope 过滤
+func (a *Adapter) DescribeCapabilities(ctx context.Context, input DescribeCapabilitiesInput) (*DescribeCapabilitiesOutput, error) {
+	text, ok := capabilityTexts[a.config.Language]
+	if !ok {
+		text = capabilityTexts[config.LanguageZH]
+	}
+
+	output := &DescribeCapabilitiesOutput{
+		PermissionEnabled: !a.permission.IsAllowAll(),
+		Upload: UploadCapability{
+			MaxSize:   a.config.Upload.MaxSize,
+			FileTypes: uploadFileTypes,
+		},
+		Quotas: QuotaCapability{
+			MaxBatchOperations: MaxBatchOperations,
+			MaxSignedBodySize:  MaxSignedBodySize,
+			Lockout: LockoutCapability{
+				MaxAttempts:     a.config.Token.Lockout.MaxAttempts,
+				WindowSeconds:   int64(a.config.Token.Lockout.Window.Seconds()),
+				DurationSeconds: int64(a.config.Token.Lockout.Duration.Seconds()),
+			},
+		},
+	}
+
+	// 调用方 token 的 scope 进一步限制可以调用的操作
+	tok, scoped := token.FromContext(ctx)
+	scoped = scoped && tok.HasScopes()
+	if scoped {
+		output.Scopes = tok.Scopes
+	}
+
+	names := newNameResolver(a, input.ResolveNames)
+	var lines []string
+	for _, resource := range capabilityResources {
+		capability := ResourceCapability{
+			Resource:   string(resource),
+			Actions:    []string{},
+			Operations: []string{},
+		}
+		callable := map[permission.Action]bool{}
+		for methodName, op := range operations {
+			if op.Resource != resource || a.permission.Check(op.Resource, op.Action) != nil {
+				continue
+			}
+			name := ToSnakeCase(methodName)
+			if scoped && !tok.Allows(name, string(op.Resource), string(op.Action)) {
+				continue
+			}
+			callable[op.Action] = true
+			capability.Operations = append(capability.Operations, name)
+		}
+		sort.Strings(capability.Operations)
+		for _, action := range []permission.Action{permission.ActionCreate, permission.ActionRead, permission.ActionUpdate, permission.ActionDelete} {
+			if a.permission.Check(resource, action) == nil && (!scoped || callable[action]) {
+				capability.Actions = append(capability.Actions, string(action))
+			}
+		}
+
+		policy, _ := a.permission.GetResourcePolicy(resource)
+		if output.PermissionEnabled {
+			for _, id := range policy.AllowList {
+				capability.AllowList = append(capability.AllowList, names.resolve(ctx, resource, id))
+			}
+		}
+		if resource == permission.ResourceMessage && output.PermissionEnabled {
+			for _, id := range policy.AllowSend.Users {
+				output.AllowSend.Users = append(output.AllowSend.Users, names.resolve(ctx, permission.ResourceUser, id))
+			}
+			for _, id := range policy.AllowSend.Dept {
+				output.AllowSend.Depts = append(output.AllowSend.Depts, names.resolve(ctx, permission.ResourceDept, id))
+			}
+			output.AllowSend.Restricted = len(policy.AllowSend.Users) > 0 || len(policy.AllowSend.Dept) > 0
+		}
+
+		lines = append(lines, describeResource(capability, text))
+		output.Resources = append(output.Resources, capability)
+	}
+
+	if !output.PermissionEnabled && !scoped {
+		output.Summary = text.allowAll
+	} else {
+		output.Summary = strings.Join(lines, "\n")
+	}
+	if output.AllowSend.Restricted {
+		output.Summary += text.allowSend
+	}
+	if scoped {
+		output.Summary += fmt.Sprintf(text.scoped, strings.Join(tok.Scopes, ", "))
+	}
+	return output, nil
+}
+
+// describeResource 用一行文字描述资源允许的操作
+func describeResource(c ResourceCapability, text capabilityText) string {
+	if len(c.Actions) == 0 {
+		return fmt.Sprintf(text.noActions, c.Resource)
+	}
+	line := fmt.Sprintf(text.allowed, c.Resource, strings.Join(c.Actions, "/"))
+	if len(c.AllowList) > 0 {
+		ids := make([]string, 0, len(c.AllowList))
+		for _, item := range c.AllowList {
+			ids = append(ids, item.ID)
+		}
+		line += fmt.Sprintf(text.onlyIDs, strings.Join(ids, ", "))
+	}
+	return line
+}
+
+// nameResolver 通过有权限检查的读取方法解析用户、部门和群组名称（尽力而为）
+// 部门名称一次性从部门列表读取；用户和群组按 ID 查询，同一 ID 只查询一次
+type nameResolver struct {
+	a         *Adapter
+	enabled   bool
+	deptNames map[string]string
+	names     map[permission.Resource]map[string]string
+}
+
+func newNameResolver(a *Adapter, enabled bool) *nameResolver {
+	return &nameResolver{a: a, enabled: enabled, names: map[permission.Resource]map[string]string{}}
+}
+
+// resolve 返回带名称的 ID，未启用或无法解析时只返回 ID
+func (r *nameResolver) resolve(ctx context.Context, resource permission.Resource, id string) NamedID {
+	item := NamedID{ID: id}
+	if !r.enabled {
+		return item
+	}
+	if name, ok := r.names[resource][id]; ok {
+		item.Name = name
+		return item
+	}
+	switch resource {
+	case permission.ResourceUser:
+		if out, err := r.a.GetUser(ctx, GetUserInput{UserID: id}); err == nil {
+			item.Name = out.User.Name
+		}
+	case permission.ResourceDept:
+		if r.deptNames == nil {
+			r.deptNames = map[string]string{}
+			if out, err := r.a.GetDeptList(ctx, DeptListInput{DeptID: 0}); err == nil {
+				for _, dept := range out.Departments {
+					r.deptNames[strconv.Itoa(dept.ID)] = dept.Name
+				}
+			}
+		}
+		item.Name = r.deptNames[id]
+	case permission.ResourceGroup:
+		if out, err := r.a.GetGroupInfo(ctx, GetGroupInfoInput{GroupID: id}); err == nil {
+			item.Name = out.Group.Name
+		}
+	}
+	if r.names[resource] == nil {
+		r.names[resource] = map[string]string{}
+	}
+	r.names[resource][id] = item.Name
+	return item
+}
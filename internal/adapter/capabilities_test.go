@@ -0,0 +1,154 @@
+package adapter
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// findCapability 按资源查找能力说明
+func findCapability(t *testing.T, output *DescribeCapabilitiesOutput, resource permission.Resource) ResourceCapability {
+	t.Helper()
+	for _, c := range output.Resources {
+		if c.Resource == string(resource) {
+			return c
+		}
+	}
+	t.Fatalf("缺少资源 %s", resource)
+	return ResourceCapability{}
+}
+
+func TestDescribeCapabilities(t *testing.T) {
+	adapter := setupTestAdapter(t)
+	adapter.config.Upload.MaxSize = 1024
+	adapter.config.Token.Lockout = config.LockoutConfig{MaxAttempts: 3, Window: time.Minute, Duration: 10 * time.Minute}
+	adapter.permission.SetResourcePolicy(permission.ResourceUser, permission.ResourcePolicy{
+		Read:      true,
+		AllowList: []string{"10232"},
+	})
+	adapter.permission.SetResourcePolicy(permission.ResourceMessage, permission.ResourcePolicy{
+		Create:    true,
+		AllowSend: permission.AllowSend{Users: []string{"10232"}},
+	})
+
+	output, err := adapter.DescribeCapabilities(context.Background(), DescribeCapabilitiesInput{ResolveNames: true})
+	if err != nil {
+		t.Fatalf("获取能力说明失败: %v", err)
+	}
+
+	if !output.PermissionEnabled {
+		t.Error("期望权限检查已启用")
+	}
+
+	user := findCapability(t, output, permission.ResourceUser)
+	if !reflect.DeepEqual(user.Actions, []string{"read"}) || !reflect.DeepEqual(user.Operations, []string{"get_user"}) {
+		t.Errorf("user = %+v", user)
+	}
+	// 白名单中的 ID 附带从通讯录解析出的名称
+	if !reflect.DeepEqual(user.AllowList, []NamedID{{ID: "10232", Name: "Tc-黎明"}}) {
+		t.Errorf("user.allowlist = %+v", user.AllowList)
+	}
+
+	if !output.AllowSend.Restricted || !reflect.DeepEqual(output.AllowSend.Users, []NamedID{{ID: "10232", Name: "Tc-黎明"}}) {
+		t.Errorf("allow_send = %+v", output.AllowSend)
+	}
+	if output.Upload.MaxSize != 1024 || len(output.Upload.FileTypes) != 4 {
+		t.Errorf("upload = %+v", output.Upload)
+	}
+	if !strings.Contains(output.Summary, "user: 允许 read，仅限 ID 10232") {
+		t.Errorf("summary = %q", output.Summary)
+	}
+	if output.Quotas.MaxBatchOperations != MaxBatchOperations || output.Quotas.MaxSignedBodySize != MaxSignedBodySize {
+		t.Errorf("quotas = %+v", output.Quotas)
+	}
+	if output.Quotas.Lockout != (LockoutCapability{MaxAttempts: 3, WindowSeconds: 60, DurationSeconds: 600}) {
+		t.Errorf("quotas.lockout = %+v", output.Quotas.Lockout)
+	}
+
+	// 默认不解析名称，只返回 ID
+	output, err = adapter.DescribeCapabilities(context.Background(), DescribeCapabilitiesInput{})
+	if err != nil {
+		t.Fatalf("获取能力说明失败: %v", err)
+	}
+	if !reflect.DeepEqual(output.AllowSend.Users, []NamedID{{ID: "10232"}}) {
+		t.Errorf("未启用 resolve_names 时 allow_send = %+v", output.AllowSend)
+	}
+
+	// summary 使用配置的语言
+	adapter.config.Language = config.LanguageEN
+	output, err = adapter.DescribeCapabilities(context.Background(), DescribeCapabilitiesInput{})
+	if err != nil {
+		t.Fatalf("获取能力说明失败: %v", err)
+	}
+	if !strings.Contains(output.Summary, "user: allows read, only IDs 10232") || !strings.Contains(output.Summary, "allow_send") {
+		t.Errorf("summary = %q", output.Summary)
+	}
+
+	// 关闭权限检查后允许所有操作
+	adapter.permission.Disable()
+	output, err = adapter.DescribeCapabilities(context.Background(), DescribeCapabilitiesInput{})
+	if err != nil {
+		t.Fatalf("获取能力说明失败: %v", err)
+	}
+	if output.PermissionEnabled || len(findCapability(t, output, permission.ResourceDept).Actions) != 4 || output.AllowSend.Restricted {
+		t.Errorf("关闭权限检查后 = %+v", output)
+	}
+}
+
+func TestDescribeCapabilities_TokenScopes(t *testing.T) {
+	adapter := setupTestAdapter(t)
+	adapter.permission.Disable()
+
+	// token 只允许读取用户和调用 send_text_message
+	tok := &token.Token{Scopes: []string{"user:read", "send_text_message"}}
+	ctx := token.NewContext(context.Background(), tok)
+	output, err := adapter.DescribeCapabilities(ctx, DescribeCapabilitiesInput{})
+	if err != nil {
+		t.Fatalf("获取能力说明失败: %v", err)
+	}
+
+	if !reflect.DeepEqual(output.Scopes, tok.Scopes) {
+		t.Errorf("scopes = %v", output.Scopes)
+	}
+	user := findCapability(t, output, permission.ResourceUser)
+	if !reflect.DeepEqual(user.Actions, []string{"read"}) || !reflect.DeepEqual(user.Operations, []string{"get_user"}) {
+		t.Errorf("user = %+v", user)
+	}
+	message := findCapability(t, output, permission.ResourceMessage)
+	if !reflect.DeepEqual(message.Actions, []string{"create"}) || !reflect.DeepEqual(message.Operations, []string{"send_text_message"}) {
+		t.Errorf("message = %+v", message)
+	}
+	if dept := findCapability(t, output, permission.ResourceDept); len(dept.Actions) != 0 || len(dept.Operations) != 0 {
+		t.Errorf("dept = %+v", dept)
+	}
+	// 即使关闭了权限检查，summary 也按 scope 逐项说明
+	if !strings.Contains(output.Summary, "dept: 不允许任何操作") || !strings.Contains(output.Summary, "user:read, send_text_message") {
+		t.Errorf("summary = %q", output.Summary)
+	}
+
+	// 未限制 scope 的 token 不影响结果
+	output, err = adapter.DescribeCapabilities(token.NewContext(context.Background(), &token.Token{}), DescribeCapabilitiesInput{})
+	if err != nil {
+		t.Fatalf("获取能力说明失败: %v", err)
+	}
+	if output.Scopes != nil || len(findCapability(t, output, permission.ResourceDept).Actions) != 4 {
+		t.Errorf("未限制 scope 时 = %+v", output)
+	}
+}
+
+func TestUploadFile_MaxSize(t *testing.T) {
+	adapter := setupTestAdapter(t)
+	adapter.config.Upload.MaxSize = 10
+
+	_, err := adapter.UploadFile(context.Background(), UploadFileInput{FilePath: testdata.UploadFilePath})
+	if err == nil || !strings.Contains(err.Error(), "超过上限") {
+		t.Errorf("期望超过上传上限的错误，得到 %v", err)
+	}
+}
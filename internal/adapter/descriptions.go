@@ -0,0 +1,544 @@
+package adapter
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+)
+
+// Description 是 adapter 方法面向调用方（LLM、REST 客户端、CLI 用户）的说明
+type Description struct {
+	Summary    string   // 一句话摘要，用作工具标题和 CLI Short
+	Details    string   // 多句详细说明：做什么、何时使用、与其他方法的关系
+	Example    string   // 调用示例（JSON 参数）
+	ErrorHints []string // 常见错误及处理建议
+
+	language string
+}
+
+// localizedDescription 同一方法的中英文描述
+type localizedDescription struct {
+	zh Description
+	en Description
+}
+
+// 权限相关的通用错误提示
+const (
+	hintDeniedZH = "权限拒绝：当前权限策略不允许该资源/操作，或 ID 不在行级白名单中，需要管理员调整 permission 配置"
+	hintDeniedEN = "Permission denied: the policy does not allow this resource/action, or the ID is not in the row-level allowlist; an administrator must change the permission config"
+	hintSendZH   = "消息发送受 allowsend 限制：接收者必须在允许的用户/部门列表中"
+	hintSendEN   = "Sending is restricted by allowsend: every recipient must be in the allowed users/departments"
+)
+
+// descriptions adapter 方法名到中英文描述的映射，与 operations 覆盖相同的方法
+var descriptions = map[string]localizedDescription{
+	// 能力说明
+	"DescribeCapabilities": {
+		zh: Description{
+			Summary:    "查看当前可用的能力",
+			Details:    "返回当前权限策略允许的资源和操作、可以调用的工具、行级白名单 ID、允许接收消息的用户和部门（resolve_names 为 true 时附名称）、批量操作数、签名请求体和认证失败锁定等限制以及文件上传限制；通过 HTTP 调用时按当前 token 的 scope 过滤。summary 使用配置的语言。在规划调用前先调用此方法，避免调用被拒绝。",
+			Example:    `{}`,
+			ErrorHints: []string{"名称为空表示未设置 resolve_names、该 ID 不存在或当前策略不允许读取对应资源"},
+		},
+		en: Description{
+			Summary:    "Describe what is currently allowed",
+			Details:    "Returns the resources and actions the permission policy allows, the callable tools, row-level allowlisted IDs, the users and departments messages may be sent to (with names when resolve_names is true), the batch size, signed body size and authentication lockout limits, and the file upload limits. Over HTTP, the result is filtered by the calling token's scopes. The summary uses the configured language. Call it before planning other calls so they are not rejected.",
+			Example:    `{}`,
+			ErrorHints: []string{"An empty name means resolve_names was not set, the ID does not exist or the policy does not allow reading that resource"},
+		},
+	},
+
+	// 部门
+	"GetDeptList": {
+		zh: Description{
+			Summary:    "获取部门列表",
+			Details:    "返回指定部门下的子部门（ID、名称、父部门ID、排序）。dept_id 为 0 时从根部门开始。需要查找部门 ID 时先调用此方法，再用 get_dept_user_list 查看成员。",
+			Example:    `{"dept_id": 0}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "List departments",
+			Details:    "Returns the sub-departments (ID, name, parent ID, sort order) of a department. Use dept_id 0 to start from the root. Call this first to find a department ID, then get_dept_user_list to see its members.",
+			Example:    `{"dept_id": 0}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"GetDeptUserList": {
+		zh: Description{
+			Summary:    "获取部门成员列表",
+			Details:    "返回部门的直属成员（用户ID、姓名等）。适合在发送部门通知或维护群组成员前确认成员。",
+			Example:    `{"dept_id": 12}`,
+			ErrorHints: []string{hintDeniedZH, "部门不存在时有度返回错误码，请先用 get_dept_list 确认部门 ID"},
+		},
+		en: Description{
+			Summary:    "List department members",
+			Details:    "Returns the direct members (user ID, name, ...) of a department. Use it to check who will receive a department notice or before editing group members.",
+			Example:    `{"dept_id": 12}`,
+			ErrorHints: []string{hintDeniedEN, "YouDu returns an error code for unknown departments; look the ID up with get_dept_list"},
+		},
+	},
+	"GetDeptAliasList": {
+		zh: Description{
+			Summary:    "获取部门别名列表",
+			Details:    "返回部门 ID 与别名的对应关系。dept_id 为 0 时返回所有部门的别名，别名常用于与其他系统对接。",
+			Example:    `{"dept_id": 0}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "List department aliases",
+			Details:    "Returns the mapping between department IDs and aliases. Use dept_id 0 for all departments; aliases are usually keys shared with other systems.",
+			Example:    `{"dept_id": 0}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"CreateDept": {
+		zh: Description{
+			Summary:    "创建部门",
+			Details:    "在 parent_id 指定的父部门下创建部门，返回新部门 ID。sort_id 控制同级排序，alias 可选。",
+			Example:    `{"name": "研发部", "parent_id": 0, "sort_id": 10}`,
+			ErrorHints: []string{hintDeniedZH, "父部门不存在或同级已有同名部门时有度返回错误码"},
+		},
+		en: Description{
+			Summary:    "Create a department",
+			Details:    "Creates a department under parent_id and returns the new department ID. sort_id orders siblings; alias is optional.",
+			Example:    `{"name": "R&D", "parent_id": 0, "sort_id": 10}`,
+			ErrorHints: []string{hintDeniedEN, "YouDu returns an error code when the parent does not exist or a sibling has the same name"},
+		},
+	},
+	"UpdateDept": {
+		zh: Description{
+			Summary:    "更新部门",
+			Details:    "修改部门名称、父部门、排序或别名。只传入需要修改的字段；修改 parent_id 会移动部门。",
+			Example:    `{"dept_id": 12, "name": "平台研发部"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Update a department",
+			Details:    "Changes the name, parent, sort order or alias of a department. Pass only the fields to change; changing parent_id moves the department.",
+			Example:    `{"dept_id": 12, "name": "Platform R&D"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"DeleteDept": {
+		zh: Description{
+			Summary:    "删除部门",
+			Details:    "删除指定部门，操作不可恢复。部门下仍有成员或子部门时有度会拒绝删除。执行前请与用户确认。",
+			Example:    `{"dept_id": 12}`,
+			ErrorHints: []string{hintDeniedZH, "部门非空时删除失败，请先移走成员和子部门"},
+		},
+		en: Description{
+			Summary:    "Delete a department",
+			Details:    "Deletes a department; this cannot be undone. YouDu refuses to delete departments that still have members or sub-departments. Confirm with the user first.",
+			Example:    `{"dept_id": 12}`,
+			ErrorHints: []string{hintDeniedEN, "Deleting a non-empty department fails; move its members and sub-departments first"},
+		},
+	},
+
+	// 用户
+	"GetUser": {
+		zh: Description{
+			Summary:    "获取用户信息",
+			Details:    "按用户 ID（账号）返回姓名、性别、手机、电话、邮箱和所属部门。发送消息或修改用户前可用于确认对象。",
+			Example:    `{"user_id": "10232"}`,
+			ErrorHints: []string{hintDeniedZH, "用户不存在时有度返回错误码，用户 ID 是账号而不是姓名"},
+		},
+		en: Description{
+			Summary:    "Get a user",
+			Details:    "Returns the name, gender, mobile, phone, email and departments of a user by user ID (account). Use it to confirm who you are about to message or modify.",
+			Example:    `{"user_id": "10232"}`,
+			ErrorHints: []string{hintDeniedEN, "YouDu returns an error code for unknown users; the user ID is the account, not the display name"},
+		},
+	},
+	"CreateUser": {
+		zh: Description{
+			Summary:    "创建用户",
+			Details:    "在 dept_id 指定的部门创建用户账号。user_id 和 name 必填，gender 为 0 未知、1 男、2 女。",
+			Example:    `{"user_id": "zhangsan", "name": "张三", "dept_id": 12, "gender": 1}`,
+			ErrorHints: []string{hintDeniedZH, "user_id 已存在或部门不存在时有度返回错误码"},
+		},
+		en: Description{
+			Summary:    "Create a user",
+			Details:    "Creates a user account in department dept_id. user_id and name are required; gender is 0 unknown, 1 male, 2 female.",
+			Example:    `{"user_id": "zhangsan", "name": "Zhang San", "dept_id": 12, "gender": 1}`,
+			ErrorHints: []string{hintDeniedEN, "YouDu returns an error code when user_id already exists or the department does not exist"},
+		},
+	},
+	"UpdateUser": {
+		zh: Description{
+			Summary:    "更新用户",
+			Details:    "修改用户的姓名、性别、手机、电话或邮箱。只传入需要修改的字段。",
+			Example:    `{"user_id": "zhangsan", "mobile": "13800000000"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Update a user",
+			Details:    "Changes the name, gender, mobile, phone or email of a user. Pass only the fields to change.",
+			Example:    `{"user_id": "zhangsan", "mobile": "13800000000"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"DeleteUser": {
+		zh: Description{
+			Summary:    "删除用户",
+			Details:    "删除用户账号，操作不可恢复，用户的会话和群组成员关系随之失效。执行前请与用户确认。",
+			Example:    `{"user_id": "zhangsan"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Delete a user",
+			Details:    "Deletes a user account; this cannot be undone and the user's sessions and group memberships are lost. Confirm with the user first.",
+			Example:    `{"user_id": "zhangsan"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+
+	// 群组
+	"GetGroupList": {
+		zh: Description{
+			Summary:    "获取用户所在群组",
+			Details:    "返回 user_id 所在的群组（群组ID、名称）。需要群组 ID 时先调用此方法。",
+			Example:    `{"user_id": "10232"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "List a user's groups",
+			Details:    "Returns the groups (ID and name) that user_id belongs to. Call this first when you need a group ID.",
+			Example:    `{"user_id": "10232"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"GetGroupInfo": {
+		zh: Description{
+			Summary:    "获取群组信息",
+			Details:    "返回群组名称和成员列表。",
+			Example:    `{"group_id": "group_1"}`,
+			ErrorHints: []string{hintDeniedZH, "群组不存在时有度返回错误码"},
+		},
+		en: Description{
+			Summary:    "Get a group",
+			Details:    "Returns the name and members of a group.",
+			Example:    `{"group_id": "group_1"}`,
+			ErrorHints: []string{hintDeniedEN, "YouDu returns an error code for unknown groups"},
+		},
+	},
+	"CreateGroup": {
+		zh: Description{
+			Summary:    "创建群组",
+			Details:    "创建一个空群组并返回群组 ID，之后用 add_group_member 添加成员。",
+			Example:    `{"name": "项目组"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Create a group",
+			Details:    "Creates an empty group and returns its ID; add members with add_group_member afterwards.",
+			Example:    `{"name": "Project team"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"UpdateGroup": {
+		zh: Description{
+			Summary:    "更新群组",
+			Details:    "修改群组名称。",
+			Example:    `{"group_id": "group_1", "name": "新项目组"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Rename a group",
+			Details:    "Changes the name of a group.",
+			Example:    `{"group_id": "group_1", "name": "New project team"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"DeleteGroup": {
+		zh: Description{
+			Summary:    "删除群组",
+			Details:    "解散群组，操作不可恢复，所有成员将被移除。执行前请与用户确认。",
+			Example:    `{"group_id": "group_1"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Delete a group",
+			Details:    "Disbands a group; this cannot be undone and all members are removed. Confirm with the user first.",
+			Example:    `{"group_id": "group_1"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"AddGroupMember": {
+		zh: Description{
+			Summary:    "添加群组成员",
+			Details:    "将 members 中的用户加入群组，已在群组中的用户会被忽略。",
+			Example:    `{"group_id": "group_1", "members": ["10232", "zhangsan"]}`,
+			ErrorHints: []string{hintDeniedZH, "members 中包含不存在的用户时有度返回错误码"},
+		},
+		en: Description{
+			Summary:    "Add group members",
+			Details:    "Adds the users in members to a group; users already in the group are ignored.",
+			Example:    `{"group_id": "group_1", "members": ["10232", "zhangsan"]}`,
+			ErrorHints: []string{hintDeniedEN, "YouDu returns an error code when members contains unknown users"},
+		},
+	},
+	"DelGroupMember": {
+		zh: Description{
+			Summary:    "移除群组成员",
+			Details:    "将 members 中的用户移出群组。执行前请与用户确认。",
+			Example:    `{"group_id": "group_1", "members": ["zhangsan"]}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Remove group members",
+			Details:    "Removes the users in members from a group. Confirm with the user first.",
+			Example:    `{"group_id": "group_1", "members": ["zhangsan"]}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+
+	// 会话
+	"CreateSession": {
+		zh: Description{
+			Summary:    "创建会话",
+			Details:    "创建单人（single）或多人（group）会话并返回会话 ID。多人会话需要在 members 中列出成员，之后用 send_*_session_message 在会话中发消息。",
+			Example:    `{"title": "故障处理", "creator": "10232", "type": "group", "members": ["10232", "zhangsan"]}`,
+			ErrorHints: []string{hintDeniedZH, "type 只能是 single 或 group"},
+		},
+		en: Description{
+			Summary:    "Create a session",
+			Details:    "Creates a single or group chat session and returns its ID. Group sessions need members; then post with the send_*_session_message tools.",
+			Example:    `{"title": "Incident", "creator": "10232", "type": "group", "members": ["10232", "zhangsan"]}`,
+			ErrorHints: []string{hintDeniedEN, "type must be single or group"},
+		},
+	},
+	"GetSession": {
+		zh: Description{
+			Summary:    "获取会话信息",
+			Details:    "返回会话标题、类型、创建者和成员。",
+			Example:    `{"session_id": "session_123"}`,
+			ErrorHints: []string{hintDeniedZH, "会话不存在时有度返回错误码"},
+		},
+		en: Description{
+			Summary:    "Get a session",
+			Details:    "Returns the title, type, creator and members of a session.",
+			Example:    `{"session_id": "session_123"}`,
+			ErrorHints: []string{hintDeniedEN, "YouDu returns an error code for unknown sessions"},
+		},
+	},
+	"UpdateSession": {
+		zh: Description{
+			Summary:    "更新会话",
+			Details:    "以 op_user 的身份修改会话标题。",
+			Example:    `{"session_id": "session_123", "title": "故障复盘", "op_user": "10232"}`,
+			ErrorHints: []string{hintDeniedZH},
+		},
+		en: Description{
+			Summary:    "Update a session",
+			Details:    "Changes the session title on behalf of op_user.",
+			Example:    `{"session_id": "session_123", "title": "Postmortem", "op_user": "10232"}`,
+			ErrorHints: []string{hintDeniedEN},
+		},
+	},
+	"SendTextSessionMessage": {
+		zh: Description{
+			Summary:    "发送会话文本消息",
+			Details:    "以 sender 的身份向会话发送文本消息。每次调用都会发送一条新消息，请勿重复调用。",
+			Example:    `{"session_id": "session_123", "sender": "10232", "content": "大家好"}`,
+			ErrorHints: []string{hintDeniedZH, "sender 必须是会话成员"},
+		},
+		en: Description{
+			Summary:    "Send a text message to a session",
+			Details:    "Posts a text message to a session as sender. Every call sends a new message, so do not retry blindly.",
+			Example:    `{"session_id": "session_123", "sender": "10232", "content": "Hello"}`,
+			ErrorHints: []string{hintDeniedEN, "sender must be a member of the session"},
+		},
+	},
+	"SendImageSessionMessage": {
+		zh: Description{
+			Summary:    "发送会话图片消息",
+			Details:    "以 sender 的身份向会话发送图片。media_id 来自 upload_file（file_type=image）。",
+			Example:    `{"session_id": "session_123", "sender": "10232", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedZH, "media_id 无效或已过期时需要重新上传"},
+		},
+		en: Description{
+			Summary:    "Send an image to a session",
+			Details:    "Posts an image to a session as sender. media_id comes from upload_file with file_type=image.",
+			Example:    `{"session_id": "session_123", "sender": "10232", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedEN, "Upload again when media_id is invalid or expired"},
+		},
+	},
+	"SendFileSessionMessage": {
+		zh: Description{
+			Summary:    "发送会话文件消息",
+			Details:    "以 sender 的身份向会话发送文件。media_id 来自 upload_file。",
+			Example:    `{"session_id": "session_123", "sender": "10232", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedZH, "media_id 无效或已过期时需要重新上传"},
+		},
+		en: Description{
+			Summary:    "Send a file to a session",
+			Details:    "Posts a file to a session as sender. media_id comes from upload_file.",
+			Example:    `{"session_id": "session_123", "sender": "10232", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedEN, "Upload again when media_id is invalid or expired"},
+		},
+	},
+
+	// 消息
+	"SendTextMessage": {
+		zh: Description{
+			Summary:    "发送文本消息",
+			Details:    "以应用身份向用户和/或部门发送文本消息。to_user、to_dept 至少填写一个，多个 ID 用 | 分隔。每次调用都会发送一条新消息。",
+			Example:    `{"to_user": "10232|zhangsan", "content": "会议改到下午三点"}`,
+			ErrorHints: []string{hintDeniedZH, hintSendZH},
+		},
+		en: Description{
+			Summary:    "Send a text message",
+			Details:    "Sends a text message from the app to users and/or departments. Set to_user, to_dept or both; separate multiple IDs with |. Every call sends a new message.",
+			Example:    `{"to_user": "10232|zhangsan", "content": "The meeting moved to 3 pm"}`,
+			ErrorHints: []string{hintDeniedEN, hintSendEN},
+		},
+	},
+	"SendImageMessage": {
+		zh: Description{
+			Summary:    "发送图片消息",
+			Details:    "以应用身份发送图片。media_id 来自 upload_file（file_type=image）；本地图片可以直接用 send_file_with_upload。",
+			Example:    `{"to_user": "10232", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedZH, hintSendZH},
+		},
+		en: Description{
+			Summary:    "Send an image message",
+			Details:    "Sends an image from the app. media_id comes from upload_file with file_type=image; for local images send_file_with_upload does both steps.",
+			Example:    `{"to_user": "10232", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedEN, hintSendEN},
+		},
+	},
+	"SendFileMessage": {
+		zh: Description{
+			Summary:    "发送文件消息",
+			Details:    "以应用身份发送已上传的文件。media_id 来自 upload_file；本地文件可以直接用 send_file_with_upload。",
+			Example:    `{"to_dept": "12", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedZH, hintSendZH},
+		},
+		en: Description{
+			Summary:    "Send a file message",
+			Details:    "Sends an uploaded file from the app. media_id comes from upload_file; for local files send_file_with_upload does both steps.",
+			Example:    `{"to_dept": "12", "media_id": "media_abc"}`,
+			ErrorHints: []string{hintDeniedEN, hintSendEN},
+		},
+	},
+	"SendLinkMessage": {
+		zh: Description{
+			Summary:    "发送链接消息",
+			Details:    "发送带标题的链接。action 为 0 时在有度内置浏览器打开，为 1 时在外部浏览器打开。",
+			Example:    `{"to_user": "10232", "title": "周报", "url": "https://example.com/report", "action": 0}`,
+			ErrorHints: []string{hintDeniedZH, hintSendZH},
+		},
+		en: Description{
+			Summary:    "Send a link message",
+			Details:    "Sends a titled link. action 0 opens it in the YouDu webview, 1 in the external browser.",
+			Example:    `{"to_user": "10232", "title": "Weekly report", "url": "https://example.com/report", "action": 0}`,
+			ErrorHints: []string{hintDeniedEN, hintSendEN},
+		},
+	},
+	"SendSysMessage": {
+		zh: Description{
+			Summary:    "发送系统消息",
+			Details:    "发送带标题的系统通知，适合公告和告警。pop_duration 大于 0 时在客户端弹窗显示对应秒数。",
+			Example:    `{"to_dept": "0", "title": "系统维护", "content": "今晚 22:00 停机维护", "pop_duration": 10}`,
+			ErrorHints: []string{hintDeniedZH, hintSendZH},
+		},
+		en: Description{
+			Summary:    "Send a system message",
+			Details:    "Sends a titled system notice, suited to announcements and alerts. With pop_duration > 0 the client shows a popup for that many seconds.",
+			Example:    `{"to_dept": "0", "title": "Maintenance", "content": "Downtime tonight at 22:00", "pop_duration": 10}`,
+			ErrorHints: []string{hintDeniedEN, hintSendEN},
+		},
+	},
+	"UploadFile": {
+		zh: Description{
+			Summary:    "上传文件",
+			Details:    "上传服务器本地路径上的文件并返回 media_id，供 send_image_message、send_file_message 和会话消息使用。",
+			Example:    `{"file_path": "/tmp/report.pdf", "file_type": "file"}`,
+			ErrorHints: []string{hintDeniedZH, "file_path 是运行服务的机器上的路径，文件不存在时返回打开文件失败"},
+		},
+		en: Description{
+			Summary:    "Upload a file",
+			Details:    "Uploads a file from a local path on the server and returns a media_id for send_image_message, send_file_message and the session message tools.",
+			Example:    `{"file_path": "/tmp/report.pdf", "file_type": "file"}`,
+			ErrorHints: []string{hintDeniedEN, "file_path is a path on the machine running the server; missing files fail to open"},
+		},
+	},
+	"SendFileWithUpload": {
+		zh: Description{
+			Summary:    "上传并发送文件",
+			Details:    "一步完成上传本地文件和发送文件消息，支持进度通知和取消。",
+			Example:    `{"to_user": "10232", "file_path": "/tmp/report.pdf"}`,
+			ErrorHints: []string{hintDeniedZH, hintSendZH, "file_path 是运行服务的机器上的路径"},
+		},
+		en: Description{
+			Summary:    "Upload and send a file",
+			Details:    "Uploads a local file and sends it as a file message in one step, with progress notifications and cancellation.",
+			Example:    `{"to_user": "10232", "file_path": "/tmp/report.pdf"}`,
+			ErrorHints: []string{hintDeniedEN, hintSendEN, "file_path is a path on the machine running the server"},
+		},
+	},
+}
+
+// Describe 返回方法在指定语言（zh / en，其他值按 zh 处理）下的描述
+// 未登记的方法使用从方法名拆分出的摘要
+func Describe(methodName, language string) Description {
+	d, ok := descriptions[methodName]
+	if !ok {
+		return Description{Summary: splitMethodName(methodName), language: language}
+	}
+	if language == config.LanguageEN {
+		d.en.language = language
+		return d.en
+	}
+	d.zh.language = config.LanguageZH
+	return d.zh
+}
+
+// Text 渲染完整描述：摘要、详细说明、示例和常见错误
+func (d Description) Text() string {
+	exampleLabel, errorsLabel := "示例", "常见错误"
+	if d.language == config.LanguageEN {
+		exampleLabel, errorsLabel = "Example", "Common errors"
+	}
+
+	var b strings.Builder
+	b.WriteString(d.Summary)
+	if d.Details != "" {
+		b.WriteString("\n\n")
+		b.WriteString(d.Details)
+	}
+	if d.Example != "" {
+		b.WriteString("\n\n")
+		b.WriteString(exampleLabel)
+		b.WriteString(": ")
+		b.WriteString(d.Example)
+	}
+	if len(d.ErrorHints) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(errorsLabel)
+		b.WriteString(":")
+		for _, hint := range d.ErrorHints {
+			b.WriteString("\n- ")
+			b.WriteString(hint)
+		}
+	}
+	return b.String()
+}
+
+// splitMethodName 将 PascalCase 方法名拆分为小写单词（如 SendSysMessage -> send sys message）
+func splitMethodName(methodName string) string {
+	var words []string
+	var currentWord strings.Builder
+
+	for i, r := range methodName {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, currentWord.String())
+			currentWord.Reset()
+		}
+		currentWord.WriteRune(unicode.ToLower(r))
+	}
+	words = append(words, currentWord.String())
+
+	return strings.Join(words, " ")
+}
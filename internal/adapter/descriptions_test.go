@@ -0,0 +1,65 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+)
+
+func TestDescriptions_CoverOperations(t *testing.T) {
+	adapterType := reflect.TypeOf(&Adapter{})
+
+	for methodName := range operations {
+		method, ok := adapterType.MethodByName(methodName)
+		if !ok {
+			t.Errorf("方法 %s 不存在", methodName)
+			continue
+		}
+		if _, ok := descriptions[methodName]; !ok {
+			t.Errorf("方法 %s 缺少描述", methodName)
+			continue
+		}
+
+		for _, language := range []string{config.LanguageZH, config.LanguageEN} {
+			d := Describe(methodName, language)
+			if d.Summary == "" || d.Details == "" || d.Example == "" || len(d.ErrorHints) == 0 {
+				t.Errorf("%s (%s) 描述不完整: %+v", methodName, language, d)
+				continue
+			}
+
+			// 示例参数必须能解析为方法的输入类型
+			decoder := json.NewDecoder(bytes.NewReader([]byte(d.Example)))
+			decoder.DisallowUnknownFields()
+			input := reflect.New(method.Type.In(2)).Interface()
+			if err := decoder.Decode(input); err != nil {
+				t.Errorf("%s (%s) 示例无效: %v", methodName, language, err)
+			}
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	zh := Describe("SendSysMessage", config.LanguageZH)
+	if zh.Summary != "发送系统消息" || !strings.Contains(zh.Text(), "示例: ") {
+		t.Errorf("中文描述 = %q", zh.Text())
+	}
+
+	en := Describe("SendSysMessage", config.LanguageEN)
+	if en.Summary != "Send a system message" || !strings.Contains(en.Text(), "Common errors:") {
+		t.Errorf("英文描述 = %q", en.Text())
+	}
+
+	// 未配置语言时使用中文
+	if Describe("SendSysMessage", "").Summary != zh.Summary {
+		t.Error("期望默认使用中文描述")
+	}
+
+	// 未登记的方法从方法名生成摘要
+	if d := Describe("FooBarBaz", config.LanguageEN); d.Summary != "foo bar baz" || d.Text() != "foo bar baz" {
+		t.Errorf("未登记方法描述 = %+v", d)
+	}
+}
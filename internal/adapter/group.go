@@ -155,7 +155,7 @@ func (a *Adapter) DeleteGroup(ctx context.Context, input DeleteGroupInput) (*Del
 // AddGroupMemberInput represents input for adding group members
 type AddGroupMemberInput struct {
 	GroupID string   `json:"group_id" jsonschema:"description=Group ID,required"`
-	Members []string `json:"members" jsonschema:"description=List of member user IDs to add,required"`
+	Members []string `json:"members" jsonschema:"description=List of member user IDs to add,required,minItems=1"`
 }
 
 // AddGroupMemberOutput represents output for adding group members
@@ -188,7 +188,7 @@ func (a *Adapter) AddGroupMember(ctx context.Context, input AddGroupMemberInput)
 // DelGroupMemberInput represents input for deleting group members
 type DelGroupMemberInput struct {
 	GroupID string   `json:"group_id" jsonschema:"description=Group ID,required"`
-	Members []string `json:"members" jsonschema:"description=List of member user IDs to remove,required"`
+	Members []string `json:"members" jsonschema:"description=List of member user IDs to remove,required,minItems=1"`
 }
 
 // DelGroupMemberOutput represents output for deleting group members
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/addcnos/youdu/v2"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
 	"github.com/yourusername/youdu-app-mcp/internal/permission"
 )
 
@@ -31,10 +32,10 @@ func (a *Adapter) SendTextMessage(ctx context.Context, input SendTextMessageInpu
 
 	// 验证输入
 	if input.ToUser == "" && input.ToDept == "" {
-		return nil, fmt.Errorf("必须指定接收者：to_user 或 to_dept 至少填写一个")
+		return nil, apperr.New(apperr.CodeInvalidArgument, "必须指定接收者：to_user 或 to_dept 至少填写一个")
 	}
 	if input.Content == "" {
-		return nil, fmt.Errorf("消息内容不能为空")
+		return nil, apperr.New(apperr.CodeInvalidArgument, "消息内容不能为空")
 	}
 
 	req := youdu.TextMessageRequest{
@@ -48,7 +49,7 @@ func (a *Adapter) SendTextMessage(ctx context.Context, input SendTextMessageInpu
 
 	_, err := a.client.SendTextMessage(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("发送文本消息失败: %w\n提示：请检查用户ID(%s)是否正确，以及应用是否有发送消息的权限", err, input.ToUser)
+		return nil, fmt.Errorf("发送文本消息失败: %w", err)
 	}
 
 	return &SendTextMessageOutput{
@@ -138,7 +139,7 @@ type SendLinkMessageInput struct {
 	ToDept string `json:"to_dept" jsonschema:"description=Target department ID (use pipe | to separate multiple departments)"`
 	Title  string `json:"title" jsonschema:"description=Link title,required"`
 	URL    string `json:"url" jsonschema:"description=Link URL,required"`
-	Action int    `json:"action" jsonschema:"description=Action type (0:webview 1:open external browser),default=0"`
+	Action int    `json:"action" jsonschema:"description=Action type (0:webview 1:open external browser),enum=0,enum=1,default=0"`
 }
 
 // SendLinkMessageOutput represents output for sending link message
@@ -180,7 +181,7 @@ type SendSysMessageInput struct {
 	ToDept      string `json:"to_dept" jsonschema:"description=Target department ID (use pipe | to separate multiple departments)"`
 	Title       string `json:"title" jsonschema:"description=System message title,required"`
 	Content     string `json:"content" jsonschema:"description=System message content,required"`
-	PopDuration int    `json:"pop_duration" jsonschema:"description=Pop window duration in seconds,default=0"`
+	PopDuration int    `json:"pop_duration" jsonschema:"description=Pop window duration in seconds,minimum=0,default=0"`
 }
 
 // SendSysMessageOutput represents output for sending system message
@@ -226,7 +227,7 @@ func (a *Adapter) SendSysMessage(ctx context.Context, input SendSysMessageInput)
 type UploadFileInput struct {
 	FilePath string `json:"file_path" jsonschema:"description=Path to the file to upload,required"`
 	FileName string `json:"file_name" jsonschema:"description=Name of the file (with extension). If not provided, will be extracted from file path"`
-	FileType string `json:"file_type" jsonschema:"description=Type of file: image, file, voice, video,default=file"`
+	FileType string `json:"file_type" jsonschema:"description=Type of file,enum=image,enum=file,enum=voice,enum=video,default=file"`
 }
 
 // UploadFileOutput represents output for uploading file
@@ -244,7 +245,7 @@ func (a *Adapter) UploadFile(ctx context.Context, input UploadFileInput) (*Uploa
 
 	// 验证输入
 	if input.FilePath == "" {
-		return nil, fmt.Errorf("文件路径不能为空")
+		return nil, apperr.New(apperr.CodeInvalidArgument, "文件路径不能为空")
 	}
 
 	// 打开文件
@@ -266,9 +267,22 @@ func (a *Adapter) UploadFile(ctx context.Context, input UploadFileInput) (*Uploa
 		fileType = "file"
 	}
 
-	// 构造上传请求
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("读取文件信息失败: %w", err)
+	}
+	if maxSize := a.config.Upload.MaxSize; maxSize > 0 && stat.Size() > maxSize {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "文件大小 %d 字节超过上限 %d 字节（upload.max_size）", stat.Size(), maxSize)
+	}
+
+	// 构造上传请求，读取文件时报告进度
 	req := youdu.UploadMediaRequest{
-		File:     file,
+		File: &progressReader{
+			ctx:     ctx,
+			r:       file,
+			size:    stat.Size(),
+			message: "上传文件 " + fileName,
+		},
 		FileName: fileName,
 		FileType: youdu.FileType(fileType),
 	}
@@ -291,7 +305,7 @@ type SendFileWithUploadInput struct {
 	ToDept   string `json:"to_dept" jsonschema:"description=Target department ID (use pipe | to separate multiple departments)"`
 	FilePath string `json:"file_path" jsonschema:"description=Path to the file to upload and send,required"`
 	FileName string `json:"file_name" jsonschema:"description=Name of the file (with extension). If not provided, will be extracted from file path"`
-	FileType string `json:"file_type" jsonschema:"description=Type of file: image, file, voice, video,default=file"`
+	FileType string `json:"file_type" jsonschema:"description=Type of file,enum=image,enum=file,enum=voice,enum=video,default=file"`
 }
 
 // SendFileWithUploadOutput represents output for uploading and sending file message
@@ -309,10 +323,10 @@ func (a *Adapter) SendFileWithUpload(ctx context.Context, input SendFileWithUplo
 
 	// 验证输入
 	if input.ToUser == "" && input.ToDept == "" {
-		return nil, fmt.Errorf("必须指定接收者：to_user 或 to_dept 至少填写一个")
+		return nil, apperr.New(apperr.CodeInvalidArgument, "必须指定接收者：to_user 或 to_dept 至少填写一个")
 	}
 	if input.FilePath == "" {
-		return nil, fmt.Errorf("文件路径不能为空")
+		return nil, apperr.New(apperr.CodeInvalidArgument, "文件路径不能为空")
 	}
 
 	// 步骤1: 上传文件
@@ -322,12 +336,20 @@ func (a *Adapter) SendFileWithUpload(ctx context.Context, input SendFileWithUplo
 		FileType: input.FileType,
 	}
 
-	uploadOutput, err := a.UploadFile(ctx, uploadInput)
+	// 上传占总进度的 90%，发送消息占剩余部分
+	reportProgress(ctx, 0, 100, "开始上传文件")
+	uploadOutput, err := a.UploadFile(withProgressRange(ctx, 0, 90), uploadInput)
 	if err != nil {
 		return nil, fmt.Errorf("上传文件失败: %w", err)
 	}
 
+	// 客户端已取消时不再发送消息
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 步骤2: 发送文件消息
+	reportProgress(ctx, 90, 100, "发送文件消息")
 	sendInput := SendFileMessageInput{
 		ToUser:  input.ToUser,
 		ToDept:  input.ToDept,
@@ -338,6 +360,7 @@ func (a *Adapter) SendFileWithUpload(ctx context.Context, input SendFileWithUplo
 	if err != nil {
 		return nil, fmt.Errorf("发送文件消息失败: %w", err)
 	}
+	reportProgress(ctx, 100, 100, "文件消息已发送")
 
 	return &SendFileWithUploadOutput{
 		MediaID: uploadOutput.MediaID,
@@ -0,0 +1,88 @@
+package adapter
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+)
+
+// Operation 描述一个 adapter 方法对应的资源和操作类型
+// 与各方法内部的权限检查保持一致，供 API / MCP 等上层在调用前判断
+type Operation struct {
+	Resource permission.Resource
+	Action   permission.Action
+}
+
+// operations adapter 方法名到资源/操作的映射
+var operations = map[string]Operation{
+	// 部门
+	"GetDeptList":      {permission.ResourceDept, permission.ActionRead},
+	"GetDeptUserList":  {permission.ResourceDept, permission.ActionRead},
+	"GetDeptAliasList": {permission.ResourceDept, permission.ActionRead},
+	"CreateDept":       {permission.ResourceDept, permission.ActionCreate},
+	"UpdateDept":       {permission.ResourceDept, permission.ActionUpdate},
+	"DeleteDept":       {permission.ResourceDept, permission.ActionDelete},
+
+	// 用户
+	"GetUser":    {permission.ResourceUser, permission.ActionRead},
+	"CreateUser": {permission.ResourceUser, permission.ActionCreate},
+	"UpdateUser": {permission.ResourceUser, permission.ActionUpdate},
+	"DeleteUser": {permission.ResourceUser, permission.ActionDelete},
+
+	// 群组
+	"GetGroupList":   {permission.ResourceGroup, permission.ActionRead},
+	"GetGroupInfo":   {permission.ResourceGroup, permission.ActionRead},
+	"CreateGroup":    {permission.ResourceGroup, permission.ActionCreate},
+	"UpdateGroup":    {permission.ResourceGroup, permission.ActionUpdate},
+	"DeleteGroup":    {permission.ResourceGroup, permission.ActionDelete},
+	"AddGroupMember": {permission.ResourceGroup, permission.ActionUpdate},
+	"DelGroupMember": {permission.ResourceGroup, permission.ActionUpdate},
+
+	// 会话
+	"CreateSession":           {permission.ResourceSession, permission.ActionCreate},
+	"GetSession":              {permission.ResourceSession, permission.ActionRead},
+	"UpdateSession":           {permission.ResourceSession, permission.ActionUpdate},
+	"SendTextSessionMessage":  {permission.ResourceSession, permission.ActionUpdate},
+	"SendImageSessionMessage": {permission.ResourceSession, permission.ActionUpdate},
+	"SendFileSessionMessage":  {permission.ResourceSession, permission.ActionUpdate},
+
+	// 消息
+	"SendTextMessage":    {permission.ResourceMessage, permission.ActionCreate},
+	"SendImageMessage":   {permission.ResourceMessage, permission.ActionCreate},
+	"SendFileMessage":    {permission.ResourceMessage, permission.ActionCreate},
+	"SendLinkMessage":    {permission.ResourceMessage, permission.ActionCreate},
+	"SendSysMessage":     {permission.ResourceMessage, permission.ActionCreate},
+	"UploadFile":         {permission.ResourceMessage, permission.ActionCreate},
+	"SendFileWithUpload": {permission.ResourceMessage, permission.ActionCreate},
+}
+
+// LookupOperation 返回 adapter 方法对应的资源和操作类型
+func LookupOperation(methodName string) (Operation, bool) {
+	op, ok := operations[methodName]
+	return op, ok
+}
+
+// unscopedMethods 不需要任何资源权限的方法，任何 token 都可以调用（不受 scope 限制）
+var unscopedMethods = map[string]bool{
+	"DescribeCapabilities": true,
+}
+
+// RequiresScope 判断调用 adapter 方法是否需要 token 的 scope 允许
+// 能力说明不需要：限制了 scope 的 token 也能查询自己可以调用哪些操作
+func RequiresScope(methodName string) bool {
+	return !unscopedMethods[methodName]
+}
+
+// ToSnakeCase 将方法名转换为工具/endpoint 名称（PascalCase -> snake_case）
+// MCP 工具名、HTTP API 路径和能力说明共用，保证三者一致
+func ToSnakeCase(s string) string {
+	var result strings.Builder
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) {
+			result.WriteRune('_')
+		}
+		result.WriteRune(unicode.ToLower(r))
+	}
+	return result.String()
+}
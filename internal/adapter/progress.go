@@ -0,0 +1,64 @@
+package adapter
+
+import (
+	"context"
+	"io"
+)
+
+// ProgressFunc 接收长时间操作的进度，progress 单调递增，total 为 0 表示总量未知
+type ProgressFunc func(progress, total float64, message string)
+
+type progressKey struct{}
+
+// WithProgress 返回携带进度回调的上下文
+// 多步骤方法（如 SendFileWithUpload）通过该回调报告进度；上下文取消时方法会尽快停止
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress 向上下文中的回调报告进度（未设置回调时忽略）
+func reportProgress(ctx context.Context, progress, total float64, message string) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		fn(progress, total, message)
+	}
+}
+
+// withProgressRange 将子步骤的进度映射到父进度的 [offset, offset+span] 区间（总量为 100）
+func withProgressRange(ctx context.Context, offset, span float64) context.Context {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || fn == nil {
+		return ctx
+	}
+	return WithProgress(ctx, func(progress, total float64, message string) {
+		if total <= 0 {
+			return
+		}
+		fn(offset+progress/total*span, 100, message)
+	})
+}
+
+// progressReader 在读取文件时按百分比报告进度，并在上下文取消后中止读取
+type progressReader struct {
+	ctx     context.Context
+	r       io.Reader
+	size    int64
+	read    int64
+	percent int64
+	message string
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.size > 0 {
+		// 每增加 1% 报告一次，避免大文件产生过多通知
+		if percent := p.read * 100 / p.size; percent > p.percent {
+			p.percent = percent
+			reportProgress(p.ctx, float64(p.read), float64(p.size), p.message)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,59 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
+)
+
+func TestSendFileWithUpload_Progress(t *testing.T) {
+	adapter := setupTestAdapter(t)
+
+	var progress []float64
+	var lastMessage string
+	ctx := WithProgress(context.Background(), func(p, total float64, message string) {
+		if total != 100 {
+			t.Errorf("total = %v, 期望 100", total)
+		}
+		progress = append(progress, p)
+		lastMessage = message
+	})
+
+	_, err := adapter.SendFileWithUpload(ctx, SendFileWithUploadInput{
+		ToUser:   "10232",
+		FilePath: testdata.UploadFilePath,
+	})
+	if err != nil {
+		t.Fatalf("上传并发送文件失败: %v", err)
+	}
+
+	if len(progress) < 3 {
+		t.Fatalf("期望至少 3 次进度通知，得到 %v", progress)
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i] < progress[i-1] {
+			t.Errorf("进度应单调递增: %v", progress)
+			break
+		}
+	}
+	if progress[len(progress)-1] != 100 || lastMessage != "文件消息已发送" {
+		t.Errorf("最后一次进度 = %v (%s)", progress[len(progress)-1], lastMessage)
+	}
+}
+
+func TestSendFileWithUpload_Cancelled(t *testing.T) {
+	adapter := setupTestAdapter(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := adapter.SendFileWithUpload(ctx, SendFileWithUploadInput{
+		ToUser:   "10232",
+		FilePath: testdata.UploadFilePath,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("期望返回 context.Canceled，得到 %v", err)
+	}
+}
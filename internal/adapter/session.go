@@ -12,7 +12,7 @@ type CreateSessionInput struct {
 	Title   string   `json:"title" jsonschema:"description=Session title,required"`
 	Creator string   `json:"creator" jsonschema:"description=Session creator user ID,required"`
 	Members []string `json:"members" jsonschema:"description=List of member user IDs"`
-	Type    string   `json:"type" jsonschema:"description=Session type (single/group),required"`
+	Type    string   `json:"type" jsonschema:"description=Session type,enum=single,enum=group,required"`
 }
 
 // CreateSessionOutput represents output for creating a session
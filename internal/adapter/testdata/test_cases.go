@@ -1,5 +1,8 @@
 package testdata
 
+// UploadFilePath 上传测试使用的文件（相对于 internal/<包> 目录）
+const UploadFilePath = "../adapter/testdata/upload.txt"
+
 // TestCase 定义统一的测试用例结构
 type TestCase struct {
 	Name        string      // 测试用例名称
@@ -168,6 +171,29 @@ var MessageTestCases = []TestCase{
 			Allowed:  true,
 		},
 	},
+	{
+		Name:        "SendFileWithUpload_Success",
+		Description: "成功上传并发送文件消息",
+		Method:      "SendFileWithUpload",
+		MockAPI:     "/cgi/media/upload",
+		Input: map[string]interface{}{
+			"to_user":   "10232",
+			"file_path": UploadFilePath,
+		},
+		Expected: map[string]interface{}{
+			"mediaId": "test_media_id",
+		},
+		ShouldError: false,
+		Permission: struct {
+			Resource string
+			Action   string
+			Allowed  bool
+		}{
+			Resource: "message",
+			Action:   "create",
+			Allowed:  true,
+		},
+	},
 }
 
 // 部门管理测试用例
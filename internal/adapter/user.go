@@ -38,7 +38,7 @@ func (a *Adapter) GetUser(ctx context.Context, input GetUserInput) (*GetUserOutp
 type CreateUserInput struct {
 	UserID   string `json:"user_id" jsonschema:"description=User ID,required"`
 	Name     string `json:"name" jsonschema:"description=User name,required"`
-	Gender   int    `json:"gender" jsonschema:"description=Gender (0:Unknown 1:Male 2:Female),default=0"`
+	Gender   int    `json:"gender" jsonschema:"description=Gender (0:Unknown 1:Male 2:Female),enum=0,enum=1,enum=2,default=0"`
 	Mobile   string `json:"mobile" jsonschema:"description=Mobile phone number"`
 	Phone    string `json:"phone" jsonschema:"description=Phone number"`
 	Email    string `json:"email" jsonschema:"description=Email address"`
@@ -82,7 +82,7 @@ func (a *Adapter) CreateUser(ctx context.Context, input CreateUserInput) (*Creat
 type UpdateUserInput struct {
 	UserID string `json:"user_id" jsonschema:"description=User ID,required"`
 	Name   string `json:"name" jsonschema:"description=User name"`
-	Gender int    `json:"gender" jsonschema:"description=Gender (0:Unknown 1:Male 2:Female)"`
+	Gender int    `json:"gender" jsonschema:"description=Gender (0:Unknown 1:Male 2:Female),enum=0,enum=1,enum=2"`
 	Mobile string `json:"mobile" jsonschema:"description=Mobile phone number"`
 	Phone  string `json:"phone" jsonschema:"description=Phone number"`
 	Email  string `json:"email" jsonschema:"description=Email address"`
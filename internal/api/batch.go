@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// batchPath 批量执行 endpoint
+const batchPath = "/api/v1/batch"
+
+// 批量执行遇到错误时的处理方式
+const (
+	batchOnErrorStop     = "stop"     // 停止执行，后续操作标记为 skipped（默认）
+	batchOnErrorContinue = "continue" // 继续执行后续操作
+)
+
+// 单个操作的执行状态
+const (
+	batchStatusOK      = "ok"
+	batchStatusError   = "error"
+	batchStatusSkipped = "skipped"
+)
+
+// batchReference 引用前面操作的输出：整个字符串为 $<序号>.<字段路径>，例如 $0.session_id、$1.groups.0.id
+var batchReference = regexp.MustCompile(`^\$(\d+)\.(.+)$`)
+
+// batchRequest 批量执行请求
+type batchRequest struct {
+	Operations []batchOperation `json:"operations" jsonschema:"description=按顺序执行的操作,required,minItems=1,maxItems=100"`
+	OnError    string           `json:"on_error" jsonschema:"description=遇到错误时停止（stop）还是继续（continue）,enum=stop,enum=continue,default=stop"`
+}
+
+// batchOperation 批量请求中的单个操作
+type batchOperation struct {
+	Endpoint string                 `json:"endpoint" jsonschema:"description=endpoint 名称（与 POST /api/v1/{endpoint} 相同）,required"`
+	Input    map[string]interface{} `json:"input" jsonschema:"description=输入参数；字符串值 $<序号>.<字段路径> 引用前面操作的输出"`
+}
+
+// batchResponse 批量执行结果
+type batchResponse struct {
+	Results   []batchResult `json:"results" jsonschema:"description=与 operations 一一对应的执行结果"`
+	Succeeded int           `json:"succeeded" jsonschema:"description=成功的操作数"`
+	Failed    int           `json:"failed" jsonschema:"description=失败的操作数"`
+	Skipped   int           `json:"skipped" jsonschema:"description=因前面的错误而跳过的操作数"`
+}
+
+// batchResult 单个操作的执行结果
+type batchResult struct {
+	Index    int                    `json:"index" jsonschema:"description=操作序号（从 0 开始）"`
+	Endpoint string                 `json:"endpoint" jsonschema:"description=endpoint 名称"`
+	Status   string                 `json:"status" jsonschema:"description=执行状态（ok/error/skipped）"`
+	Output   map[string]interface{} `json:"output,omitempty" jsonschema:"description=成功时的输出"`
+	Error    *errorResponse         `json:"error,omitempty" jsonschema:"description=失败时的错误"`
+}
+
+// registerBatchRoute 注册批量执行 endpoint
+func (s *Server) registerBatchRoute() {
+	s.router.Post(batchPath, s.handleBatch)
+}
+
+// handleBatch 按顺序执行多个操作
+// 每个操作与单独调用对应的 endpoint 一样经过 token scope 和权限策略检查
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		s.respondError(w, r, http.StatusBadRequest, apperr.CodeInvalidArgument, fmt.Sprintf("Invalid JSON: %v", err))
+		return
+	}
+	if req.OnError == "" {
+		req.OnError = batchOnErrorStop
+	}
+	if err := validateBatch(req); err != nil {
+		s.respondAppError(w, r, err)
+		return
+	}
+
+	response := s.runBatch(r, req)
+	respondJSON(w, http.StatusOK, response)
+}
+
+// validateBatch 校验批量请求本身
+func validateBatch(req batchRequest) error {
+	switch {
+	case len(req.Operations) == 0:
+		return apperr.New(apperr.CodeInvalidArgument, "operations 不能为空")
+	case len(req.Operations) > adapter.MaxBatchOperations:
+		return apperr.New(apperr.CodeInvalidArgument, "operations 最多 %d 项", adapter.MaxBatchOperations)
+	case req.OnError != batchOnErrorStop && req.OnError != batchOnErrorContinue:
+		return apperr.New(apperr.CodeInvalidArgument, "on_error 必须是 %s 或 %s", batchOnErrorStop, batchOnErrorContinue)
+	}
+	return nil
+}
+
+// runBatch 依次执行操作并收集结果
+func (s *Server) runBatch(r *http.Request, req batchRequest) batchResponse {
+	response := batchResponse{Results: make([]batchResult, len(req.Operations))}
+	stopped := false
+
+	for i, op := range req.Operations {
+		result := batchResult{Index: i, Endpoint: op.Endpoint}
+
+		if stopped {
+			result.Status = batchStatusSkipped
+			response.Skipped++
+			response.Results[i] = result
+			continue
+		}
+
+		output, err := s.runBatchOperation(r, op, response.Results[:i])
+		if err != nil {
+			body := s.errorBody(r, apperr.From(err))
+			result.Status = batchStatusError
+			result.Error = &body
+			response.Failed++
+			stopped = req.OnError == batchOnErrorStop
+		} else {
+			result.Status = batchStatusOK
+			result.Output = output
+			response.Succeeded++
+		}
+		response.Results[i] = result
+	}
+
+	return response
+}
+
+// runBatchOperation 解析引用并执行单个操作，输出转换为 JSON 对象以便后续操作引用
+func (s *Server) runBatchOperation(r *http.Request, op batchOperation, previous []batchResult) (map[string]interface{}, error) {
+	methodName, ok := s.methods[op.Endpoint]
+	if !ok {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "未知的 endpoint '%s'", op.Endpoint)
+	}
+
+	// 与单独调用 endpoint 相同的 token scope 检查
+	if tok, ok := token.FromContext(r.Context()); ok {
+		if err := s.checkTokenScope(tok, op.Endpoint); err != nil {
+			return nil, apperr.Wrap(apperr.CodePermissionDenied, err, "")
+		}
+	}
+
+	resolved, err := resolveReferences(op.Input, previous)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "无法编码输入参数")
+	}
+
+	method, _ := reflect.TypeOf(s.adapter).MethodByName(methodName)
+	input := reflect.New(method.Type.In(2)).Interface()
+	if err := json.Unmarshal(raw, input); err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "输入参数无效")
+	}
+
+	output, err := s.invoke(r.Context(), methodName, input)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolveReferences 将输入中的 $<序号>.<字段路径> 替换为前面操作输出中的值（保留原类型）
+func resolveReferences(value interface{}, previous []batchResult) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		match := batchReference.FindStringSubmatch(v)
+		if match == nil {
+			return v, nil
+		}
+		// 序号超出 int 范围时 Atoi 返回错误
+		index, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, apperr.New(apperr.CodeInvalidArgument, "引用 %s 的序号无效", v)
+		}
+		if index >= len(previous) {
+			return nil, apperr.New(apperr.CodeInvalidArgument, "引用 %s 指向当前或之后的操作", v)
+		}
+		if previous[index].Status != batchStatusOK {
+			return nil, apperr.New(apperr.CodeInvalidArgument, "引用 %s 指向未成功执行的操作", v)
+		}
+		resolved, ok := lookupPath(previous[index].Output, strings.Split(match[2], "."))
+		if !ok {
+			return nil, apperr.New(apperr.CodeInvalidArgument, "引用 %s 在操作 %d 的输出中不存在", v, index)
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := resolveReferences(item, previous)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := resolveReferences(item, previous)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// lookupPath 按字段路径（对象字段名或数组下标）查找值
+func lookupPath(value interface{}, path []string) (interface{}, bool) {
+	for _, key := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
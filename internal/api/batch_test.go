@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// postBatch 发送批量请求并解析结果
+func postBatch(t *testing.T, server *Server, body string, ctx context.Context) (int, batchResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", batchPath, bytes.NewBufferString(body))
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	var response batchResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+	}
+	return w.Code, response
+}
+
+// statuses 返回每个操作的执行状态
+func statuses(response batchResponse) []string {
+	result := make([]string, len(response.Results))
+	for i, item := range response.Results {
+		result[i] = item.Status
+	}
+	return result
+}
+
+// TestBatch_References 测试引用前面操作的输出
+func TestBatch_References(t *testing.T) {
+	server := setupTestServer(t)
+
+	code, response := postBatch(t, server, `{"operations": [
+		{"endpoint": "get_user", "input": {"user_id": "10232"}},
+		{"endpoint": "get_user", "input": {"user_id": "$0.user.userId"}}
+	]}`, nil)
+
+	if code != http.StatusOK {
+		t.Fatalf("期望状态码 %d, 得到 %d", http.StatusOK, code)
+	}
+	if response.Succeeded != 2 || response.Failed != 0 {
+		t.Fatalf("期望全部成功, 得到 %+v", response)
+	}
+	user, _ := response.Results[1].Output["user"].(map[string]interface{})
+	if user["name"] != "Tc-黎明" {
+		t.Errorf("第二个操作的输出不正确: %v", response.Results[1].Output)
+	}
+}
+
+// TestBatch_OnError 测试遇到错误时停止或继续
+func TestBatch_OnError(t *testing.T) {
+	server := setupTestServer(t)
+
+	operations := `[
+		{"endpoint": "create_user", "input": {"user_id": "u1", "name": "n", "dept_id": 1}},
+		{"endpoint": "get_user", "input": {"user_id": "10232"}}
+	]`
+
+	tests := []struct {
+		name    string
+		onError string
+		want    []string
+	}{
+		{"默认停止", ``, []string{batchStatusError, batchStatusSkipped}},
+		{"继续执行", `, "on_error": "continue"`, []string{batchStatusError, batchStatusOK}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, response := postBatch(t, server, `{"operations": `+operations+tt.onError+`}`, nil)
+			if code != http.StatusOK {
+				t.Fatalf("期望状态码 %d, 得到 %d", http.StatusOK, code)
+			}
+
+			got := statuses(response)
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("期望状态 %v, 得到 %v", tt.want, got)
+			}
+
+			// 失败的操作带有与单独调用相同的错误码
+			if err := response.Results[0].Error; err == nil || err.Code != apperr.CodePermissionDenied {
+				t.Errorf("期望 permission_denied, 得到 %+v", err)
+			}
+		})
+	}
+}
+
+// TestBatch_InvalidOperations 测试无效的操作和引用
+func TestBatch_InvalidOperations(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		name      string
+		operation string
+	}{
+		{"未知 endpoint", `{"endpoint": "no_such_endpoint"}`},
+		{"引用之后的操作", `{"endpoint": "get_user", "input": {"user_id": "$3.user.userId"}}`},
+		{"引用不存在的字段", `{"endpoint": "get_user", "input": {"user_id": "$0.nothing"}}`},
+		{"引用序号超出范围", `{"endpoint": "get_user", "input": {"user_id": "$99999999999999999999.user"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, response := postBatch(t, server, `{"operations": [
+				{"endpoint": "describe_capabilities"},
+				`+tt.operation+`
+			]}`, nil)
+
+			if len(response.Results) != 2 || response.Results[1].Error == nil || response.Results[1].Error.Code != apperr.CodeInvalidArgument {
+				t.Errorf("期望 invalid_argument, 得到 %+v", response.Results)
+			}
+		})
+	}
+
+	// 请求本身无效时返回错误状态码
+	for body, want := range map[string]int{
+		`{"operations": []}`: http.StatusUnprocessableEntity,
+		`{"operations": [{"endpoint": "get_user"}], "on_error": "retry"}`: http.StatusUnprocessableEntity,
+		`{"operations":`: http.StatusBadRequest,
+	} {
+		if code, _ := postBatch(t, server, body, nil); code != want {
+			t.Errorf("%s: 期望状态码 %d, 得到 %d", body, want, code)
+		}
+	}
+}
+
+// TestBatch_TokenScope 测试批量操作逐个检查 token scope
+func TestBatch_TokenScope(t *testing.T) {
+	server := setupTestServer(t)
+
+	tok := &token.Token{ID: "scoped", Scopes: []string{"get_user"}}
+	ctx := token.NewContext(context.Background(), tok)
+
+	_, response := postBatch(t, server, `{"on_error": "continue", "operations": [
+		{"endpoint": "get_user", "input": {"user_id": "10232"}},
+		{"endpoint": "get_dept_list", "input": {"dept_id": 0}}
+	]}`, ctx)
+
+	got := statuses(response)
+	if len(got) != 2 || got[0] != batchStatusOK || got[1] != batchStatusError {
+		t.Fatalf("期望 [ok error], 得到 %v", got)
+	}
+	if response.Results[1].Error.Code != apperr.CodePermissionDenied {
+		t.Errorf("期望 permission_denied, 得到 %+v", response.Results[1].Error)
+	}
+}
@@ -0,0 +1,277 @@
+// OpenAPI 文档页面：加载 /api/v1/openapi.json，按 tag 列出 operation，并支持直接发送请求。
+// 随二进制内嵌，不依赖任何外部资源；所有文本都通过 textContent 写入页面。
+(function () {
+  "use strict";
+
+  var methods = ["get", "post", "put", "patch", "delete"];
+  var maxSchemaDepth = 8;
+  var spec;
+
+  // el 创建元素，children 可以是字符串或元素
+  function el(tag, className, children) {
+    var node = document.createElement(tag);
+    if (className) {
+      node.className = className;
+    }
+    (children || []).forEach(function (child) {
+      if (child === null || child === undefined) {
+        return;
+      }
+      node.appendChild(typeof child === "string" ? document.createTextNode(child) : child);
+    });
+    return node;
+  }
+
+  // resolve 解析本文档内的 $ref（#/components/...）
+  function resolve(obj) {
+    var seen = 0;
+    while (obj && obj.$ref && seen < maxSchemaDepth) {
+      obj = obj.$ref.replace(/^#\//, "").split("/").reduce(function (node, key) {
+        return node ? node[key] : undefined;
+      }, spec);
+      seen++;
+    }
+    return obj || {};
+  }
+
+  // isArray 判断 schema 是否为数组（包括可为 null 的数组）
+  function isArray(schema) {
+    return schema.type === "array" || (Array.isArray(schema.type) && schema.type.indexOf("array") >= 0);
+  }
+
+  // typeOf 返回 schema 的类型说明
+  function typeOf(schema) {
+    var type = Array.isArray(schema.type) ? schema.type.join(" | ") : schema.type || "";
+    if (isArray(schema) && schema.items) {
+      type = type.replace("array", "array<" + (typeOf(resolve(schema.items)) || "any") + ">");
+    }
+    if (schema.enum) {
+      type += " (" + schema.enum.join(", ") + ")";
+    }
+    return type;
+  }
+
+  // renderSchema 以嵌套列表显示 schema 的字段
+  function renderSchema(schema, depth) {
+    schema = resolve(schema);
+    if (isArray(schema) && schema.items) {
+      schema = resolve(schema.items);
+    }
+    var list = el("ul", "schema");
+    var required = schema.required || [];
+    Object.keys(schema.properties || {}).forEach(function (name) {
+      var field = resolve(schema.properties[name]);
+      var item = el("li", "", [
+        el("span", "name", [name]),
+        " ",
+        el("span", "type", [typeOf(field)]),
+        required.indexOf(name) >= 0 ? el("span", "required", [" *"]) : null,
+        field.description ? " — " + field.description : null,
+      ]);
+      var nested = isArray(field) && field.items ? resolve(field.items) : field;
+      if (nested.properties && depth < maxSchemaDepth) {
+        item.appendChild(renderSchema(nested, depth + 1));
+      }
+      list.appendChild(item);
+    });
+    return list;
+  }
+
+  // jsonContent 返回请求体或响应的 application/json 内容
+  function jsonContent(body) {
+    body = resolve(body);
+    return body.content && body.content["application/json"];
+  }
+
+  function renderParameters(parameters) {
+    var table = el("table", "", [el("tr", "", [el("th", "", ["名称"]), el("th", "", ["位置"]), el("th", "", ["类型"]), el("th", "", ["说明"])])]);
+    parameters.forEach(function (param) {
+      table.appendChild(el("tr", "", [
+        el("td", "name", [param.name, param.required ? el("span", "required", [" *"]) : null]),
+        el("td", "", [param.in]),
+        el("td", "type", [typeOf(resolve(param.schema || {}))]),
+        el("td", "", [param.description || ""]),
+      ]));
+    });
+    return table;
+  }
+
+  function renderResponses(responses) {
+    var table = el("table", "", [el("tr", "", [el("th", "", ["状态码"]), el("th", "", ["说明"])])]);
+    Object.keys(responses).sort().forEach(function (status) {
+      table.appendChild(el("tr", "", [el("td", "", [status]), el("td", "", [resolve(responses[status]).description || ""])]));
+    });
+    return table;
+  }
+
+  // renderTry 生成“试一试”表单：填写参数和请求体后发送请求并显示响应
+  function renderTry(method, path, operation) {
+    var form = el("form", "try");
+    var inputs = {};
+    (operation.parameters || []).forEach(function (param) {
+      var input = el("input");
+      input.placeholder = param.name + (param.required ? " *" : "");
+      inputs[param.name] = { param: param, input: input };
+      form.appendChild(input);
+    });
+
+    var body;
+    var content = operation.requestBody && jsonContent(operation.requestBody);
+    if (content) {
+      body = el("textarea");
+      body.value = JSON.stringify(content.example || {}, null, 2);
+      form.appendChild(body);
+    }
+
+    var output = el("pre");
+    output.hidden = true;
+    form.appendChild(el("button", "", ["发送请求"]));
+    form.appendChild(output);
+
+    form.addEventListener("submit", function (event) {
+      event.preventDefault();
+      var url = path;
+      var query = new URLSearchParams();
+      Object.keys(inputs).forEach(function (name) {
+        var value = inputs[name].input.value;
+        if (value === "") {
+          return;
+        }
+        if (inputs[name].param.in === "path") {
+          url = url.replace("{" + name + "}", encodeURIComponent(value));
+        } else if (inputs[name].param.in === "query") {
+          query.append(name, value);
+        }
+      });
+      if (query.toString()) {
+        url += "?" + query.toString();
+      }
+
+      var headers = { "Content-Type": "application/json" };
+      var token = document.getElementById("token").value;
+      if (token) {
+        headers.Authorization = "Bearer " + token;
+      }
+
+      output.hidden = false;
+      output.className = "";
+      output.textContent = method.toUpperCase() + " " + url + " …";
+      fetch(url, { method: method.toUpperCase(), headers: headers, body: body ? body.value : undefined })
+        .then(function (response) {
+          return response.text().then(function (text) {
+            try {
+              text = JSON.stringify(JSON.parse(text), null, 2);
+            } catch (e) {
+              // 非 JSON 响应按原样显示
+            }
+            output.textContent = response.status + " " + response.statusText + "\n\n" + text;
+          });
+        })
+        .catch(function (err) {
+          output.className = "error";
+          output.textContent = String(err);
+        });
+    });
+    return form;
+  }
+
+  function renderOperation(method, path, operation) {
+    var id = operation.operationId || method + path;
+    var section = el("section", "operation", [
+      el("h2", "", [el("span", "method method-" + method, [method]), path]),
+      el("div", "summary", [operation.summary || ""]),
+    ]);
+    section.id = id;
+    if (operation.description) {
+      section.appendChild(el("p", "description", [operation.description]));
+    }
+    if (operation.parameters && operation.parameters.length) {
+      section.appendChild(el("h4", "", ["参数"]));
+      section.appendChild(renderParameters(operation.parameters));
+    }
+    var request = operation.requestBody && jsonContent(operation.requestBody);
+    if (request) {
+      section.appendChild(el("h4", "", ["请求体"]));
+      section.appendChild(renderSchema(request.schema || {}, 0));
+    }
+    var ok = operation.responses && operation.responses["200"] && jsonContent(operation.responses["200"]);
+    if (ok && ok.schema) {
+      section.appendChild(el("h4", "", ["响应"]));
+      section.appendChild(renderSchema(ok.schema, 0));
+    }
+    if (operation.responses) {
+      section.appendChild(el("h4", "", ["状态码"]));
+      section.appendChild(renderResponses(operation.responses));
+    }
+    section.appendChild(el("h4", "", ["试一试"]));
+    section.appendChild(renderTry(method, path, operation));
+    return section;
+  }
+
+  function render() {
+    document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+    document.getElementById("description").textContent = spec.info.description || "";
+
+    // 按 tag 分组，保持 spec.tags 的顺序
+    var groups = {};
+    var order = (spec.tags || []).map(function (tag) {
+      return tag.name;
+    });
+    Object.keys(spec.paths).sort().forEach(function (path) {
+      methods.forEach(function (method) {
+        var operation = spec.paths[path][method];
+        if (!operation) {
+          return;
+        }
+        var tag = (operation.tags || ["other"])[0];
+        if (order.indexOf(tag) < 0) {
+          order.push(tag);
+        }
+        (groups[tag] = groups[tag] || []).push({ method: method, path: path, operation: operation });
+      });
+    });
+
+    var nav = document.getElementById("nav");
+    var main = document.getElementById("operations");
+    main.textContent = "";
+    order.forEach(function (tag) {
+      if (!groups[tag]) {
+        return;
+      }
+      var info = (spec.tags || []).filter(function (t) {
+        return t.name === tag;
+      })[0];
+      nav.appendChild(el("h3", "", [info && info.description ? tag + " · " + info.description : tag]));
+      groups[tag].forEach(function (item) {
+        var section = renderOperation(item.method, item.path, item.operation);
+        var link = el("a", "", [item.method.toUpperCase() + " " + item.path]);
+        link.href = "#" + section.id;
+        nav.appendChild(link);
+        main.appendChild(section);
+      });
+    });
+  }
+
+  var tokenInput = document.getElementById("token");
+  tokenInput.value = sessionStorage.getItem("youdu-api-token") || "";
+  tokenInput.addEventListener("change", function () {
+    sessionStorage.setItem("youdu-api-token", tokenInput.value);
+  });
+
+  var main = document.getElementById("operations");
+  fetch(main.dataset.specUrl)
+    .then(function (response) {
+      if (!response.ok) {
+        throw new Error(response.status + " " + response.statusText);
+      }
+      return response.json();
+    })
+    .then(function (doc) {
+      spec = doc;
+      render();
+    })
+    .catch(function (err) {
+      main.className = "error";
+      main.textContent = "加载 OpenAPI 文档失败: " + err;
+    });
+})();
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+)
+
+// requestIDHeader 响应中返回请求 ID 的 header（与 middleware.RequestID 读取的请求 header 相同）
+const requestIDHeader = "X-Request-Id"
+
+// statusClientClosedRequest 客户端在响应前断开连接（nginx 约定的 499）
+const statusClientClosedRequest = 499
+
+// httpStatuses 错误码对应的 HTTP 状态码，未列出的错误码返回 500
+var httpStatuses = map[apperr.Code]int{
+	apperr.CodeUnauthenticated:      http.StatusUnauthorized,
+	apperr.CodePermissionDenied:     http.StatusForbidden,
+	apperr.CodeInvalidArgument:      http.StatusUnprocessableEntity,
+	apperr.CodeNotFound:             http.StatusNotFound,
+	apperr.CodeConfirmationRequired: http.StatusPreconditionRequired,
+	apperr.CodeUpstream:             http.StatusBadGateway,
+	apperr.CodeTimeout:              http.StatusGatewayTimeout,
+	apperr.CodeCancelled:            statusClientClosedRequest,
+	apperr.CodeRateLimited:          http.StatusTooManyRequests,
+}
+
+// errorResponse 错误响应体
+type errorResponse struct {
+	Error        bool        `json:"error" jsonschema:"description=始终为 true"`
+	Code         apperr.Code `json:"code" jsonschema:"description=机器可读的错误码"`
+	Message      string      `json:"message" jsonschema:"description=错误信息"`
+	Hint         string      `json:"hint,omitempty" jsonschema:"description=处理建议"`
+	RequestID    string      `json:"request_id,omitempty" jsonschema:"description=请求 ID（同 X-Request-Id 响应 header）"`
+	YouduErrCode int         `json:"youdu_errcode,omitempty" jsonschema:"description=有度返回的 errcode"`
+}
+
+// httpStatus 返回错误码对应的 HTTP 状态码
+func httpStatus(code apperr.Code) int {
+	if status, ok := httpStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// respondAppError 按错误码返回对应 HTTP 状态码的错误响应
+func (s *Server) respondAppError(w http.ResponseWriter, r *http.Request, err error) {
+	e := apperr.From(err)
+	s.writeError(w, r, httpStatus(e.Code), e)
+}
+
+// respondError 返回指定状态码和错误码的错误响应
+func (s *Server) respondError(w http.ResponseWriter, r *http.Request, status int, code apperr.Code, message string) {
+	s.writeError(w, r, status, apperr.New(code, "%s", message))
+}
+
+// writeError 写入统一格式的错误响应
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, e *apperr.Error) {
+	respondJSON(w, status, s.errorBody(r, e))
+}
+
+// errorBody 生成错误响应体
+func (s *Server) errorBody(r *http.Request, e *apperr.Error) errorResponse {
+	language := ""
+	if s.config != nil {
+		language = s.config.Language
+	}
+	return errorResponse{
+		Error:        true,
+		Code:         e.Code,
+		Message:      e.Message,
+		Hint:         e.Hint(language),
+		RequestID:    middleware.GetReqID(r.Context()),
+		YouduErrCode: e.YouduErrCode,
+	}
+}
+
+// requestIDMiddleware 在响应 header 中返回请求 ID，便于和服务端日志对应
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(requestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
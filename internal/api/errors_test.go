@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/addcnos/youdu/v2"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+)
+
+// TestErrorStatus 测试不同错误返回的状态码和错误码
+func TestErrorStatus(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		name       string
+		endpoint   string
+		body       string
+		wantStatus int
+		wantCode   apperr.Code
+	}{
+		{"非法 JSON", "get_user", `{"user_id":`, http.StatusBadRequest, apperr.CodeInvalidArgument},
+		{"枚举校验失败", "upload_file", `{"file_path": "README.md", "file_type": "bogus"}`, http.StatusUnprocessableEntity, apperr.CodeInvalidArgument},
+		{"缺少接收者", "send_text_message", `{"content": "hi"}`, http.StatusUnprocessableEntity, apperr.CodeInvalidArgument},
+		{"权限拒绝", "create_user", `{"user_id": "u1", "name": "n", "dept_id": 1}`, http.StatusForbidden, apperr.CodePermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/"+tt.endpoint, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("期望状态码 %d, 得到 %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+
+			var response errorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+			if !response.Error || response.Code != tt.wantCode || response.Message == "" || response.Hint == "" {
+				t.Errorf("错误响应不正确: %+v", response)
+			}
+
+			// 响应体和 header 中的请求 ID 一致
+			if response.RequestID == "" || response.RequestID != w.Header().Get(requestIDHeader) {
+				t.Errorf("request_id = %q, %s = %q", response.RequestID, requestIDHeader, w.Header().Get(requestIDHeader))
+			}
+		})
+	}
+}
+
+// TestRespondAppError 测试有度错误和超时的状态码
+func TestRespondAppError(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   apperr.Code
+		wantErrNo  int
+	}{
+		{"有度错误", fmt.Errorf("发送文本消息失败: %w", &youdu.Error{Code: 40001, Message: "invalid appid"}), http.StatusBadGateway, apperr.CodeUpstream, 40001},
+		{"有度资源不存在", &youdu.Error{Code: 40009, Message: "user not exist"}, http.StatusNotFound, apperr.CodeNotFound, 40009},
+		{"超时", fmt.Errorf("请求失败: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, apperr.CodeTimeout, 0},
+		{"其他错误", fmt.Errorf("boom"), http.StatusInternalServerError, apperr.CodeInternal, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.respondAppError(w, httptest.NewRequest("POST", "/api/v1/get_user", nil), tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("期望状态码 %d, 得到 %d", tt.wantStatus, w.Code)
+			}
+			var response errorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+			if response.Code != tt.wantCode || response.YouduErrCode != tt.wantErrNo {
+				t.Errorf("错误响应不正确: %+v", response)
+			}
+		})
+	}
+}
+
+// TestErrorStatus_Unreachable 测试有度服务器无法访问时返回 502
+func TestErrorStatus_Unreachable(t *testing.T) {
+	cfg, err := config.LoadFromFile("../../config_test.yaml")
+	if err != nil {
+		t.Fatalf("加载测试配置失败: %v", err)
+	}
+	cfg.Youdu.Addr = "http://127.0.0.1:1"
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/get_user", bytes.NewBufferString(`{"user_id": "10232"}`))
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("期望状态码 %d, 得到 %d: %s", http.StatusBadGateway, w.Code, w.Body.String())
+	}
+	var response errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if response.Code != apperr.CodeUpstream {
+		t.Errorf("期望 %s, 得到 %+v", apperr.CodeUpstream, response)
+	}
+}
@@ -0,0 +1,377 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// API 文档路径
+const (
+	openAPIPath = "/api/v1/openapi.json"
+	docsPath    = "/api/v1/docs"  // 文档页面，页面的脚本和样式在 docsPath/{name}
+	redocPath   = "/api/v1/redoc" // 旧的 Redoc 地址，重定向到 docsPath
+)
+
+// docsFiles 文档页面及其脚本和样式（从 openAPIPath 加载规范）
+// 全部内嵌在二进制中，页面不加载任何外部资源
+//
+//go:embed docs
+var docsFiles embed.FS
+
+// docsContentSecurityPolicy 文档页面只允许加载同源的脚本、样式和请求
+const docsContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self' data:; base-uri 'none'; form-action 'none'"
+
+// 认证方式在 OpenAPI 文档中的名称
+const (
+	securityBearer    = "bearerAuth"
+	securitySignature = "signedRequest"
+	securityClientTLS = "clientCertificate"
+)
+
+// errorResponses 每个 endpoint 可能返回的错误响应（components.responses 中的名称）
+var errorResponses = map[string]string{
+	"400": "BadRequest",
+	"401": "Unauthorized",
+	"403": "Forbidden",
+	"404": "NotFound",
+	"422": "UnprocessableEntity",
+	"428": "PreconditionRequired",
+	"429": "TooManyRequests",
+	"500": "InternalError",
+	"502": "BadGateway",
+	"499": "ClientClosedRequest",
+	"504": "GatewayTimeout",
+}
+
+// registerDocsRoutes 注册 OpenAPI 规范和文档页面
+func (s *Server) registerDocsRoutes() {
+	s.router.Get(openAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, s.openAPIDocument())
+	})
+	s.router.Get(docsPath, s.serveDocsFile("index.html"))
+	s.router.Get(docsPath+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		s.serveDocsFile(chi.URLParam(r, "name"))(w, r)
+	})
+	s.router.Get(redocPath, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, docsPath, http.StatusMovedPermanently)
+	})
+}
+
+// isDocsPath 判断是否为不需要认证的文档路径
+func isDocsPath(p string) bool {
+	return p == openAPIPath || p == docsPath || p == redocPath || strings.HasPrefix(p, docsPath+"/")
+}
+
+// serveDocsFile 返回内嵌的文档页面或资源
+func (s *Server) serveDocsFile(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := docsFiles.ReadFile(path.Join("docs", name))
+		if err != nil {
+			s.respondAppError(w, r, apperr.New(apperr.CodeNotFound, "文档资源 %s 不存在", name))
+			return
+		}
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Security-Policy", docsContentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}
+}
+
+// openAPIDocument 根据 adapter 方法的输入/输出类型生成 OpenAPI 3.1 文档
+func (s *Server) openAPIDocument() map[string]interface{} {
+	adapterType := reflect.TypeOf(s.adapter)
+	schemas := map[string]interface{}{
+		"ErrorResponse": schema.ForOutput(reflect.TypeOf(errorResponse{})),
+	}
+	paths := map[string]interface{}{}
+
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		method, ok := adapterType.MethodByName(s.methods[name])
+		if !ok {
+			continue
+		}
+		inputType := method.Type.In(2)
+		outputType := method.Type.Out(0).Elem()
+		schemas[inputType.Name()] = schema.For(inputType)
+		schemas[outputType.Name()] = schema.ForOutput(outputType)
+
+		paths["/api/v1/"+name] = map[string]interface{}{
+			"post": s.openAPIOperation(name, method.Name, inputType, outputType),
+		}
+	}
+
+	// 资源风格的路由
+	for _, route := range restRoutes {
+		method, ok := adapterType.MethodByName(route.Adapter)
+		if !ok {
+			continue
+		}
+		item, _ := paths[route.Pattern].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[route.Pattern] = item
+		}
+		item[strings.ToLower(route.Method)] = s.openAPIRESTOperation(route, method.Type.In(2), method.Type.Out(0).Elem())
+	}
+
+	// 批量执行
+	schemas["BatchRequest"] = schema.For(reflect.TypeOf(batchRequest{}))
+	schemas["BatchResponse"] = schema.ForOutput(reflect.TypeOf(batchResponse{}))
+	batchResponses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "每个操作的执行结果（单个操作失败不影响状态码）",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/BatchResponse"},
+				},
+			},
+		},
+	}
+	for _, status := range []string{"400", "401", "403", "422", "429"} {
+		batchResponses[status] = map[string]interface{}{"$ref": "#/components/responses/" + errorResponses[status]}
+	}
+	paths[batchPath] = map[string]interface{}{
+		"post": map[string]interface{}{
+			"operationId": "batch",
+			"summary":     "按顺序批量执行多个操作",
+			"description": "每个操作与单独调用对应的 endpoint 一样检查 token scope 和权限策略。输入中的字符串值 $<序号>.<字段路径>（例如 $0.session_id）会替换为前面操作输出中的值。on_error 为 stop（默认）时遇到错误停止，后续操作标记为 skipped；为 continue 时继续执行。",
+			"tags":        []string{"meta"},
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"$ref": "#/components/schemas/BatchRequest"},
+					},
+				},
+			},
+			"responses": batchResponses,
+		},
+	}
+
+	paths["/health"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"operationId": "health",
+			"summary":     "健康检查",
+			"tags":        []string{"meta"},
+			"security":    []interface{}{},
+			"responses":   map[string]interface{}{"200": map[string]interface{}{"description": "服务正常"}},
+		},
+	}
+	paths["/api/v1/endpoints"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"operationId": "list_endpoints",
+			"summary":     "列出所有 endpoint 及其输入/输出 schema",
+			"tags":        []string{"meta"},
+			"security":    []interface{}{},
+			"responses":   map[string]interface{}{"200": map[string]interface{}{"description": "endpoint 列表"}},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "YouDu API",
+			"version":     "1.0.0",
+			"description": "有度 IM 的 HTTP API。每个 endpoint 对应一个 adapter 方法，输入和输出 schema 与 MCP 工具一致。",
+		},
+		"servers": []interface{}{map[string]interface{}{"url": "/"}},
+		"tags":    openAPITags(),
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas":         schemas,
+			"responses":       openAPIErrorResponses(),
+			"securitySchemes": openAPISecuritySchemes(),
+		},
+		"security": s.openAPISecurity(),
+	}
+}
+
+// openAPIOperation 生成单个 endpoint 的 operation 对象
+func (s *Server) openAPIOperation(name, methodName string, inputType, outputType reflect.Type) map[string]interface{} {
+	description := adapter.Describe(methodName, s.config.Language)
+
+	details := description.Details
+	if len(description.ErrorHints) > 0 {
+		details += "\n\n" + strings.Join(description.ErrorHints, "\n\n")
+	}
+
+	content := map[string]interface{}{
+		"schema": map[string]interface{}{"$ref": "#/components/schemas/" + inputType.Name()},
+	}
+	var example interface{}
+	if description.Example != "" && json.Unmarshal([]byte(description.Example), &example) == nil {
+		content["example"] = example
+	}
+
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "成功",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/" + outputType.Name()},
+				},
+			},
+		},
+	}
+	for status, ref := range errorResponses {
+		responses[status] = map[string]interface{}{"$ref": "#/components/responses/" + ref}
+	}
+
+	operation := map[string]interface{}{
+		"operationId": name,
+		"summary":     description.Summary,
+		"description": details,
+		"requestBody": map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"application/json": content},
+		},
+		"responses": responses,
+	}
+	if op, ok := adapter.LookupOperation(methodName); ok {
+		operation["tags"] = []string{string(op.Resource)}
+	} else {
+		operation["tags"] = []string{"meta"}
+	}
+	return operation
+}
+
+// openAPIRESTOperation 生成资源风格路由的 operation 对象
+// 路径参数来自路由模式；GET 的其余字段作为查询参数，其他方法使用 JSON 请求体
+func (s *Server) openAPIRESTOperation(route restRoute, inputType, outputType reflect.Type) map[string]interface{} {
+	operation := s.openAPIOperation(adapter.ToSnakeCase(route.Adapter), route.Adapter, inputType, outputType)
+
+	id := strings.NewReplacer("/api/v1/", "", "/", "_", "{", "", "}", "").Replace(route.Pattern)
+	operation["operationId"] = strings.ToLower(route.Method) + "_" + id
+
+	var parameters []interface{}
+	for _, field := range schema.Fields(inputType) {
+		in := ""
+		switch {
+		case strings.Contains(route.Pattern, "{"+field.Name+"}"):
+			in = "path"
+		case route.Method == http.MethodGet:
+			in = "query"
+		default:
+			continue
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":        field.Name,
+			"in":          in,
+			"required":    in == "path" || field.Required,
+			"description": field.Schema.Description,
+			"schema":      field.Schema,
+		})
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if route.Method == http.MethodGet {
+		delete(operation, "requestBody")
+		responses := operation["responses"].(map[string]interface{})
+		responses["304"] = map[string]interface{}{"description": "If-None-Match 与当前 ETag 相同，内容未变化"}
+	} else {
+		operation["requestBody"].(map[string]interface{})["required"] = false
+	}
+	return operation
+}
+
+// openAPITags 按资源分组
+func openAPITags() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"name": "dept", "description": "部门"},
+		map[string]interface{}{"name": "user", "description": "用户"},
+		map[string]interface{}{"name": "group", "description": "群组"},
+		map[string]interface{}{"name": "session", "description": "会话"},
+		map[string]interface{}{"name": "message", "description": "消息和文件"},
+		map[string]interface{}{"name": "meta", "description": "服务信息"},
+	}
+}
+
+// openAPIErrorResponses 错误响应定义
+func openAPIErrorResponses() map[string]interface{} {
+	descriptions := map[string]string{
+		"BadRequest":           "请求体不是合法的 JSON（invalid_argument）",
+		"Unauthorized":         "缺少或无效的认证信息（unauthenticated）",
+		"Forbidden":            "权限策略或 token scope 不允许该操作（permission_denied）",
+		"NotFound":             "用户、部门、群组或文件不存在（not_found）",
+		"UnprocessableEntity":  "参数不符合 schema 或业务校验（invalid_argument）",
+		"PreconditionRequired": "操作需要用户确认后才能执行（confirmation_required）",
+		"TooManyRequests":      "认证失败次数过多，来源 IP 被临时锁定，见 Retry-After（rate_limited）",
+		"ClientClosedRequest":  "客户端在响应前断开连接，请求已取消（cancelled）",
+		"InternalError":        "服务内部错误（internal）",
+		"BadGateway":           "有度服务器返回错误（youdu_errcode 为有度的 errcode）或无法访问（upstream_error）",
+		"GatewayTimeout":       "请求有度服务器超时（timeout）",
+	}
+	responses := map[string]interface{}{}
+	for name, description := range descriptions {
+		responses[name] = map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"},
+				},
+			},
+		}
+	}
+	return responses
+}
+
+// openAPISecuritySchemes 支持的认证方式：bearer token、HMAC 签名请求和客户端证书
+func openAPISecuritySchemes() map[string]interface{} {
+	return map[string]interface{}{
+		securityBearer: map[string]interface{}{
+			"type":        "http",
+			"scheme":      "bearer",
+			"description": "Authorization: Bearer <token>（也可以直接传 token 值）",
+		},
+		securitySignature: map[string]interface{}{
+			"type": "apiKey",
+			"in":   "header",
+			"name": token.HeaderKeyID,
+			"description": "HMAC-SHA256 签名请求：" + token.HeaderKeyID + " 为 token ID，同时需要 " +
+				token.HeaderTimestamp + "、" + token.HeaderNonce + " 和 " + token.HeaderSignature +
+				"，签名内容为 method、path、timestamp、nonce 和请求体，密钥为 token 值",
+		},
+		securityClientTLS: map[string]interface{}{
+			"type":        "mutualTLS",
+			"description": "客户端证书（subject 映射到 token）",
+		},
+	}
+}
+
+// openAPISecurity 全局认证要求；未启用 token 认证时允许匿名访问
+func (s *Server) openAPISecurity() []interface{} {
+	security := []interface{}{
+		map[string]interface{}{securityBearer: []string{}},
+		map[string]interface{}{securitySignature: []string{}},
+		map[string]interface{}{securityClientTLS: []string{}},
+	}
+	if s.config.TokenManager == nil || !s.config.TokenManager.AuthEnabled() {
+		security = append(security, map[string]interface{}{})
+	}
+	return security
+}
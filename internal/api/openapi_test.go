@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestOpenAPI 测试 OpenAPI 文档
+func TestOpenAPI(t *testing.T) {
+	server := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", openAPIPath, nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 %d, 得到 %d", http.StatusOK, w.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas         map[string]interface{} `json:"schemas"`
+			Responses       map[string]interface{} `json:"responses"`
+			SecuritySchemes map[string]interface{} `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("解析文档失败: %v", err)
+	}
+
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+
+	// 每个 adapter endpoint 都有 POST operation
+	for name := range server.methods {
+		if _, ok := doc.Paths["/api/v1/"+name]["post"]; !ok {
+			t.Errorf("缺少 /api/v1/%s", name)
+		}
+	}
+
+	for _, scheme := range []string{securityBearer, securitySignature, securityClientTLS} {
+		if _, ok := doc.Components.SecuritySchemes[scheme]; !ok {
+			t.Errorf("缺少认证方式 %s", scheme)
+		}
+	}
+
+	// 所有 $ref 都指向存在的组件
+	var checkRefs func(v interface{})
+	checkRefs = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				var found bool
+				switch {
+				case strings.HasPrefix(ref, "#/components/schemas/"):
+					_, found = doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+				case strings.HasPrefix(ref, "#/components/responses/"):
+					_, found = doc.Components.Responses[strings.TrimPrefix(ref, "#/components/responses/")]
+				}
+				if !found {
+					t.Errorf("无法解析的 $ref: %s", ref)
+				}
+			}
+			for _, child := range v {
+				checkRefs(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				checkRefs(child)
+			}
+		}
+	}
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			checkRefs(op)
+		}
+	}
+
+	op := doc.Paths["/api/v1/send_text_message"]["post"]
+	if op["summary"] == "" || op["requestBody"] == nil {
+		t.Errorf("send_text_message 缺少摘要或请求体: %v", op)
+	}
+	responses, _ := op["responses"].(map[string]interface{})
+	for _, status := range []string{"200", "400", "401", "403", "500"} {
+		if _, ok := responses[status]; !ok {
+			t.Errorf("send_text_message 缺少 %s 响应", status)
+		}
+	}
+
+	// 每个错误码对应的状态码都有文档
+	for code, status := range httpStatuses {
+		if _, ok := responses[strconv.Itoa(status)]; !ok {
+			t.Errorf("send_text_message 缺少 %s 对应的 %d 响应", code, status)
+		}
+	}
+}
+
+// TestDocsPages 测试内嵌的文档页面及其资源
+func TestDocsPages(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{docsPath, "text/html"},
+		{docsPath + "/viewer.js", "text/javascript"},
+		{docsPath + "/viewer.css", "text/css"},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: 期望状态码 %d, 得到 %d", tc.path, http.StatusOK, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, tc.contentType) {
+			t.Errorf("%s: Content-Type = %q", tc.path, ct)
+		}
+		if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self'") {
+			t.Errorf("%s: Content-Security-Policy = %q", tc.path, csp)
+		}
+		// 页面只引用内嵌的资源，不从 CDN 加载脚本和样式
+		if body := w.Body.String(); strings.Contains(body, "https://") || strings.Contains(body, "http://") {
+			t.Errorf("%s: 页面引用了外部资源", tc.path)
+		}
+	}
+
+	// 页面从 openAPIPath 加载规范
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", docsPath, nil))
+	if !strings.Contains(w.Body.String(), openAPIPath) {
+		t.Errorf("%s: 页面未引用 %s", docsPath, openAPIPath)
+	}
+
+	// 不存在的资源
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", docsPath+"/missing.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("期望状态码 %d, 得到 %d", http.StatusNotFound, w.Code)
+	}
+
+	// 旧的 Redoc 地址重定向到文档页面
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("GET", redocPath, nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != docsPath {
+		t.Errorf("%s: 期望重定向到 %s, 得到 %d %q", redocPath, docsPath, w.Code, w.Header().Get("Location"))
+	}
+}
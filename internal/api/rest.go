@@ -0,0 +1,214 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+)
+
+// restRoute 资源风格的路由，映射到已有的 adapter 方法
+// 路径参数名与输入字段的 JSON 名称相同。输入依次从 JSON 请求体（GET 除外）、
+// 查询参数和路径参数绑定，后者覆盖前者。
+type restRoute struct {
+	Method  string // HTTP 方法
+	Pattern string // chi 路由
+	Adapter string // adapter 方法名
+}
+
+// restRoutes 资源风格的路由列表，与 POST /api/v1/{method_name} 并存
+var restRoutes = []restRoute{
+	{http.MethodGet, "/api/v1/capabilities", "DescribeCapabilities"},
+
+	// 部门
+	{http.MethodGet, "/api/v1/depts", "GetDeptList"},
+	{http.MethodPost, "/api/v1/depts", "CreateDept"},
+	{http.MethodGet, "/api/v1/depts/{dept_id}/children", "GetDeptList"},
+	{http.MethodGet, "/api/v1/depts/{dept_id}/users", "GetDeptUserList"},
+	{http.MethodGet, "/api/v1/depts/{dept_id}/aliases", "GetDeptAliasList"},
+	{http.MethodPatch, "/api/v1/depts/{dept_id}", "UpdateDept"},
+	{http.MethodDelete, "/api/v1/depts/{dept_id}", "DeleteDept"},
+
+	// 用户
+	{http.MethodPost, "/api/v1/users", "CreateUser"},
+	{http.MethodGet, "/api/v1/users/{user_id}", "GetUser"},
+	{http.MethodPatch, "/api/v1/users/{user_id}", "UpdateUser"},
+	{http.MethodDelete, "/api/v1/users/{user_id}", "DeleteUser"},
+	{http.MethodGet, "/api/v1/users/{user_id}/groups", "GetGroupList"},
+
+	// 群组
+	{http.MethodPost, "/api/v1/groups", "CreateGroup"},
+	{http.MethodGet, "/api/v1/groups/{group_id}", "GetGroupInfo"},
+	{http.MethodPatch, "/api/v1/groups/{group_id}", "UpdateGroup"},
+	{http.MethodDelete, "/api/v1/groups/{group_id}", "DeleteGroup"},
+	{http.MethodPost, "/api/v1/groups/{group_id}/members", "AddGroupMember"},
+	{http.MethodDelete, "/api/v1/groups/{group_id}/members", "DelGroupMember"},
+
+	// 会话
+	{http.MethodPost, "/api/v1/sessions", "CreateSession"},
+	{http.MethodGet, "/api/v1/sessions/{session_id}", "GetSession"},
+	{http.MethodPatch, "/api/v1/sessions/{session_id}", "UpdateSession"},
+	{http.MethodPost, "/api/v1/sessions/{session_id}/messages", "SendTextSessionMessage"},
+}
+
+// restCacheControl GET 响应的缓存策略：结果取决于调用方的 token 和权限策略，
+// 只允许客户端私有缓存，并且每次使用前通过 ETag 重新验证
+const restCacheControl = "private, no-cache"
+
+// registerRESTRoutes 注册资源风格的路由
+func (s *Server) registerRESTRoutes() error {
+	adapterType := reflect.TypeOf(s.adapter)
+
+	for _, route := range restRoutes {
+		method, ok := adapterType.MethodByName(route.Adapter)
+		if !ok {
+			return fmt.Errorf("adapter 方法 %s 不存在", route.Adapter)
+		}
+		s.router.Method(route.Method, route.Pattern, s.restHandler(route, method.Type.In(2)))
+		s.routes[route.Method+" "+route.Pattern] = adapter.ToSnakeCase(route.Adapter)
+
+		fmt.Printf("  ✓ %s %s -> %s\n", route.Method, route.Pattern, adapter.ToSnakeCase(route.Adapter))
+	}
+
+	return nil
+}
+
+// restHandler 绑定请求参数并调用 adapter 方法
+func (s *Server) restHandler(route restRoute, inputType reflect.Type) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		input := reflect.New(inputType).Interface()
+
+		if r.Method != http.MethodGet {
+			if err := json.NewDecoder(r.Body).Decode(input); err != nil && err != io.EOF {
+				s.respondError(w, r, http.StatusBadRequest, apperr.CodeInvalidArgument, fmt.Sprintf("Invalid JSON: %v", err))
+				return
+			}
+		}
+		if err := bindParams(r, input); err != nil {
+			s.respondError(w, r, http.StatusBadRequest, apperr.CodeInvalidArgument, err.Error())
+			return
+		}
+
+		output, err := s.invoke(r.Context(), route.Adapter, input)
+		if err != nil {
+			s.respondAppError(w, r, err)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			s.respondCacheable(w, r, output)
+			return
+		}
+		respondJSON(w, http.StatusOK, output)
+	}
+}
+
+// bindParams 将查询参数和路径参数绑定到输入字段（input 为结构体指针）
+func bindParams(r *http.Request, input interface{}) error {
+	value := reflect.ValueOf(input).Elem()
+	query := r.URL.Query()
+
+	for _, field := range schema.Fields(value.Type()) {
+		values := query[field.Name]
+		if param := chi.URLParam(r, field.Name); param != "" {
+			unescaped, err := url.PathUnescape(param)
+			if err != nil {
+				return &schema.FieldError{Field: field.Name, Message: "不是合法的路径参数"}
+			}
+			values = []string{unescaped}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := setField(value.Field(field.Index), field.Name, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setField 按字段类型转换参数值；切片支持重复参数和逗号分隔
+func setField(v reflect.Value, name string, values []string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(values[0])
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return &schema.FieldError{Field: name, Message: "必须是整数"}
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return &schema.FieldError{Field: name, Message: "必须是 true 或 false"}
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return &schema.FieldError{Field: name, Message: "不支持通过 URL 参数传递"}
+		}
+		var items []string
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return &schema.FieldError{Field: name, Message: "不支持通过 URL 参数传递"}
+	}
+	return nil
+}
+
+// respondCacheable 返回带 ETag 的 GET 响应，If-None-Match 匹配时返回 304
+func (s *Server) respondCacheable(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		s.respondAppError(w, r, err)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("Cache-Control", restCacheControl)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Vary", "Authorization")
+
+	if match := r.Header.Get("If-None-Match"); match != "" && (match == "*" || strings.Contains(match, etag)) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
+
+// endpointName 返回请求对应的 endpoint 名称（adapter 方法名的 snake_case），用于 token scope 检查
+// 资源风格的路由按匹配到的路由模式查找
+func (s *Server) endpointName(r *http.Request) string {
+	endpoint := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	if _, ok := s.methods[endpoint]; ok {
+		return endpoint
+	}
+
+	pattern := s.router.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+	if name, ok := s.routes[r.Method+" "+pattern]; ok {
+		return name
+	}
+	return endpoint
+}
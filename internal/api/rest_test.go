@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+)
+
+// TestREST_GetUser 测试资源风格的 GET 路由和缓存
+func TestREST_GetUser(t *testing.T) {
+	server := setupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/users/10232", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 %d, 得到 %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		User struct {
+			UserID string `json:"userId"`
+			Name   string `json:"name"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if response.User.Name != "Tc-黎明" {
+		t.Errorf("期望用户名 Tc-黎明, 得到 %+v", response.User)
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Cache-Control") != restCacheControl {
+		t.Errorf("缺少缓存 header: ETag=%q Cache-Control=%q", etag, w.Header().Get("Cache-Control"))
+	}
+
+	// 内容未变化时返回 304
+	req = httptest.NewRequest("GET", "/api/v1/users/10232", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("期望 304 且无响应体, 得到 %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// TestREST_Binding 测试路径参数和查询参数绑定
+func TestREST_Binding(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"查询参数", "GET", "/api/v1/depts?dept_id=0", http.StatusOK},
+		{"路径参数", "GET", "/api/v1/depts/0/children", http.StatusOK},
+		{"路径参数类型错误", "GET", "/api/v1/depts/abc/children", http.StatusBadRequest},
+		{"查询参数类型错误", "GET", "/api/v1/depts?dept_id=abc", http.StatusBadRequest},
+		{"权限拒绝", "DELETE", "/api/v1/groups/g1", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("期望状态码 %d, 得到 %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// 允许删除用户后，DELETE 路由从路径绑定 user_id
+	server.config.Permission.SetResourcePolicy(permission.ResourceUser, permission.ResourcePolicy{Read: true, Delete: true})
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/users/10232", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("期望状态码 %d, 得到 %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+}
+
+// TestREST_EndpointName 测试资源风格路由对应的 endpoint 名称（用于 token scope）
+func TestREST_EndpointName(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{"POST", "/api/v1/get_user", "get_user"},
+		{"GET", "/api/v1/users/10232", "get_user"},
+		{"DELETE", "/api/v1/users/10232", "delete_user"},
+		{"POST", "/api/v1/groups/g1/members", "add_group_member"},
+		{"DELETE", "/api/v1/groups/g1/members", "del_group_member"},
+	}
+
+	for _, tt := range tests {
+		if got := server.endpointName(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("%s %s: 期望 %s, 得到 %s", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
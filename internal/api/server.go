@@ -3,24 +3,35 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
-	"unicode"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
 	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/lockout"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
 )
 
 // Server represents the HTTP API server
 type Server struct {
-	router       chi.Router
-	adapter      *adapter.Adapter
-	config       *config.Config
-	tokenEnabled bool
+	router   chi.Router
+	adapter  *adapter.Adapter
+	config   *config.Config
+	methods  map[string]string // endpoint 名称 -> adapter 方法名
+	routes   map[string]string // 资源风格路由（"GET /api/v1/users/{user_id}"）-> endpoint 名称
+	throttle *lockout.Throttle // 认证失败限流
+	mcpPaths map[string]bool   // 挂载的 MCP endpoint 路径
 }
 
 // New creates a new API server
@@ -38,21 +49,22 @@ func New(cfg *config.Config) (*Server, error) {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
+	r.Use(requestIDMiddleware)
 	r.Use(corsMiddleware)
 	r.Use(jsonContentTypeMiddleware)
 
-	// 检查是否启用 token 认证
-	tokenEnabled := cfg.TokenManager != nil && cfg.TokenManager.Count() > 0
-
 	s := &Server{
-		router:       r,
-		adapter:      adp,
-		config:       cfg,
-		tokenEnabled: tokenEnabled,
+		router:   r,
+		adapter:  adp,
+		config:   cfg,
+		methods:  make(map[string]string),
+		routes:   make(map[string]string),
+		throttle: lockout.New(cfg.Token.Lockout),
+		mcpPaths: make(map[string]bool),
 	}
 
-	// 添加 token 认证中间件（如果启用）
-	if tokenEnabled {
+	// 添加 token 认证中间件（是否需要认证在每次请求时判断）
+	if cfg.TokenManager != nil {
 		s.router.Use(s.tokenAuthMiddleware)
 	}
 
@@ -61,24 +73,74 @@ func New(cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to register routes: %w", err)
 	}
 
+	// 注册资源风格的路由（GET /api/v1/users/{user_id} 等）
+	if err := s.registerRESTRoutes(); err != nil {
+		return nil, fmt.Errorf("failed to register REST routes: %w", err)
+	}
+
+	// 注册批量执行 endpoint
+	s.registerBatchRoute()
+
 	// 添加健康检查和元信息端点
 	s.registerMetaRoutes()
 
+	// 添加 OpenAPI 规范和文档页面
+	s.registerDocsRoutes()
+
 	return s, nil
 }
 
+// MountMCP 在 API 服务器上挂载 MCP HTTP endpoint
+// 这些路径与其他 API 一样经过认证中间件（失败锁定、来源 IP、客户端证书），
+// 认证通过的 token 通过 context 传给 handler，由 MCP 按工具检查 scope
+func (s *Server) MountMCP(handler http.Handler, paths ...string) {
+	for _, path := range paths {
+		s.mcpPaths[path] = true
+		s.router.Handle(path, handler)
+	}
+}
+
 // Start starts the HTTP server
 func (s *Server) Start(addr string) error {
 	fmt.Printf("🚀 YouDu API Server 启动在 %s\n", addr)
 	fmt.Println("📖 API 文档: GET /api/v1/endpoints")
+	fmt.Printf("📘 OpenAPI: GET %s（文档页面: %s）\n", openAPIPath, docsPath)
 	fmt.Println("💚 健康检查: GET /health")
-	if s.tokenEnabled {
+	switch {
+	case s.config.TokenManager == nil:
+		fmt.Println("⚠️  Token 认证: 未启用")
+	case !s.config.TokenManager.Enabled() && !s.config.TokenManager.Required():
+		fmt.Println("⚠️  Token 认证: 未启用 (token.enabled: false)")
+	case s.config.TokenManager.Required():
+		fmt.Println("🔒 Token 认证: 强制启用 (token.required)")
+		fmt.Printf("   当前有效 token 数量: %d\n", s.config.TokenManager.Count())
+	case s.config.TokenManager.AuthEnabled():
 		fmt.Println("🔒 Token 认证: 已启用")
 		fmt.Printf("   当前有效 token 数量: %d\n", s.config.TokenManager.Count())
-	} else {
-		fmt.Println("⚠️  Token 认证: 未启用")
+	default:
+		fmt.Println("⚠️  Token 认证: 未启用（生成 token 后自动启用）")
+	}
+
+	if !s.config.TLS.Enabled() {
+		return http.ListenAndServe(addr, s.router)
+	}
+
+	// 启用 HTTPS（以及可选的客户端证书校验）
+	tlsConfig, err := s.config.TLS.Build()
+	if err != nil {
+		return err
+	}
+	fmt.Println("🔐 HTTPS: 已启用")
+	if tlsConfig.ClientCAs != nil {
+		fmt.Printf("   客户端证书校验: %s（已映射 %d 个 subject）\n", s.config.TLS.ClientAuth, len(s.config.TLS.ClientSubjects))
+	}
+
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   s.router,
+		TLSConfig: tlsConfig,
 	}
-	return http.ListenAndServe(addr, s.router)
+	return server.ListenAndServeTLS("", "")
 }
 
 // Close closes the server and releases resources
@@ -89,7 +151,6 @@ func (s *Server) Close() error {
 // registerRoutes 使用反射自动注册所有 adapter 方法为 HTTP endpoint
 func (s *Server) registerRoutes() error {
 	adapterType := reflect.TypeOf(s.adapter)
-	adapterValue := reflect.ValueOf(s.adapter)
 
 	fmt.Println("\n📋 正在注册 API Endpoints:")
 
@@ -103,7 +164,7 @@ func (s *Server) registerRoutes() error {
 		}
 
 		// 转换方法名为 snake_case 作为路径
-		path := toSnakeCase(method.Name)
+		path := adapter.ToSnakeCase(method.Name)
 
 		// 获取方法类型信息
 		methodType := method.Type
@@ -125,14 +186,14 @@ func (s *Server) registerRoutes() error {
 			continue
 		}
 
-		// 获取输入和输出类型
+		// 获取输入类型
 		inputType := methodType.In(2)
-		outputType := methodType.Out(0)
 
 		// 注册路由
-		if err := s.registerRoute(path, method, adapterValue, inputType, outputType); err != nil {
+		if err := s.registerRoute(path, method, inputType); err != nil {
 			return fmt.Errorf("failed to register route %s: %w", path, err)
 		}
+		s.methods[path] = method.Name
 
 		fmt.Printf("  ✓ POST /api/v1/%s\n", path)
 	}
@@ -141,33 +202,25 @@ func (s *Server) registerRoutes() error {
 }
 
 // registerRoute 注册单个路由
-func (s *Server) registerRoute(path string, method reflect.Method, adapterValue reflect.Value, inputType, outputType reflect.Type) error {
+func (s *Server) registerRoute(path string, method reflect.Method, inputType reflect.Type) error {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		// 创建输入实例
 		input := reflect.New(inputType).Interface()
 
-		// 解析 JSON 请求体
-		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
-			respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+		// 解析 JSON 请求体（允许无参数的方法使用空请求体）
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil && err != io.EOF {
+			s.respondError(w, r, http.StatusBadRequest, apperr.CodeInvalidArgument, fmt.Sprintf("Invalid JSON: %v", err))
 			return
 		}
 
-		// 调用 adapter 方法
-		results := method.Func.Call([]reflect.Value{
-			adapterValue,
-			reflect.ValueOf(r.Context()),
-			reflect.ValueOf(input).Elem(),
-		})
-
-		// 检查错误
-		if !results[1].IsNil() {
-			err := results[1].Interface().(error)
-			respondError(w, http.StatusInternalServerError, err.Error())
+		// 调用 adapter 方法（按错误类型返回 403/404/422/502/504 等状态码）
+		output, err := s.invoke(r.Context(), method.Name, input)
+		if err != nil {
+			s.respondAppError(w, r, err)
 			return
 		}
 
 		// 返回成功响应
-		output := results[0].Interface()
 		respondJSON(w, http.StatusOK, output)
 	}
 
@@ -177,6 +230,22 @@ func (s *Server) registerRoute(path string, method reflect.Method, adapterValue
 	return nil
 }
 
+// invoke 按 schema 校验输入（枚举和取值范围）后调用 adapter 方法，input 为输入类型的指针
+func (s *Server) invoke(ctx context.Context, methodName string, input interface{}) (interface{}, error) {
+	if err := schema.Validate(input); err != nil {
+		return nil, err
+	}
+
+	results := reflect.ValueOf(s.adapter).MethodByName(methodName).Call([]reflect.Value{
+		reflect.ValueOf(ctx),
+		reflect.ValueOf(input).Elem(),
+	})
+	if !results[1].IsNil() {
+		return nil, results[1].Interface().(error)
+	}
+	return results[0].Interface(), nil
+}
+
 // registerMetaRoutes 注册元信息路由
 func (s *Server) registerMetaRoutes() {
 	// 健康检查
@@ -215,50 +284,39 @@ func (s *Server) listEndpoints() []map[string]interface{} {
 			continue
 		}
 
-		path := toSnakeCase(method.Name)
+		path := adapter.ToSnakeCase(method.Name)
 		inputType := methodType.In(2)
 		outputType := methodType.Out(0)
+		description := adapter.Describe(method.Name, s.config.Language)
 
 		endpoints = append(endpoints, map[string]interface{}{
-			"method":      "POST",
-			"path":        fmt.Sprintf("/api/v1/%s", path),
-			"name":        method.Name,
-			"description": generateDescription(method.Name),
-			"input_type":  inputType.String(),
-			"output_type": outputType.String(),
+			"method":        "POST",
+			"path":          fmt.Sprintf("/api/v1/%s", path),
+			"name":          method.Name,
+			"summary":       description.Summary,
+			"description":   description.Details,
+			"example":       description.Example,
+			"error_hints":   description.ErrorHints,
+			"input_type":    inputType.String(),
+			"output_type":   outputType.String(),
+			"routes":        s.routesFor(method.Name),
+			"input_schema":  schema.For(inputType),
+			"output_schema": schema.ForOutput(outputType),
 		})
 	}
 
 	return endpoints
 }
 
-// toSnakeCase 将 PascalCase 转换为 snake_case
-func toSnakeCase(s string) string {
-	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && unicode.IsUpper(r) {
-			result.WriteRune('_')
+// routesFor 返回 adapter 方法对应的资源风格路由（"GET /api/v1/users/{user_id}"）
+func (s *Server) routesFor(methodName string) []string {
+	routes := []string{}
+	for _, route := range restRoutes {
+		if route.Adapter == methodName {
+			routes = append(routes, route.Method+" "+route.Pattern)
 		}
-		result.WriteRune(unicode.ToLower(r))
 	}
-	return result.String()
-}
-
-// generateDescription 从方法名生成可读描述
-func generateDescription(methodName string) string {
-	var words []string
-	var currentWord strings.Builder
-
-	for i, r := range methodName {
-		if i > 0 && unicode.IsUpper(r) {
-			words = append(words, currentWord.String())
-			currentWord.Reset()
-		}
-		currentWord.WriteRune(unicode.ToLower(r))
-	}
-	words = append(words, currentWord.String())
-
-	return strings.Join(words, " ")
+	return routes
 }
 
 // respondJSON 返回 JSON 响应
@@ -268,20 +326,13 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// respondError 返回错误响应
-func respondError(w http.ResponseWriter, status int, message string) {
-	respondJSON(w, status, map[string]interface{}{
-		"error":   true,
-		"message": message,
-	})
-}
-
 // corsMiddleware 添加 CORS 支持
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", requestIDHeader+", ETag")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -303,31 +354,144 @@ func jsonContentTypeMiddleware(next http.Handler) http.Handler {
 // tokenAuthMiddleware 验证 token
 func (s *Server) tokenAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 跳过健康检查和 endpoints 列表
-		if r.URL.Path == "/health" || r.URL.Path == "/api/v1/endpoints" {
+		// 跳过健康检查、endpoints 列表和 API 文档
+		if r.URL.Path == "/health" || r.URL.Path == "/api/v1/endpoints" || isDocsPath(r.URL.Path) {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// 从 Authorization header 获取 token
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			respondError(w, http.StatusUnauthorized, "缺少 Authorization header")
+		// 每次请求时从 token 存储判断是否需要认证
+		if !s.config.TokenManager.AuthEnabled() {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		// 支持两种格式: "Bearer <token>" 或 直接 "<token>"
-		token := authHeader
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			token = strings.TrimPrefix(authHeader, "Bearer ")
+		// 来源 IP 因认证失败次数过多被锁定
+		ip := lockout.ClientIP(r)
+		if until, locked := s.throttle.LockedUntil(ip); locked {
+			retryAfter := int(time.Until(until).Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			s.respondError(w, r, http.StatusTooManyRequests, apperr.CodeRateLimited, "认证失败次数过多，请稍后重试")
+			return
 		}
 
-		// 验证 token
-		if !s.config.TokenManager.Validate(token) {
-			respondError(w, http.StatusUnauthorized, "无效的 token")
+		// 客户端证书、签名请求和 bearer token 三种认证方式
+		var tok *token.Token
+		var authErr *authError
+		if tokenID, ok := s.clientCertTokenID(r); ok {
+			tok, authErr = s.authenticateClientCert(r, ip, tokenID)
+		} else if r.Header.Get(token.HeaderSignature) != "" {
+			tok, authErr = s.authenticateSigned(r, ip)
+		} else {
+			tok, authErr = s.authenticateBearer(r, ip)
+		}
+		if authErr != nil {
+			if authErr.countFailure {
+				s.throttle.RecordFailure(ip)
+			}
+			s.respondError(w, r, authErr.status, authErr.code(), authErr.message)
 			return
 		}
+		s.throttle.RecordSuccess(ip)
+
+		// 检查 token 的访问范围（批量执行时逐个检查其中的操作，MCP 按工具检查）
+		if r.URL.Path != batchPath && !s.mcpPaths[r.URL.Path] {
+			if err := s.checkTokenScope(tok, s.endpointName(r)); err != nil {
+				s.respondError(w, r, http.StatusForbidden, apperr.CodePermissionDenied, err.Error())
+				return
+			}
+		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(token.NewContext(r.Context(), tok)))
 	})
 }
+
+// authError 认证失败信息
+type authError struct {
+	status       int    // HTTP 状态码
+	message      string // 错误信息
+	countFailure bool   // 是否计入认证失败次数
+}
+
+// code 返回认证失败对应的错误码
+func (e *authError) code() apperr.Code {
+	switch e.status {
+	case http.StatusForbidden:
+		return apperr.CodePermissionDenied
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return apperr.CodeInvalidArgument
+	case http.StatusInternalServerError:
+		return apperr.CodeInternal
+	}
+	return apperr.CodeUnauthenticated
+}
+
+// tokenStoreError 查询 token 时的存储错误（例如加密密钥已被轮换，需要重启服务）
+// 返回 500 且不计入认证失败次数，避免有效的客户端被当作无效 token 拒绝并锁定
+func tokenStoreError(err error) *authError {
+	return &authError{status: http.StatusInternalServerError, message: fmt.Sprintf("查询 token 失败: %v", err)}
+}
+
+// authenticateBearer 使用 Authorization header 中的 token 认证
+func (s *Server) authenticateBearer(r *http.Request, ip string) (*token.Token, *authError) {
+	// 从 Authorization header 获取 token
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, &authError{status: http.StatusUnauthorized, message: "缺少 Authorization header"}
+	}
+
+	// 支持两种格式: "Bearer <token>" 或 直接 "<token>"
+	tokenValue := authHeader
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		tokenValue = strings.TrimPrefix(authHeader, "Bearer ")
+	}
+
+	tok, err := s.config.TokenManager.Lookup(tokenValue)
+	if errors.Is(err, token.ErrNotFound) {
+		return nil, &authError{status: http.StatusUnauthorized, message: "无效的 token", countFailure: true}
+	}
+	if err != nil {
+		return nil, tokenStoreError(err)
+	}
+
+	// 签名认证的 token 值是签名密钥，不允许直接在请求中传输
+	if tok.SignsRequests() {
+		return nil, &authError{status: http.StatusUnauthorized, message: "该 token 仅支持签名认证", countFailure: true}
+	}
+
+	// 检查来源 IP 是否在 token 的允许列表中（早于 token 有效性验证）
+	if !tok.AllowsIP(net.ParseIP(ip)) {
+		return nil, &authError{status: http.StatusForbidden, message: fmt.Sprintf("token 不允许从 %s 访问", ip), countFailure: true}
+	}
+
+	// 验证 token
+	if !s.config.TokenManager.Validate(tokenValue) {
+		return nil, &authError{status: http.StatusUnauthorized, message: "无效的 token", countFailure: true}
+	}
+
+	return tok, nil
+}
+
+// checkTokenScope 检查 token 的 scope 是否允许访问请求的 endpoint
+func (s *Server) checkTokenScope(tok *token.Token, endpoint string) error {
+	if !tok.HasScopes() {
+		return nil
+	}
+
+	// 根据 endpoint 找到对应的资源和操作类型
+	var resource, action string
+	if methodName, exists := s.methods[endpoint]; exists {
+		if !adapter.RequiresScope(methodName) {
+			return nil
+		}
+		if op, found := adapter.LookupOperation(methodName); found {
+			resource, action = string(op.Resource), string(op.Action)
+		}
+	}
+
+	if !tok.Allows(endpoint, resource, action) {
+		return fmt.Errorf("token 无权访问 endpoint '%s'", endpoint)
+	}
+
+	return nil
+}
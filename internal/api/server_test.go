@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
 	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
 	"github.com/yourusername/youdu-app-mcp/internal/config"
 )
@@ -85,8 +86,8 @@ func TestGetEndpoints(t *testing.T) {
 	}
 
 	count := int(response["count"].(float64))
-	if count != 28 {
-		t.Errorf("期望 28 个 endpoints, 得到 %d", count)
+	if count != 31 {
+		t.Errorf("期望 31 个 endpoints, 得到 %d", count)
 	}
 }
 
@@ -104,7 +105,7 @@ func TestAPI_UserOperations(t *testing.T) {
 				t.Fatalf("序列化输入失败: %v", err)
 			}
 
-			methodName := toSnakeCase(tc.Method)
+			methodName := adapter.ToSnakeCase(tc.Method)
 			req := httptest.NewRequest("POST", "/api/v1/"+methodName, bytes.NewBuffer(inputBytes))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
@@ -152,7 +153,7 @@ func TestAPI_MessageOperations(t *testing.T) {
 				t.Fatalf("序列化输入失败: %v", err)
 			}
 
-			methodName := toSnakeCase(tc.Method)
+			methodName := adapter.ToSnakeCase(tc.Method)
 			req := httptest.NewRequest("POST", "/api/v1/"+methodName, bytes.NewBuffer(inputBytes))
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
@@ -178,3 +179,31 @@ func TestAPI_MessageOperations(t *testing.T) {
 		})
 	}
 }
+
+// TestAPI_DescribeCapabilities 测试无参数的能力说明接口
+func TestAPI_DescribeCapabilities(t *testing.T) {
+	server := setupTestServer(t)
+	if server == nil {
+		return
+	}
+
+	// 无参数方法允许空请求体
+	req := httptest.NewRequest("POST", "/api/v1/describe_capabilities", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("期望状态码 %d, 得到 %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if response["permission_enabled"] != true {
+		t.Errorf("期望 permission_enabled=true, 得到 %v", response["permission_enabled"])
+	}
+	if resources, ok := response["resources"].([]interface{}); !ok || len(resources) != 5 {
+		t.Errorf("期望 5 个资源, 得到 %v", response["resources"])
+	}
+}
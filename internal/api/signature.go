@@ -0,0 +1,96 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// defaultMaxClockSkew 未配置时允许的客户端时钟偏差
+const defaultMaxClockSkew = 5 * time.Minute
+
+// authenticateSigned 校验 HMAC 签名请求
+// 客户端使用 token 值作为密钥，对 method、path、timestamp、nonce 和 body 签名，token 本身不在请求中传输
+func (s *Server) authenticateSigned(r *http.Request, ip string) (*token.Token, *authError) {
+	keyID := r.Header.Get(token.HeaderKeyID)
+	timestampStr := r.Header.Get(token.HeaderTimestamp)
+	nonce := r.Header.Get(token.HeaderNonce)
+	signature := r.Header.Get(token.HeaderSignature)
+
+	if keyID == "" || timestampStr == "" || nonce == "" {
+		return nil, &authError{
+			status:       http.StatusUnauthorized,
+			message:      fmt.Sprintf("签名请求缺少 %s、%s 或 %s header", token.HeaderKeyID, token.HeaderTimestamp, token.HeaderNonce),
+			countFailure: true,
+		}
+	}
+
+	// 检查时间戳是否在允许的时钟偏差范围内
+	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+	if err != nil {
+		return nil, &authError{status: http.StatusUnauthorized, message: "无效的时间戳", countFailure: true}
+	}
+	maxSkew := s.maxClockSkew()
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return nil, &authError{status: http.StatusUnauthorized, message: "请求时间戳超出允许的时钟偏差范围", countFailure: true}
+	}
+
+	tok, err := s.config.TokenManager.LookupByID(keyID)
+	if err != nil && !errors.Is(err, token.ErrNotFound) {
+		return nil, tokenStoreError(err)
+	}
+	if err != nil || !tok.SignsRequests() {
+		return nil, &authError{status: http.StatusUnauthorized, message: "无效的签名 key", countFailure: true}
+	}
+
+	// 检查来源 IP 是否在 token 的允许列表中（早于 token 有效性验证）
+	if !tok.AllowsIP(net.ParseIP(ip)) {
+		return nil, &authError{status: http.StatusForbidden, message: fmt.Sprintf("token 不允许从 %s 访问", ip), countFailure: true}
+	}
+
+	if !s.config.TokenManager.Validate(tok.Value) {
+		return nil, &authError{status: http.StatusUnauthorized, message: "无效的签名 key", countFailure: true}
+	}
+
+	// 读取请求体用于校验签名（限制大小），然后还原供后续处理
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, adapter.MaxSignedBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &authError{status: http.StatusRequestEntityTooLarge, message: fmt.Sprintf("签名请求的请求体超过 %d 字节", adapter.MaxSignedBodySize)}
+		}
+		return nil, &authError{status: http.StatusBadRequest, message: fmt.Sprintf("读取请求体失败: %v", err)}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if !tok.VerifySignature(signature, r.Method, r.URL.RequestURI(), timestamp, nonce, body) {
+		return nil, &authError{status: http.StatusUnauthorized, message: "签名无效", countFailure: true}
+	}
+
+	// 签名校验通过后再记录 nonce，防止重放
+	if err := s.config.TokenManager.UseNonce(tok.ID, nonce, 2*maxSkew); err != nil {
+		return nil, &authError{status: http.StatusUnauthorized, message: err.Error(), countFailure: true}
+	}
+
+	return tok, nil
+}
+
+// maxClockSkew 返回允许的客户端时钟偏差
+func (s *Server) maxClockSkew() time.Duration {
+	if s.config.Token.HMAC.MaxSkew > 0 {
+		return s.config.Token.HMAC.MaxSkew
+	}
+	return defaultMaxClockSkew
+}
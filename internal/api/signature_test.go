@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/database"
+	"github.com/yourusername/youdu-app-mcp/internal/lockout"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// setupSignedServer 创建带签名认证 token 的测试服务器
+func setupSignedServer(t *testing.T) (*Server, *token.Token) {
+	t.Helper()
+
+	db, err := database.New(database.Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("创建测试数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Permission:   createTestPermission(),
+		TokenManager: token.NewManager(db.GetConnection()),
+	}
+
+	tok, err := cfg.TokenManager.GenerateWithOptions("webhook", nil, token.GenerateOptions{AuthMode: token.AuthModeHMAC})
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	return server, tok
+}
+
+// newSignedRequest 创建签名请求
+func newSignedRequest(tok *token.Token, path string, body []byte, timestamp int64, nonce string) *http.Request {
+	req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(token.HeaderKeyID, tok.ID)
+	req.Header.Set(token.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(token.HeaderNonce, nonce)
+	req.Header.Set(token.HeaderSignature, token.SignRequest(tok.Value, "POST", path, timestamp, nonce, body))
+	return req
+}
+
+func TestSignedRequest(t *testing.T) {
+	server, tok := setupSignedServer(t)
+	body := []byte(`{"dept_id": 0}`)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name     string
+		req      func() *http.Request
+		wantAuth bool
+	}{
+		{
+			name:     "有效签名",
+			req:      func() *http.Request { return newSignedRequest(tok, "/api/v1/get_dept_list", body, now, "nonce-1") },
+			wantAuth: true,
+		},
+		{
+			name:     "nonce 重放",
+			req:      func() *http.Request { return newSignedRequest(tok, "/api/v1/get_dept_list", body, now, "nonce-1") },
+			wantAuth: false,
+		},
+		{
+			name: "时间戳超出时钟偏差",
+			req: func() *http.Request {
+				return newSignedRequest(tok, "/api/v1/get_dept_list", body, now-int64(time.Hour.Seconds()), "nonce-2")
+			},
+			wantAuth: false,
+		},
+		{
+			name: "body 被篡改",
+			req: func() *http.Request {
+				req := newSignedRequest(tok, "/api/v1/get_dept_list", body, now, "nonce-3")
+				req.Body = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"dept_id": 1}`))).Body
+				return req
+			},
+			wantAuth: false,
+		},
+		{
+			name: "签名 token 不能作为 bearer token 使用",
+			req: func() *http.Request {
+				req := httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader(body))
+				req.Header.Set("Authorization", "Bearer "+tok.Value)
+				return req
+			},
+			wantAuth: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.router.ServeHTTP(rr, tt.req())
+
+			authed := rr.Code != http.StatusUnauthorized && rr.Code != http.StatusForbidden
+			if authed != tt.wantAuth {
+				t.Errorf("期望认证结果 %v，得到状态码 %d: %s", tt.wantAuth, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestSignedRequest_BodyTooLarge(t *testing.T) {
+	server, tok := setupSignedServer(t)
+
+	body := bytes.Repeat([]byte("a"), adapter.MaxSignedBodySize+1)
+	req := newSignedRequest(tok, "/api/v1/get_dept_list", body, time.Now().Unix(), "nonce-large")
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("期望状态码 %d，得到 %d: %s", http.StatusRequestEntityTooLarge, rr.Code, rr.Body.String())
+	}
+}
+
+func TestSignedRequest_MalformedCountsAsFailure(t *testing.T) {
+	server, tok := setupSignedServer(t)
+	server.throttle = lockout.New(config.LockoutConfig{MaxAttempts: 3, Window: time.Minute, Duration: time.Minute})
+	body := []byte(`{"dept_id": 0}`)
+	now := time.Now().Unix()
+
+	malformed := []func() *http.Request{
+		// 缺少 nonce
+		func() *http.Request {
+			req := newSignedRequest(tok, "/api/v1/get_dept_list", body, now, "nonce-a")
+			req.Header.Del(token.HeaderNonce)
+			return req
+		},
+		// 无效的时间戳
+		func() *http.Request {
+			req := newSignedRequest(tok, "/api/v1/get_dept_list", body, now, "nonce-b")
+			req.Header.Set(token.HeaderTimestamp, "not-a-number")
+			return req
+		},
+		// 签名无效
+		func() *http.Request {
+			req := newSignedRequest(tok, "/api/v1/get_dept_list", body, now, "nonce-c")
+			req.Header.Set(token.HeaderSignature, "invalid")
+			return req
+		},
+	}
+	for i, newReq := range malformed {
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, newReq())
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("第 %d 次失败：期望状态码 %d，得到 %d", i+1, http.StatusUnauthorized, rr.Code)
+		}
+	}
+
+	// 锁定后，即使签名有效也被拒绝
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, newSignedRequest(tok, "/api/v1/get_dept_list", body, now, "nonce-d"))
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("期望状态码 %d，得到 %d", http.StatusTooManyRequests, rr.Code)
+	}
+}
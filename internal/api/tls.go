@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// clientCertTokenID 返回已校验的客户端证书映射的 token ID
+func (s *Server) clientCertTokenID(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	for _, mapping := range s.config.TLS.ClientSubjects {
+		if mapping.Subject == cert.Subject.CommonName || mapping.Subject == cert.Subject.String() {
+			return mapping.TokenID, true
+		}
+	}
+
+	return "", false
+}
+
+// authenticateClientCert 使用已校验的客户端证书认证（证书 subject 映射到 token）
+func (s *Server) authenticateClientCert(r *http.Request, ip, tokenID string) (*token.Token, *authError) {
+	tok, err := s.config.TokenManager.LookupByID(tokenID)
+	if errors.Is(err, token.ErrNotFound) {
+		return nil, &authError{status: http.StatusUnauthorized, message: "客户端证书映射的 token 不存在"}
+	}
+	if err != nil {
+		return nil, tokenStoreError(err)
+	}
+
+	// 检查来源 IP 是否在 token 的允许列表中（早于 token 有效性验证）
+	if !tok.AllowsIP(net.ParseIP(ip)) {
+		return nil, &authError{status: http.StatusForbidden, message: fmt.Sprintf("token 不允许从 %s 访问", ip), countFailure: true}
+	}
+
+	if !s.config.TokenManager.Validate(tok.Value) {
+		return nil, &authError{status: http.StatusUnauthorized, message: "客户端证书映射的 token 已失效"}
+	}
+
+	return tok, nil
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/database"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// newTestCert 生成测试证书，parent 为 nil 时生成自签名 CA
+func newTestCert(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("解析证书失败: %v", err)
+	}
+	return cert, key
+}
+
+// writeTestPEM 将证书和私钥写入临时文件，返回证书和私钥路径
+func writeTestPEM(t *testing.T, name string, cert *x509.Certificate, key *ecdsa.PrivateKey) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatalf("写入证书失败: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("编码私钥失败: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("写入私钥失败: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestClientCertAuth(t *testing.T) {
+	db, err := database.New(database.Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("创建测试数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	mgr := token.NewManager(db.GetConnection())
+	tok, err := mgr.GenerateWithOptions("billing", nil, token.GenerateOptions{Scopes: []string{"dept:read"}})
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Permission:   createTestPermission(),
+		TokenManager: mgr,
+		TLS: config.TLSConfig{
+			ClientSubjects: []config.ClientSubjectMapping{
+				{Subject: "billing-service", TokenID: tok.ID},
+				{Subject: "CN=deleted-service", TokenID: "missing"},
+			},
+		},
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	ca, caKey := newTestCert(t, "test-ca", nil, nil)
+
+	// withClientCert 模拟已通过 TLS 握手校验的客户端证书
+	withClientCert := func(req *http.Request, commonName string) *http.Request {
+		cert, _ := newTestCert(t, commonName, ca, caKey)
+		req.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert, ca}},
+		}
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		req      func() *http.Request
+		wantCode int
+	}{
+		{
+			name: "映射的证书无需 bearer token",
+			req: func() *http.Request {
+				return withClientCert(httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{"dept_id": 0}`))), "billing-service")
+			},
+			wantCode: 0,
+		},
+		{
+			name: "映射 token 的 scope 仍然生效",
+			req: func() *http.Request {
+				return withClientCert(httptest.NewRequest("POST", "/api/v1/send_text_message", bytes.NewReader([]byte(`{}`))), "billing-service")
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "映射到不存在的 token",
+			req: func() *http.Request {
+				return withClientCert(httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{}`))), "deleted-service")
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "未映射的证书仍需 token",
+			req: func() *http.Request {
+				return withClientCert(httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{}`))), "unknown-service")
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "未校验的证书不参与认证",
+			req: func() *http.Request {
+				req := withClientCert(httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{}`))), "billing-service")
+				req.TLS.VerifiedChains = nil
+				return req
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.router.ServeHTTP(rr, tt.req())
+
+			if tt.wantCode == 0 {
+				if rr.Code == http.StatusUnauthorized || rr.Code == http.StatusForbidden {
+					t.Errorf("期望认证通过，得到状态码 %d: %s", rr.Code, rr.Body.String())
+				}
+				return
+			}
+			if rr.Code != tt.wantCode {
+				t.Errorf("期望状态码 %d，得到 %d: %s", tt.wantCode, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	ca, caKey := newTestCert(t, "test-ca", nil, nil)
+	caPath, _ := writeTestPEM(t, "ca", ca, caKey)
+	serverCert, serverKey := newTestCert(t, "localhost", ca, caKey)
+	certPath, keyPath := writeTestPEM(t, "server", serverCert, serverKey)
+
+	invalidCA := filepath.Join(t.TempDir(), "invalid.crt")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		tls            config.TLSConfig
+		wantErr        bool
+		wantClientAuth tls.ClientAuthType
+	}{
+		{
+			name:           "仅 HTTPS",
+			tls:            config.TLSConfig{CertFile: certPath, KeyFile: keyPath},
+			wantClientAuth: tls.NoClientCert,
+		},
+		{
+			name:           "双向 TLS（默认提供时校验）",
+			tls:            config.TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCAFile: caPath},
+			wantClientAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name:           "双向 TLS（必须提供证书）",
+			tls:            config.TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCAFile: caPath, ClientAuth: "require"},
+			wantClientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:           "双向 TLS（显式关闭）",
+			tls:            config.TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCAFile: caPath, ClientAuth: "none"},
+			wantClientAuth: tls.NoClientCert,
+		},
+		{
+			name:    "无效的 client_auth",
+			tls:     config.TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCAFile: caPath, ClientAuth: "optional"},
+			wantErr: true,
+		},
+		{
+			name:    "无效的 CA 文件",
+			tls:     config.TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCAFile: invalidCA},
+			wantErr: true,
+		},
+		{
+			name:    "证书文件不存在",
+			tls:     config.TLSConfig{CertFile: filepath.Join(t.TempDir(), "missing.crt"), KeyFile: keyPath},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.tls.Build()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("期望返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("创建 TLS 配置失败: %v", err)
+			}
+			if tlsConfig.ClientAuth != tt.wantClientAuth {
+				t.Errorf("期望 ClientAuth %v，得到 %v", tt.wantClientAuth, tlsConfig.ClientAuth)
+			}
+		})
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	_ "modernc.org/sqlite"
 
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
 	"github.com/yourusername/youdu-app-mcp/internal/config"
 	"github.com/yourusername/youdu-app-mcp/internal/permission"
 	"github.com/yourusername/youdu-app-mcp/internal/token"
@@ -46,7 +51,11 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 		value TEXT UNIQUE NOT NULL,
 		description TEXT NOT NULL,
 		created_at DATETIME NOT NULL,
-		expires_at DATETIME
+		expires_at DATETIME,
+		scopes TEXT NOT NULL DEFAULT '',
+		allowed_cidrs TEXT NOT NULL DEFAULT '',
+		auth_mode TEXT NOT NULL DEFAULT 'bearer',
+		value_hash TEXT UNIQUE
 	);
 	`
 	_, err = db.Exec(schema)
@@ -398,4 +407,400 @@ func TestEndpointsListing_NoTokenRequired(t *testing.T) {
 	if _, ok := response["endpoints"]; !ok {
 		t.Error("响应中缺少 endpoints 字段")
 	}
+
+	// OpenAPI 文档和文档页面同样不需要 token
+	for _, path := range []string{openAPIPath, docsPath, docsPath + "/viewer.js", docsPath + "/viewer.css"} {
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: 期望状态码 %v，得到 %v", path, http.StatusOK, rr.Code)
+		}
+	}
+
+	// 启用认证时文档不允许匿名访问
+	for _, requirement := range server.openAPISecurity() {
+		if len(requirement.(map[string]interface{})) == 0 {
+			t.Error("启用认证时 security 不应包含匿名访问")
+		}
+	}
+}
+
+func TestTokenAuthMiddleware_ScopeDenied(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// 创建配置和 token 管理器
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Permission:   createTestPermission(),
+		TokenManager: token.NewManager(db),
+	}
+
+	// 添加一个只能发送消息的 token
+	testToken := &token.Token{
+		ID:          "ci001",
+		Value:       "ci-token-value",
+		Description: "CI token",
+		Scopes:      []string{"message:send"},
+	}
+	if err := cfg.TokenManager.Add(testToken); err != nil {
+		t.Fatalf("添加 token 失败: %v", err)
+	}
+
+	// 创建服务器
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	// 调用 delete_user 应该被拒绝
+	req := httptest.NewRequest("POST", "/api/v1/delete_user", bytes.NewReader([]byte(`{"user_id": "10232"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer ci-token-value")
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("期望状态码 %v，得到 %v", http.StatusForbidden, status)
+	}
+
+	// 调用 send_text_message 应该通过 scope 检查
+	req = httptest.NewRequest("POST", "/api/v1/send_text_message", bytes.NewReader([]byte(`{"to_user": "10232", "content": "hi"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer ci-token-value")
+
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status == http.StatusForbidden || status == http.StatusUnauthorized {
+		t.Errorf("不应该返回 %v", status)
+	}
+
+	// 能力说明不受 scope 限制，且只列出 scope 允许的操作
+	req = httptest.NewRequest("GET", "/api/v1/capabilities", nil)
+	req.Header.Set("Authorization", "Bearer ci-token-value")
+
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("期望状态码 %v，得到 %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var capabilities adapter.DescribeCapabilitiesOutput
+	if err := json.Unmarshal(rr.Body.Bytes(), &capabilities); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	for _, c := range capabilities.Resources {
+		if c.Resource != "message" && len(c.Operations) > 0 {
+			t.Errorf("scope 外的资源不应有可调用的操作: %+v", c)
+		}
+	}
+	if len(capabilities.Scopes) != 1 || capabilities.Scopes[0] != "message:send" {
+		t.Errorf("scopes = %v", capabilities.Scopes)
+	}
+}
+
+func TestTokenAuthMiddleware_TokenAddedAfterStartup(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Permission:   createTestPermission(),
+		TokenManager: token.NewManager(db),
+	}
+
+	// 在空数据库上创建服务器
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	// 启动后生成 token
+	if _, err := cfg.TokenManager.Generate("late token", nil); err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	// 不带 token 的请求应该被拒绝
+	req := httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{"dept_id": 0}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("期望状态码 %v，得到 %v", http.StatusUnauthorized, status)
+	}
+}
+
+func TestTokenAuthMiddleware_RequiredWithoutTokens(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mgr := token.NewManager(db)
+	mgr.SetRequired(true)
+
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Permission:   createTestPermission(),
+		TokenManager: mgr,
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	// 强制模式下，即使没有任何 token 也要求认证
+	req := httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{"dept_id": 0}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer anything")
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnauthorized {
+		t.Errorf("期望状态码 %v，得到 %v", http.StatusUnauthorized, status)
+	}
+}
+
+func TestTokenAuthMiddleware_CIDRDenied(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Permission:   createTestPermission(),
+		TokenManager: token.NewManager(db),
+	}
+
+	// 添加一个只允许内网访问的 token
+	testToken := &token.Token{
+		ID:           "office001",
+		Value:        "office-token-value",
+		Description:  "Office token",
+		AllowedCIDRs: []string{"10.0.0.0/8"},
+	}
+	if err := cfg.TokenManager.Add(testToken); err != nil {
+		t.Fatalf("添加 token 失败: %v", err)
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	// httptest 请求的来源地址为 192.0.2.1，不在允许列表中
+	req := httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{"dept_id": 0}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer office-token-value")
+
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("期望状态码 %v，得到 %v", http.StatusForbidden, status)
+	}
+}
+
+func TestTokenAuthMiddleware_Lockout(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Token: config.TokenConfig{
+			Lockout: config.LockoutConfig{MaxAttempts: 3, Window: time.Minute, Duration: time.Minute},
+		},
+		Permission:   createTestPermission(),
+		TokenManager: token.NewManager(db),
+	}
+
+	testToken := &token.Token{
+		ID:          "test001",
+		Value:       "test-token-value",
+		Description: "Test token",
+	}
+	cfg.TokenManager.Add(testToken)
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	send := func(tokenValue string) int {
+		req := httptest.NewRequest("POST", "/api/v1/get_dept_list", bytes.NewReader([]byte(`{"dept_id": 0}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokenValue)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if status := send("wrong-token"); status != http.StatusUnauthorized {
+			t.Fatalf("第 %d 次失败：期望状态码 %v，得到 %v", i+1, http.StatusUnauthorized, status)
+		}
+	}
+
+	// 锁定后，即使使用有效 token 也被拒绝
+	if status := send("test-token-value"); status != http.StatusTooManyRequests {
+		t.Errorf("期望状态码 %v，得到 %v", http.StatusTooManyRequests, status)
+	}
+}
+
+func TestTokenAuthMiddleware_MCPLockout(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Token: config.TokenConfig{
+			Lockout: config.LockoutConfig{MaxAttempts: 3, Window: time.Minute, Duration: time.Minute},
+		},
+		Permission:   createTestPermission(),
+		TokenManager: token.NewManager(db),
+	}
+
+	testToken := &token.Token{
+		ID:          "test001",
+		Value:       "test-token-value",
+		Description: "Test token",
+	}
+	cfg.TokenManager.Add(testToken)
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	// MCP handler 收到 API 认证通过的 token
+	var received *token.Token
+	server.MountMCP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = token.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}), "/mcp")
+
+	send := func(tokenValue string) int {
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokenValue)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if status := send("test-token-value"); status != http.StatusOK {
+		t.Fatalf("期望状态码 %v，得到 %v", http.StatusOK, status)
+	}
+	if received == nil || received.ID != "test001" {
+		t.Fatalf("期望 MCP handler 收到 token test001，得到 %+v", received)
+	}
+
+	for i := 0; i < 3; i++ {
+		if status := send("wrong-token"); status != http.StatusUnauthorized {
+			t.Fatalf("第 %d 次失败：期望状态码 %v，得到 %v", i+1, http.StatusUnauthorized, status)
+		}
+	}
+
+	// 锁定后，即使使用有效 token 也被拒绝
+	if status := send("test-token-value"); status != http.StatusTooManyRequests {
+		t.Errorf("期望状态码 %v，得到 %v", http.StatusTooManyRequests, status)
+	}
+}
+
+// brokenStore 查询指定 token 时返回存储错误（例如加密密钥已被轮换）
+type brokenStore struct {
+	*token.MemoryStore
+	broken string
+}
+
+func (s *brokenStore) GetByValue(value string) (*token.Token, error) {
+	if value == s.broken {
+		return nil, errors.New("加密密钥已被轮换")
+	}
+	return s.MemoryStore.GetByValue(value)
+}
+
+func TestTokenAuthMiddleware_StoreError(t *testing.T) {
+	store := &brokenStore{MemoryStore: token.NewMemoryStore(), broken: "broken-token"}
+	cfg := &config.Config{
+		Youdu: config.YouduConfig{
+			Addr:   "http://test-server:7080",
+			Buin:   12345678,
+			AppID:  "test-app",
+			AesKey: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI=",
+		},
+		Token: config.TokenConfig{
+			Lockout: config.LockoutConfig{MaxAttempts: 1, Window: time.Minute, Duration: time.Minute},
+		},
+		Permission:   createTestPermission(),
+		TokenManager: token.NewManagerWithStore(store),
+	}
+	cfg.TokenManager.Add(&token.Token{ID: "test001", Value: "test-token-value", Description: "Test token"})
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务器失败: %v", err)
+	}
+	defer server.Close()
+	server.MountMCP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "/mcp")
+
+	send := func(tokenValue string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set("Authorization", "Bearer "+tokenValue)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// 存储错误返回 500，而不是当作无效 token
+	rr := send("broken-token")
+	if rr.Code != http.StatusInternalServerError || !strings.Contains(rr.Body.String(), string(apperr.CodeInternal)) {
+		t.Fatalf("期望状态码 %v 和错误码 internal，得到 %v: %s", http.StatusInternalServerError, rr.Code, rr.Body.String())
+	}
+
+	// 存储错误不计入认证失败次数
+	if rr := send("test-token-value"); rr.Code != http.StatusOK {
+		t.Errorf("期望存储错误后有效 token 仍可认证，得到 %v", rr.Code)
+	}
 }
@@ -0,0 +1,141 @@
+// Package apperr 定义 adapter、MCP 和 HTTP API 共用的类型化错误
+// 每个错误带有机器可读的错误码，调用方（尤其是 LLM agent）可以据此决定如何恢复
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/addcnos/youdu/v2"
+)
+
+// Code 错误码
+type Code string
+
+const (
+	CodeUnauthenticated      Code = "unauthenticated"       // 缺少或无效的认证信息
+	CodePermissionDenied     Code = "permission_denied"     // 权限策略或 token scope 不允许
+	CodeInvalidArgument      Code = "invalid_argument"      // 参数缺失或不符合 schema
+	CodeNotFound             Code = "not_found"             // 资源或文件不存在
+	CodeConfirmationRequired Code = "confirmation_required" // 破坏性操作未经用户确认
+	CodeUpstream             Code = "upstream_error"        // 有度服务器返回错误码或无法访问
+	CodeTimeout              Code = "timeout"               // 请求超时
+	CodeCancelled            Code = "cancelled"             // 请求被取消
+	CodeRateLimited          Code = "rate_limited"          // 认证失败次数过多被临时锁定
+	CodeInternal             Code = "internal"              // 其他错误
+)
+
+// invalidArgument 由参数校验错误实现（例如 schema.FieldError）
+// schema 的测试依赖 adapter，adapter 依赖本包，因此这里不直接引用 schema
+type invalidArgument interface {
+	InvalidArgument() bool
+}
+
+// Error 类型化错误
+type Error struct {
+	Code         Code   // 错误码
+	Message      string // 错误信息（不含处理建议）
+	YouduErrCode int    // 有度返回的 errcode（仅 CodeUpstream）
+	Err          error  // 原始错误
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New 创建指定错误码的错误
+func New(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap 用指定错误码包装 err，message 为空时使用 err 的信息
+func Wrap(code Code, err error, message string) *Error {
+	if message == "" {
+		message = err.Error()
+	} else {
+		message = message + ": " + err.Error()
+	}
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// From 将任意错误转换为类型化错误
+// 已经是 *Error 的直接返回；其他错误按 context、有度 errcode、schema 校验和文件系统错误分类
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		// 外层可能附加了上下文信息，保留完整信息
+		if appErr.Message == err.Error() {
+			return appErr
+		}
+		return &Error{Code: appErr.Code, Message: err.Error(), YouduErrCode: appErr.YouduErrCode, Err: err}
+	}
+
+	e := &Error{Code: CodeInternal, Message: err.Error(), Err: err}
+
+	var youduErr *youdu.Error
+	var argErr invalidArgument
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		e.Code = CodeTimeout
+	case errors.Is(err, context.Canceled):
+		e.Code = CodeCancelled
+	case errors.As(err, &youduErr):
+		e.Code = CodeUpstream
+		e.YouduErrCode = youduErr.Code
+		if isNotFoundMessage(youduErr.Message) {
+			e.Code = CodeNotFound
+		}
+	case errors.As(err, &argErr) && argErr.InvalidArgument():
+		e.Code = CodeInvalidArgument
+	case errors.Is(err, fs.ErrNotExist):
+		e.Code = CodeNotFound
+	case errors.As(err, &netErr) && netErr.Timeout():
+		e.Code = CodeTimeout
+	case isTransportError(err):
+		e.Code = CodeUpstream
+	}
+	return e
+}
+
+// CodeOf 返回错误的错误码，err 为 nil 时返回空字符串
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	return From(err).Code
+}
+
+// isTransportError 判断是否为访问有度服务器时的网络错误（连接被拒绝、DNS 失败、重试耗尽等）
+func isTransportError(err error) bool {
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &urlErr) ||
+		errors.As(err, &opErr) ||
+		errors.As(err, &dnsErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		// go-retryablehttp 重试耗尽后返回的错误（未包装底层错误时）
+		strings.Contains(err.Error(), "giving up after")
+}
+
+// isNotFoundMessage 根据有度返回的 errmsg 判断是否为资源不存在
+func isNotFoundMessage(message string) bool {
+	lower := strings.ToLower(message)
+	return strings.Contains(lower, "not exist") || strings.Contains(lower, "not found") || strings.Contains(message, "不存在")
+}
@@ -0,0 +1,77 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/addcnos/youdu/v2"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  Code
+		wantErrNo int
+	}{
+		{"类型化错误", New(CodePermissionDenied, "权限拒绝"), CodePermissionDenied, 0},
+		{"包装后的类型化错误", fmt.Errorf("获取用户失败: %w", New(CodeNotFound, "不存在")), CodeNotFound, 0},
+		{"超时", fmt.Errorf("请求失败: %w", context.DeadlineExceeded), CodeTimeout, 0},
+		{"取消", context.Canceled, CodeCancelled, 0},
+		{"有度错误码", fmt.Errorf("发送失败: %w", &youdu.Error{Code: 40001, Message: "invalid appid"}), CodeUpstream, 40001},
+		{"有度资源不存在", &youdu.Error{Code: 40009, Message: "user not exist"}, CodeNotFound, 40009},
+		{"字段校验", &schema.FieldError{Field: "file_type", Message: "取值无效"}, CodeInvalidArgument, 0},
+		{"文件不存在", fmt.Errorf("打开文件失败: %w", fs.ErrNotExist), CodeNotFound, 0},
+		{"连接被拒绝", &url.Error{Op: "Post", URL: "http://127.0.0.1:1/cgi/user/get", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, CodeUpstream, 0},
+		{"重试耗尽", fmt.Errorf("获取用户失败: %w", errors.New("POST http://127.0.0.1:1/cgi/user/get giving up after 5 attempt(s)")), CodeUpstream, 0},
+		{"其他错误", errors.New("boom"), CodeInternal, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := From(tt.err)
+			if e.Code != tt.wantCode {
+				t.Errorf("Code = %s, 期望 %s", e.Code, tt.wantCode)
+			}
+			if e.YouduErrCode != tt.wantErrNo {
+				t.Errorf("YouduErrCode = %d, 期望 %d", e.YouduErrCode, tt.wantErrNo)
+			}
+			// 保留外层附加的上下文信息
+			if e.Message != tt.err.Error() {
+				t.Errorf("Message = %q, 期望 %q", e.Message, tt.err.Error())
+			}
+		})
+	}
+
+	if From(nil) != nil || CodeOf(nil) != "" {
+		t.Error("nil 错误应返回 nil")
+	}
+}
+
+func TestHint(t *testing.T) {
+	e := From(&youdu.Error{Code: 40001, Message: "invalid appid"})
+	if hint := e.Hint("zh"); !strings.HasPrefix(hint, "有度错误码 40001。") {
+		t.Errorf("中文提示 = %q", hint)
+	}
+	if hint := e.Hint("en"); !strings.HasPrefix(hint, "YouDu errcode 40001. ") {
+		t.Errorf("英文提示 = %q", hint)
+	}
+
+	// 每个错误码都有两种语言的提示
+	for code, h := range hints {
+		if h.zh == "" || h.en == "" {
+			t.Errorf("%s 缺少提示", code)
+		}
+	}
+	if hint := (&Error{Code: "unknown"}).Hint("zh"); hint != hints[CodeInternal].zh {
+		t.Errorf("未知错误码应使用 internal 的提示，得到 %q", hint)
+	}
+}
@@ -0,0 +1,70 @@
+package apperr
+
+import "fmt"
+
+// languageEN 与 config.LanguageEN 一致（config 经 permission 依赖本包，不能反向引用）
+const languageEN = "en"
+
+// hints 各错误码的处理建议
+var hints = map[Code]struct{ zh, en string }{
+	CodeUnauthenticated: {
+		zh: "提供有效的 token（Authorization: Bearer <token>）、签名请求或客户端证书。",
+		en: "Provide a valid token (Authorization: Bearer <token>), a signed request or a client certificate.",
+	},
+	CodePermissionDenied: {
+		zh: "当前权限策略或 token 不允许该操作。调用 describe_capabilities 查看允许的资源、操作和 ID，不要原样重试。",
+		en: "The permission policy or token does not allow this. Call describe_capabilities to see the allowed resources, actions and IDs; do not retry the same call.",
+	},
+	CodeInvalidArgument: {
+		zh: "检查参数是否符合工具的输入 schema（必填项、类型、枚举和取值范围），修正后重试。",
+		en: "Check the arguments against the tool's input schema (required fields, types, enums and ranges), fix them and retry.",
+	},
+	CodeNotFound: {
+		zh: "确认 ID 或路径是否正确，可以先用 get_dept_list、get_dept_user_list、get_user 等读取方法查找。",
+		en: "Check the ID or path; look it up first with read tools such as get_dept_list, get_dept_user_list or get_user.",
+	},
+	CodeConfirmationRequired: {
+		zh: "该操作不可恢复，请先向用户说明并取得明确同意后再调用。",
+		en: "This operation cannot be undone; explain it to the user and get explicit consent before calling again.",
+	},
+	CodeUpstream: {
+		zh: "有度服务器返回错误或无法访问。有错误码时检查参数是否正确，否则稍后重试；如果持续失败请联系管理员。",
+		en: "The YouDu server returned an error or could not be reached. With an errcode, check the arguments; otherwise retry later. Contact an administrator if it keeps failing.",
+	},
+	CodeTimeout: {
+		zh: "请求超时，可以稍后重试。",
+		en: "The request timed out; retry later.",
+	},
+	CodeCancelled: {
+		zh: "请求已被取消，如仍需要可以重新调用。",
+		en: "The request was cancelled; call again if it is still needed.",
+	},
+	CodeRateLimited: {
+		zh: "认证失败次数过多，按 Retry-After 等待后再使用正确的凭据重试。",
+		en: "Too many failed authentication attempts; wait for Retry-After, then retry with valid credentials.",
+	},
+	CodeInternal: {
+		zh: "服务内部错误。可以重试一次；如果持续失败请联系管理员。",
+		en: "Internal error. Retry once; contact an administrator if it keeps failing.",
+	},
+}
+
+// Hint 返回错误在指定语言（zh / en，其他值按 zh 处理）下的处理建议
+func (e *Error) Hint(language string) string {
+	h, ok := hints[e.Code]
+	if !ok {
+		h = hints[CodeInternal]
+	}
+	hint := h.zh
+	if language == languageEN {
+		hint = h.en
+	}
+	if e.Code == CodeUpstream && e.YouduErrCode != 0 {
+		if language == languageEN {
+			hint = fmt.Sprintf("YouDu errcode %d. %s", e.YouduErrCode, hint)
+		} else {
+			hint = fmt.Sprintf("有度错误码 %d。%s", e.YouduErrCode, hint)
+		}
+	}
+	return hint
+}
@@ -0,0 +1,408 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/database"
+)
+
+var (
+	dbRollbackSteps int
+	dbOutputJSON    bool
+	dbCheckFix      bool
+	dbNewKeyFile    string
+)
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "数据库管理",
+	Long: `管理本地 SQLite 数据库，包括结构迁移、备份恢复和完整性检查。
+
+默认情况下服务启动时会自动执行迁移；在配置中设置 db.disable_auto_migrate: true
+后需要使用 'youdu-cli db migrate' 手动执行。`,
+}
+
+// dbMigrateCmd applies pending migrations
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "执行尚未执行的迁移",
+	Long: `执行所有尚未执行的数据库迁移。
+
+多个进程同时执行迁移时，只有一个进程会实际执行，其余进程等待后跳过。
+
+示例:
+  youdu-cli db migrate
+  youdu-cli db migrate --config config.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		applied, err := db.Migrate()
+		if err != nil {
+			return fmt.Errorf("迁移失败: %w", err)
+		}
+
+		if len(applied) == 0 {
+			fmt.Printf("✅ 数据库结构已是最新版本 (版本 %d)\n", database.LatestVersion())
+			return nil
+		}
+
+		for _, m := range applied {
+			fmt.Printf("  ⬆️  %d_%s\n", m.Version, m.Name)
+		}
+		fmt.Printf("✅ 已执行 %d 个迁移，当前版本 %d\n", len(applied), database.LatestVersion())
+
+		return nil
+	},
+}
+
+// dbStatusCmd shows migration status
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看迁移状态",
+	Long: `列出所有迁移及其执行状态。
+
+示例:
+  youdu-cli db status
+  youdu-cli db status --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		statuses, err := db.MigrationStatus()
+		if err != nil {
+			return fmt.Errorf("查询迁移状态失败: %w", err)
+		}
+
+		if dbOutputJSON {
+			output, _ := json.MarshalIndent(statuses, "", "  ")
+			fmt.Println(string(output))
+			return nil
+		}
+
+		fmt.Printf("\n📋 数据库迁移 (%s):\n\n", db.GetPath())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "Version\tName\tApplied At")
+		fmt.Fprintln(w, "---\t---\t---")
+
+		pending := 0
+		for _, s := range statuses {
+			appliedAt := "⏳ 未执行"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			} else {
+				pending++
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+
+		w.Flush()
+		fmt.Println()
+		if pending > 0 {
+			fmt.Printf("💡 有 %d 个迁移尚未执行，使用 'youdu-cli db migrate' 执行\n", pending)
+		}
+
+		return nil
+	},
+}
+
+// dbRollbackCmd rolls back applied migrations
+var dbRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "回滚最近执行的迁移",
+	Long: `按执行顺序倒序回滚最近执行的迁移（默认 1 个）。
+
+回滚会删除对应的表或列及其中的数据，请先备份数据库。
+如果未禁用自动迁移，服务下次启动时会重新执行被回滚的迁移。
+
+示例:
+  youdu-cli db rollback
+  youdu-cli db rollback --steps 2`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		rolledBack, err := db.Rollback(dbRollbackSteps)
+		if err != nil {
+			return fmt.Errorf("回滚失败: %w", err)
+		}
+
+		if len(rolledBack) == 0 {
+			fmt.Println("📭 没有可回滚的迁移")
+			return nil
+		}
+
+		for _, m := range rolledBack {
+			fmt.Printf("  ⬇️  %d_%s\n", m.Version, m.Name)
+		}
+		fmt.Printf("✅ 已回滚 %d 个迁移\n", len(rolledBack))
+
+		return nil
+	},
+}
+
+// dbBackupCmd backs up the database
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup <file>",
+	Short: "备份数据库",
+	Long: `将数据库在线备份到指定文件（VACUUM INTO），服务运行期间也可以执行。
+
+目标文件已存在时返回错误。
+
+示例:
+  youdu-cli db backup backups/youdu-20250101.db`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := db.Backup(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ 数据库已备份到 %s\n", args[0])
+		return nil
+	},
+}
+
+// dbRestoreCmd restores the database from a backup
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "从备份恢复数据库",
+	Long: `使用备份文件替换当前数据库。
+
+备份文件需要通过完整性检查。恢复前请先停止 serve-api 和 youdu-mcp 等使用数据库的服务。
+恢复后服务启动时会自动执行备份之后新增的迁移。
+
+示例:
+  youdu-cli db restore backups/youdu-20250101.db`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbCfg, err := config.LoadDatabaseConfig(cfgFile)
+		if err != nil {
+			return fmt.Errorf("加载配置失败: %w", err)
+		}
+		if dbCfg.Path == "" {
+			dbCfg.Path = "./youdu.db"
+		}
+
+		if err := database.Restore(args[0], dbCfg.Path); err != nil {
+			return fmt.Errorf("恢复失败: %w", err)
+		}
+
+		fmt.Printf("✅ 已使用 %s 恢复数据库 %s\n", args[0], dbCfg.Path)
+		return nil
+	},
+}
+
+// dbCheckCmd checks database integrity
+var dbCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "检查数据库完整性",
+	Long: `执行 SQLite 完整性检查（PRAGMA integrity_check）并检测孤立记录。
+
+发现问题时以非零状态退出；使用 --fix 删除孤立记录。
+
+示例:
+  youdu-cli db check
+  youdu-cli db check --fix
+  youdu-cli db check --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if dbCheckFix {
+			deleted, err := db.DeleteOrphans()
+			if err != nil {
+				return err
+			}
+			if deleted > 0 && !dbOutputJSON {
+				fmt.Printf("🧹 已删除 %d 条孤立记录\n", deleted)
+			}
+		}
+
+		result, err := db.Check()
+		if err != nil {
+			return err
+		}
+
+		if dbOutputJSON {
+			output, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(output))
+		} else {
+			if len(result.Integrity) == 0 {
+				fmt.Println("✅ 完整性检查: 通过")
+			} else {
+				fmt.Println("❌ 完整性检查: 发现问题")
+				for _, problem := range result.Integrity {
+					fmt.Printf("  - %s\n", problem)
+				}
+			}
+
+			if len(result.Orphans) == 0 {
+				fmt.Println("✅ 孤立记录: 无")
+			} else {
+				fmt.Println("⚠️  孤立记录:")
+				for _, orphan := range result.Orphans {
+					fmt.Printf("  - %s: %d 条%s\n", orphan.Table, orphan.Count, orphan.Description)
+				}
+				fmt.Println("\n💡 提示: 使用 'youdu-cli db check --fix' 删除孤立记录")
+			}
+		}
+
+		if !result.OK() {
+			return fmt.Errorf("数据库检查未通过")
+		}
+		return nil
+	},
+}
+
+// dbRekeyCmd rotates encryption keys
+var dbRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "轮换数据加密密钥",
+	Long: `生成新的数据密钥并重新加密所有加密列（例如 token 值）。
+
+使用当前配置的主密钥（db.encryption.key_file 或环境变量 YOUDU_DB_ENCRYPTION_KEY）解密已有数据。
+指定 --new-key-file 时同时更换主密钥（旧的数据密钥使用新主密钥重新包装），完成后需要将配置更新为新的密钥文件。
+正在运行的服务遇到新的数据密钥时会自动重新加载；更换主密钥后，仍使用旧主密钥的服务会返回 500 并提示使用新的主密钥重启。
+首次启用加密时，也使用该命令加密已有的明文数据。
+
+执行前请先备份数据库，并停止 serve-api 和 youdu-mcp 等使用数据库的服务。
+
+生成主密钥:
+  openssl rand -base64 32 > youdu.key
+
+示例:
+  youdu-cli db rekey
+  youdu-cli db rekey --new-key-file youdu-new.key`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var newMaster []byte
+		if dbNewKeyFile != "" {
+			key, err := database.ReadMasterKeyFile(dbNewKeyFile)
+			if err != nil {
+				return err
+			}
+			newMaster = key
+		}
+
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		count, err := db.Rekey(newMaster)
+		if err != nil {
+			return fmt.Errorf("密钥轮换失败: %w", err)
+		}
+
+		fmt.Printf("✅ 已使用新的数据密钥重新加密 %d 条记录\n", count)
+		if dbNewKeyFile != "" {
+			fmt.Printf("\n💡 提示: 请将 db.encryption.key_file 更新为 %s（或更新环境变量 %s），旧主密钥已不再可用\n", dbNewKeyFile, database.EncryptionKeyEnv)
+		}
+		return nil
+	},
+}
+
+// dbDecryptCmd decrypts all encrypted columns
+var dbDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "将加密数据解密为明文",
+	Long: `使用当前配置的主密钥将所有加密列（例如 token 值）解密为明文。
+
+用于停用静态数据加密，或回滚加密相关的迁移（存在加密数据时 db rollback 会拒绝执行）。
+完成后请移除 db.encryption.key_file 配置和环境变量 YOUDU_DB_ENCRYPTION_KEY，否则新写入的数据仍会加密。
+
+执行前请先备份数据库，并停止 serve-api 和 youdu-mcp 等使用数据库的服务。
+
+示例:
+  youdu-cli db decrypt
+  youdu-cli db rollback --steps 2`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		count, err := db.Decrypt()
+		if err != nil {
+			return fmt.Errorf("解密失败: %w", err)
+		}
+
+		fmt.Printf("✅ 已解密 %d 条记录\n", count)
+		fmt.Printf("\n💡 提示: 请移除 db.encryption.key_file 配置和环境变量 %s\n", database.EncryptionKeyEnv)
+		return nil
+	},
+}
+
+// openDatabase 打开数据库但不自动执行迁移
+func openDatabase() (*database.DB, error) {
+	dbCfg, err := config.LoadDatabaseConfig(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+	dbCfg.DisableAutoMigrate = true
+
+	db, err := database.New(dbCfg)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	return db, nil
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+
+	// db migrate
+	dbCmd.AddCommand(dbMigrateCmd)
+
+	// db status
+	dbCmd.AddCommand(dbStatusCmd)
+	dbStatusCmd.Flags().BoolVar(&dbOutputJSON, "json", false, "以 JSON 格式输出")
+
+	// db rollback
+	dbCmd.AddCommand(dbRollbackCmd)
+	dbRollbackCmd.Flags().IntVar(&dbRollbackSteps, "steps", 1, "回滚的迁移数量")
+
+	// db backup
+	dbCmd.AddCommand(dbBackupCmd)
+
+	// db restore
+	dbCmd.AddCommand(dbRestoreCmd)
+
+	// db check
+	dbCmd.AddCommand(dbCheckCmd)
+	dbCheckCmd.Flags().BoolVar(&dbCheckFix, "fix", false, "删除孤立记录")
+	dbCheckCmd.Flags().BoolVar(&dbOutputJSON, "json", false, "以 JSON 格式输出")
+
+	// db rekey
+	dbCmd.AddCommand(dbRekeyCmd)
+	dbRekeyCmd.Flags().StringVar(&dbNewKeyFile, "new-key-file", "", "新的主密钥文件（base64 编码的 32 字节密钥）")
+
+	// db decrypt
+	dbCmd.AddCommand(dbDecryptCmd)
+}
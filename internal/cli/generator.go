@@ -10,6 +10,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
 )
 
 // generateCommands uses reflection to generate CLI commands from adapter methods
@@ -94,6 +96,9 @@ func generateCommands() error {
 	rootCmd.AddCommand(groupCmd)
 	rootCmd.AddCommand(sessionCmd)
 
+	// Show descriptions in the configured language
+	localizeHelp()
+
 	return nil
 }
 
@@ -102,16 +107,12 @@ func generateCommand(methodName string, inputType, outputType reflect.Type) *cob
 	// Convert method name to kebab-case for command name
 	cmdName := toKebabCase(methodName)
 
-	// Generate description
-	description := generateDescription(methodName)
-
 	// Create flags map to store input values
 	inputValues := make(map[string]interface{})
 
 	cmd := &cobra.Command{
-		Use:   cmdName,
-		Short: description,
-		Long:  fmt.Sprintf("%s\n\nThis command calls the %s method.", description, methodName),
+		Use:         cmdName,
+		Annotations: map[string]string{methodAnnotation: methodName},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Create input struct
 			input := reflect.New(inputType).Interface()
@@ -121,6 +122,11 @@ func generateCommand(methodName string, inputType, outputType reflect.Type) *cob
 				return fmt.Errorf("failed to populate input: %w", err)
 			}
 
+			// Validate enum values and ranges declared in the schema
+			if err := schema.Validate(input); err != nil {
+				return err
+			}
+
 			// Call the adapter method
 			adapterValue := reflect.ValueOf(youduAdapter)
 			method := adapterValue.MethodByName(methodName)
@@ -152,6 +158,7 @@ func generateCommand(methodName string, inputType, outputType reflect.Type) *cob
 			return nil
 		},
 	}
+	setCommandDescription(cmd, methodName, config.LanguageZH)
 
 	// Add flags for each input field
 	addFlagsForStruct(cmd, inputType, inputValues)
@@ -161,46 +168,26 @@ func generateCommand(methodName string, inputType, outputType reflect.Type) *cob
 
 // addFlagsForStruct adds flags for each field in a struct
 func addFlagsForStruct(cmd *cobra.Command, structType reflect.Type, inputValues map[string]interface{}) {
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-
-		// Get JSON tag for flag name
-		jsonTag := field.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
-			continue
-		}
-
-		// Parse JSON tag (handle omitempty, etc.)
-		flagName := strings.Split(jsonTag, ",")[0]
+	for _, field := range schema.Fields(structType) {
+		flagName := field.Name
+		description := flagUsage(field)
 
-		// Get jsonschema tag for description
-		description := field.Tag.Get("jsonschema")
-		if description == "" {
-			description = fmt.Sprintf("%s field", field.Name)
-		} else {
-			// Extract description from jsonschema tag
-			if idx := strings.Index(description, "description="); idx != -1 {
-				desc := description[idx+12:]
-				if endIdx := strings.Index(desc, ","); endIdx != -1 {
-					desc = desc[:endIdx]
-				}
-				description = desc
-			}
-		}
-
-		// Add flag based on field type
+		// Add flag based on field type, using the schema default as flag default
 		switch field.Type.Kind() {
 		case reflect.String:
 			var val string
-			cmd.Flags().StringVar(&val, flagName, "", description)
+			def, _ := field.Schema.Default.(string)
+			cmd.Flags().StringVar(&val, flagName, def, description)
 			inputValues[flagName] = &val
 		case reflect.Int:
 			var val int
-			cmd.Flags().IntVar(&val, flagName, 0, description)
+			def, _ := field.Schema.Default.(int64)
+			cmd.Flags().IntVar(&val, flagName, int(def), description)
 			inputValues[flagName] = &val
 		case reflect.Bool:
 			var val bool
-			cmd.Flags().BoolVar(&val, flagName, false, description)
+			def, _ := field.Schema.Default.(bool)
+			cmd.Flags().BoolVar(&val, flagName, def, description)
 			inputValues[flagName] = &val
 		case reflect.Slice:
 			if field.Type.Elem().Kind() == reflect.String {
@@ -212,6 +199,40 @@ func addFlagsForStruct(cmd *cobra.Command, structType reflect.Type, inputValues
 	}
 }
 
+// flagUsage builds the flag help text from the field schema
+func flagUsage(field schema.Field) string {
+	description := field.Schema.Description
+	if description == "" {
+		description = fmt.Sprintf("%s field", field.Name)
+	}
+
+	enum := field.Schema.Enum
+	if field.Schema.Items != nil {
+		enum = field.Schema.Items.Enum
+	}
+	if len(enum) > 0 {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			values[i] = fmt.Sprint(v)
+		}
+		description += fmt.Sprintf(" (one of: %s)", strings.Join(values, ", "))
+	}
+	if field.Schema.Minimum != nil || field.Schema.Maximum != nil {
+		description += " (range:"
+		if field.Schema.Minimum != nil {
+			description += fmt.Sprintf(" min %v", *field.Schema.Minimum)
+		}
+		if field.Schema.Maximum != nil {
+			description += fmt.Sprintf(" max %v", *field.Schema.Maximum)
+		}
+		description += ")"
+	}
+	if field.Required {
+		description += " (required)"
+	}
+	return description
+}
+
 // populateInputFromFlags populates the input struct from flag values
 func populateInputFromFlags(cmd *cobra.Command, inputValues map[string]interface{}, input interface{}) error {
 	inputVal := reflect.ValueOf(input).Elem()
@@ -250,19 +271,33 @@ func toKebabCase(s string) string {
 	return result.String()
 }
 
-// generateDescription generates a human-readable description from a method name
-func generateDescription(methodName string) string {
-	var words []string
-	var currentWord strings.Builder
+// methodAnnotation stores the adapter method name on generated commands
+const methodAnnotation = "adapter_method"
 
-	for i, r := range methodName {
-		if i > 0 && unicode.IsUpper(r) {
-			words = append(words, currentWord.String())
-			currentWord.Reset()
-		}
-		currentWord.WriteRune(unicode.ToLower(r))
-	}
-	words = append(words, currentWord.String())
+// setCommandDescription sets the help text of a generated command from the description registry
+func setCommandDescription(cmd *cobra.Command, methodName, language string) {
+	description := adapter.Describe(methodName, language)
+	cmd.Short = description.Summary
+	cmd.Long = fmt.Sprintf("%s\n\nThis command calls the %s method.", description.Text(), methodName)
+}
 
-	return strings.Join(words, " ")
+// localizeHelp re-renders the descriptions of generated commands before help is
+// shown. Commands are generated before the config file is known, so the
+// language is read when help is requested.
+func localizeHelp() {
+	defaultHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		setCommandDescriptions(rootCmd, config.LoadLanguage(cfgFile))
+		defaultHelp(cmd, args)
+	})
+}
+
+// setCommandDescriptions updates the descriptions of all generated commands under cmd
+func setCommandDescriptions(cmd *cobra.Command, language string) {
+	if methodName, ok := cmd.Annotations[methodAnnotation]; ok {
+		setCommandDescription(cmd, methodName, language)
+	}
+	for _, child := range cmd.Commands() {
+		setCommandDescriptions(child, language)
+	}
 }
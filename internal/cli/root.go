@@ -27,6 +27,14 @@ var rootCmd = &cobra.Command{
 		if cmd.Name() == "token" {
 			return nil
 		}
+
+		// 跳过 db 命令（它只需要数据库配置）
+		if cmd.Parent() != nil && cmd.Parent().Name() == "db" {
+			return nil
+		}
+		if cmd.Name() == "db" {
+			return nil
+		}
 		
 		// 跳过 serve-api 命令（它会自己加载和验证配置）
 		if cmd.Name() == "serve-api" {
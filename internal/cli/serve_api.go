@@ -1,15 +1,24 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/youdu-app-mcp/internal/api"
 	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/mcp"
 )
 
 var (
-	apiPort string
+	apiPort        string
+	apiTLSCert     string
+	apiTLSKey      string
+	apiTLSClientCA string
+	apiMCP         bool
 )
 
 // serveAPICmd represents the serve-api command
@@ -24,11 +33,15 @@ var serveAPICmd = &cobra.Command{
   youdu-cli serve-api
   youdu-cli serve-api --port 8080
   youdu-cli serve-api --config config.yaml --port 9000
+  youdu-cli serve-api --tls-cert server.crt --tls-key server.key
+  youdu-cli serve-api --tls-cert server.crt --tls-key server.key --tls-client-ca ca.crt
+  youdu-cli serve-api --mcp
 
 服务启动后可以访问:
   - GET /health - 健康检查
   - GET /api/v1/endpoints - 查看所有可用 API
-  - POST /api/v1/* - 调用各种业务 API`,
+  - POST /api/v1/* - 调用各种业务 API
+  - /mcp、/sse - MCP Streamable HTTP / SSE（使用 --mcp 启用）`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 加载配置
 		var cfg *config.Config
@@ -42,6 +55,17 @@ var serveAPICmd = &cobra.Command{
 			return fmt.Errorf("加载配置失败: %w", err)
 		}
 
+		// 命令行参数覆盖 TLS 配置
+		if apiTLSCert != "" {
+			cfg.TLS.CertFile = apiTLSCert
+		}
+		if apiTLSKey != "" {
+			cfg.TLS.KeyFile = apiTLSKey
+		}
+		if apiTLSClientCA != "" {
+			cfg.TLS.ClientCAFile = apiTLSClientCA
+		}
+
 		// 验证配置
 		if err := cfg.Validate(); err != nil {
 			return fmt.Errorf("配置无效: %w\n提示：请检查 config.yaml 文件或设置环境变量", err)
@@ -54,6 +78,45 @@ var serveAPICmd = &cobra.Command{
 		}
 		defer server.Close()
 
+		// 同时提供 MCP HTTP endpoint
+		if apiMCP {
+			mcpServer, err := mcp.New(cfg)
+			if err != nil {
+				return fmt.Errorf("创建 MCP 服务器失败: %w", err)
+			}
+			server.MountMCP(mcpServer.Handler(), mcp.StreamablePath, mcp.SSEPath)
+			fmt.Printf("🤖 MCP: Streamable HTTP %s，SSE %s\n", mcp.StreamablePath, mcp.SSEPath)
+		}
+
+		// 启动定期备份
+		if db := cfg.GetDatabase(); db != nil && db.GetConfig().Backup.Enabled() {
+			backupCfg := db.GetConfig().Backup
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go db.RunBackups(ctx, backupCfg, func(path string, err error) {
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "❌ 数据库定期备份失败: %v\n", err)
+					return
+				}
+				fmt.Printf("💾 数据库已备份到 %s\n", path)
+			})
+			fmt.Printf("💾 定期备份: 每 %s 备份到 %s（保留 %d 个）\n", backupCfg.Interval, backupCfg.Dir, backupCfg.Keep)
+		}
+
+		// SIGHUP 重新加载权限配置（API 和 MCP 立即使用新策略）
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		defer signal.Stop(reload)
+		go func() {
+			for range reload {
+				if err := cfg.ReloadPermission(); err != nil {
+					fmt.Fprintf(os.Stderr, "❌ 重新加载权限配置失败: %v\n", err)
+					continue
+				}
+				fmt.Println("🔄 权限配置已重新加载")
+			}
+		}()
+
 		// 构建监听地址
 		addr := fmt.Sprintf(":%s", apiPort)
 
@@ -72,4 +135,12 @@ func init() {
 
 	// 添加端口参数
 	serveAPICmd.Flags().StringVarP(&apiPort, "port", "p", "8080", "HTTP API 服务器监听端口")
+
+	// 添加 MCP 参数
+	serveAPICmd.Flags().BoolVar(&apiMCP, "mcp", false, "同时提供 MCP Streamable HTTP (/mcp) 和 SSE (/sse) endpoint")
+
+	// 添加 TLS 参数
+	serveAPICmd.Flags().StringVar(&apiTLSCert, "tls-cert", "", "HTTPS 服务器证书文件（PEM）")
+	serveAPICmd.Flags().StringVar(&apiTLSKey, "tls-key", "", "HTTPS 服务器私钥文件（PEM）")
+	serveAPICmd.Flags().StringVar(&apiTLSClientCA, "tls-client-ca", "", "校验客户端证书的 CA 证书文件（PEM，启用双向 TLS）")
 }
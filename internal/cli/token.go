@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
 )
 
 var (
@@ -16,6 +18,9 @@ var (
 	tokenExpiresIn   string
 	tokenID          string
 	tokenOutputJSON  bool
+	tokenScopes      []string
+	tokenAllowCIDRs  []string
+	tokenAuthMode    string
 )
 
 // tokenCmd represents the token command
@@ -36,7 +41,16 @@ var tokenGenerateCmd = &cobra.Command{
 示例:
   youdu-cli token generate --description "API token for service A"
   youdu-cli token generate --description "Temporary token" --expires-in 24h
-  youdu-cli token generate --description "Test token" --json`,
+  youdu-cli token generate --description "CI token" --scope message:send --scope user:read
+  youdu-cli token generate --description "Notify token" --scope "send_*"
+  youdu-cli token generate --description "Office token" --allow-cidr 10.0.0.0/8 --allow-cidr 192.168.1.10
+  youdu-cli token generate --description "Webhook sender" --auth-mode hmac
+  youdu-cli token generate --description "Test token" --json
+
+Scope 格式:
+  *                 允许访问所有 endpoint（默认）
+  resource:action   例如 user:read、group:*、message:send
+  endpoint glob     例如 send_*、get_user`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 加载配置以获取数据库连接
 		var cfg *config.Config
@@ -64,7 +78,11 @@ var tokenGenerateCmd = &cobra.Command{
 		}
 
 		// 生成 token
-		token, err := cfg.TokenManager.Generate(tokenDescription, expiresIn)
+		token, err := cfg.TokenManager.GenerateWithOptions(tokenDescription, expiresIn, token.GenerateOptions{
+			Scopes:       tokenScopes,
+			AllowedCIDRs: tokenAllowCIDRs,
+			AuthMode:     tokenAuthMode,
+		})
 		if err != nil {
 			return fmt.Errorf("生成 token 失败: %w", err)
 		}
@@ -85,10 +103,17 @@ var tokenGenerateCmd = &cobra.Command{
 			} else {
 				fmt.Printf("  Expires At:  永不过期\n")
 			}
+			fmt.Printf("  Scopes:      %s\n", formatTokenScopes(token.Scopes))
+			fmt.Printf("  Allowed IPs: %s\n", formatTokenCIDRs(token.AllowedCIDRs))
+			fmt.Printf("  Auth Mode:   %s\n", token.AuthMode)
 
 			fmt.Println("\n💡 提示:")
 			fmt.Println("  Token 已保存到数据库中。")
 			fmt.Println("  确保配置文件中 token.enabled 设置为 true 以启用认证。")
+			if token.SignsRequests() {
+				fmt.Println("  该 token 使用签名认证：以 Value 作为 HMAC 密钥签名请求，不要在请求中直接发送 Value。")
+				fmt.Println("  签名方法见 docs/TOKEN_AUTH.md 的「HMAC 签名认证」章节。")
+			}
 		}
 
 		return nil
@@ -120,7 +145,10 @@ var tokenListCmd = &cobra.Command{
 			return fmt.Errorf("加载配置失败: %w", err)
 		}
 
-		tokens := cfg.TokenManager.List()
+		tokens, err := cfg.TokenManager.List()
+		if err != nil {
+			return err
+		}
 
 		if len(tokens) == 0 {
 			fmt.Println("📭 没有配置任何 token")
@@ -135,8 +163,8 @@ var tokenListCmd = &cobra.Command{
 			fmt.Printf("\n📋 Token 列表 (共 %d 个):\n\n", len(tokens))
 
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-			fmt.Fprintln(w, "ID\tDescription\tCreated At\tExpires At\tStatus")
-			fmt.Fprintln(w, "---\t---\t---\t---\t---")
+			fmt.Fprintln(w, "ID\tDescription\tCreated At\tExpires At\tScopes\tAllowed IPs\tStatus")
+			fmt.Fprintln(w, "---\t---\t---\t---\t---\t---\t---")
 
 			for _, token := range tokens {
 				expiresAt := "永不过期"
@@ -149,11 +177,13 @@ var tokenListCmd = &cobra.Command{
 					}
 				}
 
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 					token.ID,
 					token.Description,
 					token.CreatedAt.Format("2006-01-02 15:04:05"),
 					expiresAt,
+					formatTokenScopes(token.Scopes),
+					formatTokenCIDRs(token.AllowedCIDRs),
 					status,
 				)
 			}
@@ -215,6 +245,9 @@ func init() {
 	tokenGenerateCmd.Flags().StringVarP(&tokenDescription, "description", "d", "", "Token 描述")
 	tokenGenerateCmd.Flags().StringVar(&tokenExpiresIn, "expires-in", "", "过期时间 (例如: 24h, 7d, 30d)")
 	tokenGenerateCmd.Flags().BoolVar(&tokenOutputJSON, "json", false, "以 JSON 格式输出")
+	tokenGenerateCmd.Flags().StringSliceVar(&tokenScopes, "scope", nil, "访问范围，可多次指定 (例如: message:send, user:read, send_*)")
+	tokenGenerateCmd.Flags().StringVar(&tokenAuthMode, "auth-mode", token.AuthModeBearer, "认证方式: bearer 或 hmac (签名认证)")
+	tokenGenerateCmd.Flags().StringSliceVar(&tokenAllowCIDRs, "allow-cidr", nil, "来源 IP 允许列表，可多次指定 (例如: 10.0.0.0/8, 192.168.1.10)")
 	tokenGenerateCmd.MarkFlagRequired("description")
 
 	// token list
@@ -226,3 +259,19 @@ func init() {
 	tokenRevokeCmd.Flags().StringVar(&tokenID, "id", "", "要撤销的 token ID")
 	tokenRevokeCmd.MarkFlagRequired("id")
 }
+
+// formatTokenScopes 格式化 token 的访问范围
+func formatTokenScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return "全部"
+	}
+	return strings.Join(scopes, ",")
+}
+
+// formatTokenCIDRs 格式化 token 的来源 IP 允许列表
+func formatTokenCIDRs(cidrs []string) string {
+	if len(cidrs) == 0 {
+		return "不限"
+	}
+	return strings.Join(cidrs, ",")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/yourusername/youdu-app-mcp/internal/database"
@@ -14,6 +15,11 @@ import (
 // Config 保存所有配置（YouDu + Permission + Token + Database）
 type Config struct {
 	Youdu        YouduConfig            `mapstructure:"youdu"`
+	Token        TokenConfig            `mapstructure:"token"`    // Token 认证配置
+	TLS          TLSConfig              `mapstructure:"tls"`      // HTTPS / 双向 TLS 配置（serve-api、youdu-mcp --listen）
+	MCP          MCPConfig              `mapstructure:"mcp"`      // MCP 服务器配置
+	Language     string                 `mapstructure:"language"` // 工具描述语言（MCP 工具、API 列表、CLI 帮助）: zh（默认）或 en
+	Upload       UploadConfig           `mapstructure:"upload"`   // 文件上传限制
 	Permission   *permission.Permission // 权限配置（由 config 包统一加载）
 	TokenManager *token.Manager         // Token 管理器（动态管理）
 	Database     *database.DB           // 数据库连接
@@ -29,27 +35,90 @@ type YouduConfig struct {
 	AesKey string `mapstructure:"aes_key"`
 }
 
+// TokenConfig 保存 token 认证配置
+type TokenConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Required bool          `mapstructure:"required"` // 强制认证：即使没有任何 token 也拒绝未认证请求
+	Lockout  LockoutConfig `mapstructure:"lockout"`  // 认证失败锁定策略
+	HMAC     HMACConfig    `mapstructure:"hmac"`     // 签名认证配置
+}
+
+// HMACConfig 签名认证配置
+type HMACConfig struct {
+	MaxSkew time.Duration `mapstructure:"max_skew"` // 允许的客户端时钟偏差
+}
+
+// LockoutConfig 认证失败锁定配置（按来源 IP 统计）
+type LockoutConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"` // 时间窗口内允许的最大失败次数（0 表示不限制）
+	Window      time.Duration `mapstructure:"window"`       // 失败次数统计的时间窗口
+	Duration    time.Duration `mapstructure:"duration"`     // 锁定时长
+}
+
+// TLSConfig 保存 HTTPS 和客户端证书认证配置
+type TLSConfig struct {
+	CertFile       string                 `mapstructure:"cert_file"`       // 服务器证书文件（PEM）
+	KeyFile        string                 `mapstructure:"key_file"`        // 服务器私钥文件（PEM）
+	ClientCAFile   string                 `mapstructure:"client_ca_file"`  // 用于校验客户端证书的 CA 证书文件（PEM，配置后启用双向 TLS）
+	ClientAuth     string                 `mapstructure:"client_auth"`     // 客户端证书要求: none、verify_if_given（配置 client_ca_file 时的默认值）或 require
+	ClientSubjects []ClientSubjectMapping `mapstructure:"client_subjects"` // 客户端证书 subject 到 token 的映射
+}
+
+// HTTPS 客户端证书要求
+const (
+	ClientAuthNone          = "none"            // 不要求客户端证书
+	ClientAuthVerifyIfGiven = "verify_if_given" // 客户端可以不提供证书；提供时必须通过校验
+	ClientAuthRequire       = "require"         // 客户端必须提供可通过校验的证书
+)
+
+// ClientSubjectMapping 将客户端证书 subject 映射到 token
+// 使用映射证书的请求以对应 token 的身份认证（scope、IP 允许列表等限制同样生效）
+type ClientSubjectMapping struct {
+	Subject string `mapstructure:"subject"`  // 证书 CommonName 或完整 subject（例如 CN=billing,O=Acme）
+	TokenID string `mapstructure:"token_id"` // 对应的 token ID
+}
+
+// Enabled 是否启用 HTTPS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// UploadConfig 文件上传限制
+type UploadConfig struct {
+	MaxSize int64 `mapstructure:"max_size"` // 单个文件大小上限（字节），0 表示不限制
+}
+
+// MCPConfig 保存 MCP 服务器配置
+type MCPConfig struct {
+	ConfirmDestructive bool   `mapstructure:"confirm_destructive"` // 破坏性工具（删除等）需要客户端确认后才执行
+	ForbiddenTools     string `mapstructure:"forbidden_tools"`     // 权限策略不允许的工具: hide（默认，不注册）或 annotate（保留并在描述中标注）
+	ElicitFallback     string `mapstructure:"elicit_fallback"`     // 客户端不支持 elicitation 时删除类工具的处理: deny（默认，拒绝执行）或 allow（直接执行）
+}
+
+// MCP 服务器处理权限策略不允许的工具的方式
+const (
+	ForbiddenToolsHide     = "hide"
+	ForbiddenToolsAnnotate = "annotate"
+)
+
+// 客户端不支持 elicitation 时删除类工具的默认策略
+const (
+	ElicitFallbackAllow = "allow"
+	ElicitFallbackDeny  = "deny"
+)
+
+// 工具描述语言
+const (
+	LanguageZH = "zh"
+	LanguageEN = "en"
+)
+
 // LoadFromFile 从指定文件加载配置
 // configPath 为空时使用默认搜索路径
 func LoadFromFile(configPath string) (*Config, error) {
-	v := newViper()
-
-	// 设置配置文件
-	if configPath != "" {
-		v.SetConfigFile(configPath)
-	} else {
-		v.SetConfigName("config")
-		v.AddConfigPath(".")
-		v.AddConfigPath("$HOME/.youdu")
-		v.AddConfigPath("/etc/youdu")
-	}
-
-	// 优先级 1: 读取配置文件（最高优先级）
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("读取配置失败: %w", err)
-		}
-		// 配置文件未找到；使用环境变量和默认值
+	v, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
 	}
 
 	// 优先级 2 & 3: 环境变量和默认值已在 newViper() 中设置
@@ -86,6 +155,61 @@ func LoadFromFile(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// readConfig 读取配置文件，configPath 为空时使用默认搜索路径
+func readConfig(configPath string) (*viper.Viper, error) {
+	v := newViper()
+
+	// 设置配置文件
+	if configPath != "" {
+		v.SetConfigFile(configPath)
+	} else {
+		v.SetConfigName("config")
+		v.AddConfigPath(".")
+		v.AddConfigPath("$HOME/.youdu")
+		v.AddConfigPath("/etc/youdu")
+	}
+
+	// 优先级 1: 读取配置文件（最高优先级）
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, fmt.Errorf("读取配置失败: %w", err)
+		}
+		// 配置文件未找到；使用环境变量和默认值
+	}
+
+	return v, nil
+}
+
+// LoadDatabaseConfig 只读取数据库配置，不打开数据库连接
+// 供 youdu-cli db 等需要自行控制迁移的命令使用；configPath 为空时使用 YOUDU_CONFIG_FILE 或默认搜索路径
+func LoadDatabaseConfig(configPath string) (database.Config, error) {
+	if configPath == "" {
+		configPath = os.Getenv("YOUDU_CONFIG_FILE")
+	}
+
+	v, err := readConfig(configPath)
+	if err != nil {
+		return database.Config{}, err
+	}
+
+	return databaseConfig(v), nil
+}
+
+// LoadLanguage 只读取描述语言，不打开数据库连接
+// 供 youdu-cli 显示帮助时使用；configPath 为空时使用 YOUDU_CONFIG_FILE 或默认搜索路径，读取失败时返回 zh
+func LoadLanguage(configPath string) string {
+	if configPath == "" {
+		configPath = os.Getenv("YOUDU_CONFIG_FILE")
+	}
+
+	v, err := readConfig(configPath)
+	if err != nil {
+		return LanguageZH
+	}
+
+	return v.GetString("language")
+}
+
 // Load 使用默认路径加载配置（向后兼容）
 // 支持通过环境变量 YOUDU_CONFIG_FILE 指定配置文件路径
 func Load() (*Config, error) {
@@ -97,8 +221,8 @@ func Load() (*Config, error) {
 func loadPermission(v *viper.Viper) (*permission.Permission, error) {
 	// 定义临时结构体用于解析配置文件
 	type PermConfig struct {
-		Enabled   bool                                      `mapstructure:"enabled"`
-		AllowAll  bool                                      `mapstructure:"allow_all"`
+		Enabled   bool                                 `mapstructure:"enabled"`
+		AllowAll  bool                                 `mapstructure:"allow_all"`
 		Resources map[string]permission.ResourcePolicy `mapstructure:"resources"`
 	}
 
@@ -164,6 +288,31 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("permission.enabled")
 	v.BindEnv("permission.allow_all")
 
+	// Token 配置
+	v.BindEnv("token.enabled")
+	v.BindEnv("token.required")
+	v.BindEnv("token.lockout.max_attempts")
+	v.BindEnv("token.lockout.window")
+	v.BindEnv("token.lockout.duration")
+	v.BindEnv("token.hmac.max_skew")
+
+	// TLS 配置
+	v.BindEnv("tls.cert_file")
+	v.BindEnv("tls.key_file")
+	v.BindEnv("tls.client_ca_file")
+	v.BindEnv("tls.client_auth")
+
+	// 描述语言
+	v.BindEnv("language")
+
+	// 文件上传限制
+	v.BindEnv("upload.max_size")
+
+	// MCP 配置
+	v.BindEnv("mcp.confirm_destructive")
+	v.BindEnv("mcp.forbidden_tools")
+	v.BindEnv("mcp.elicit_fallback")
+
 	// 资源权限
 	resources := []string{"user", "dept", "group", "session", "message"}
 	actions := []string{"create", "read", "update", "delete"}
@@ -182,6 +331,45 @@ func setDefaults(v *viper.Viper) {
 	// 权限默认值
 	v.SetDefault("permission.enabled", false)
 	v.SetDefault("permission.allow_all", true)
+
+	// Token 认证失败锁定默认值
+	v.SetDefault("token.lockout.max_attempts", 5)
+	v.SetDefault("token.lockout.window", "5m")
+	v.SetDefault("token.lockout.duration", "15m")
+	v.SetDefault("token.hmac.max_skew", "5m")
+
+	// 描述语言默认值
+	v.SetDefault("language", LanguageZH)
+
+	// MCP 默认值
+	v.SetDefault("mcp.forbidden_tools", ForbiddenToolsHide)
+	v.SetDefault("mcp.elicit_fallback", ElicitFallbackDeny)
+}
+
+// ReloadPermission 重新读取配置文件中的权限配置并替换当前策略
+// 持有 Permission 的组件（adapter、API、MCP 服务器）通过 Permission.OnChange 感知变更
+func (c *Config) ReloadPermission() error {
+	if c.viper == nil {
+		return fmt.Errorf("配置未从文件加载，无法重新加载权限")
+	}
+
+	if err := c.viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return fmt.Errorf("读取配置失败: %w", err)
+		}
+	}
+
+	perm, err := loadPermission(c.viper)
+	if err != nil {
+		return fmt.Errorf("加载权限配置失败: %w", err)
+	}
+
+	if c.Permission == nil {
+		c.Permission = perm
+		return nil
+	}
+	c.Permission.Replace(perm)
+	return nil
 }
 
 // GetPermission 获取权限配置
@@ -201,37 +389,40 @@ func (c *Config) GetDatabase() *database.DB {
 
 // loadTokens 从 viper 加载 token 配置（内部函数）
 func loadTokens(v *viper.Viper, db *database.DB) (*token.Manager, error) {
-	// 定义 token 配置结构
-	type TokenConfig struct {
-		Enabled bool `mapstructure:"enabled"`
-	}
-
 	var tokenCfg TokenConfig
 
-	// 从配置中读取 token
+	// 从配置中读取 token（读取失败时按未启用处理）
 	if err := v.UnmarshalKey("token", &tokenCfg); err != nil {
-		// 如果没有 token 配置，返回空的 token 管理器
-		return token.NewManager(nil), nil
-	}
-
-	// 如果未启用 token 验证，返回空的 token 管理器
-	if !tokenCfg.Enabled {
-		return token.NewManager(nil), nil
+		tokenCfg = TokenConfig{}
 	}
 
-	// 创建带数据库的 token 管理器
-	var dbConn *database.DB
+	// 有数据库时始终使用数据库存储，未启用认证时仍可以管理 token
+	// token 值按数据库的加密配置加密存储
+	var mgr *token.Manager
 	if db != nil {
-		dbConn = db
+		mgr = token.NewManagerWithStore(token.NewEncryptedSQLStore(db.GetConnection(), db))
+	} else {
+		mgr = token.NewManager(nil)
 	}
-
-	mgr := token.NewManager(dbConn.GetConnection())
+	mgr.SetEnabled(tokenCfg.Enabled)
+	mgr.SetRequired(tokenCfg.Required)
 
 	return mgr, nil
 }
 
 // loadDatabase 从 viper 加载数据库配置（内部函数）
 func loadDatabase(v *viper.Viper) (*database.DB, error) {
+	// 创建数据库连接
+	db, err := database.New(databaseConfig(v))
+	if err != nil {
+		return nil, fmt.Errorf("创建数据库连接失败: %w", err)
+	}
+
+	return db, nil
+}
+
+// databaseConfig 从 viper 读取数据库配置（内部函数）
+func databaseConfig(v *viper.Viper) database.Config {
 	var dbCfg database.Config
 
 	// 从配置中读取数据库配置
@@ -242,13 +433,7 @@ func loadDatabase(v *viper.Viper) (*database.DB, error) {
 		}
 	}
 
-	// 创建数据库连接
-	db, err := database.New(dbCfg)
-	if err != nil {
-		return nil, fmt.Errorf("创建数据库连接失败: %w", err)
-	}
-
-	return db, nil
+	return dbCfg
 }
 
 // Validate 检查配置是否有效
@@ -265,5 +450,32 @@ func (c *Config) Validate() error {
 	if c.Youdu.AesKey == "" {
 		return fmt.Errorf("youdu.aes_key 为必填项")
 	}
+	switch c.MCP.ForbiddenTools {
+	case "", ForbiddenToolsHide, ForbiddenToolsAnnotate:
+	default:
+		return fmt.Errorf("mcp.forbidden_tools 必须是 %s 或 %s", ForbiddenToolsHide, ForbiddenToolsAnnotate)
+	}
+	if c.Upload.MaxSize < 0 {
+		return fmt.Errorf("upload.max_size 不能为负数")
+	}
+	switch c.Language {
+	case "", LanguageZH, LanguageEN:
+	default:
+		return fmt.Errorf("language 必须是 %s 或 %s", LanguageZH, LanguageEN)
+	}
+	switch c.MCP.ElicitFallback {
+	case "", ElicitFallbackAllow, ElicitFallbackDeny:
+	default:
+		return fmt.Errorf("mcp.elicit_fallback 必须是 %s 或 %s", ElicitFallbackAllow, ElicitFallbackDeny)
+	}
+	switch c.TLS.ClientAuth {
+	case "", ClientAuthNone:
+	case ClientAuthVerifyIfGiven, ClientAuthRequire:
+		if c.TLS.ClientCAFile == "" {
+			return fmt.Errorf("tls.client_auth 为 %s 时必须配置 tls.client_ca_file", c.TLS.ClientAuth)
+		}
+	default:
+		return fmt.Errorf("tls.client_auth 必须是 %s、%s 或 %s", ClientAuthNone, ClientAuthVerifyIfGiven, ClientAuthRequire)
+	}
 	return nil
 }
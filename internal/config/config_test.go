@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestValidate_TLSClientAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr bool
+	}{
+		{name: "未配置", tls: TLSConfig{}},
+		{name: "none", tls: TLSConfig{ClientAuth: ClientAuthNone}},
+		{name: "verify_if_given 配置了 CA", tls: TLSConfig{ClientCAFile: "ca.crt", ClientAuth: ClientAuthVerifyIfGiven}},
+		{name: "require 配置了 CA", tls: TLSConfig{ClientCAFile: "ca.crt", ClientAuth: ClientAuthRequire}},
+		{name: "verify_if_given 缺少 CA", tls: TLSConfig{ClientAuth: ClientAuthVerifyIfGiven}, wantErr: true},
+		{name: "require 缺少 CA", tls: TLSConfig{ClientAuth: ClientAuthRequire}, wantErr: true},
+		{name: "未知取值", tls: TLSConfig{ClientCAFile: "ca.crt", ClientAuth: "optional"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Youdu: YouduConfig{Addr: "http://localhost:7080", Buin: 1, AppID: "app", AesKey: "key"},
+				TLS:   tt.tls,
+			}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
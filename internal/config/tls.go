@@ -0,0 +1,47 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// Build 加载证书并创建服务器的 TLS 配置（serve-api 和 youdu-mcp --listen 共用）
+func (c TLSConfig) Build() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载服务器证书失败: %w", err)
+	}
+
+	result := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientCAFile == "" || c.ClientAuth == ClientAuthNone {
+		return result, nil
+	}
+
+	// 启用客户端证书校验
+	caPEM, err := os.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取客户端 CA 证书失败: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("客户端 CA 证书文件 %s 中没有有效的证书", c.ClientCAFile)
+	}
+	result.ClientCAs = pool
+
+	switch c.ClientAuth {
+	case "", ClientAuthVerifyIfGiven:
+		result.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		result.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("无效的 tls.client_auth '%s'：可选值为 %s、%s、%s", c.ClientAuth, ClientAuthNone, ClientAuthVerifyIfGiven, ClientAuthRequire)
+	}
+
+	return result, nil
+}
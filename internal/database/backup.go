@@ -0,0 +1,295 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupFilePrefix 定期备份文件名前缀，备份文件名格式为 youdu-20060102-150405.db
+const backupFilePrefix = "youdu-"
+
+// BackupConfig 定期备份配置
+type BackupConfig struct {
+	Interval time.Duration `mapstructure:"interval" yaml:"interval"` // 备份间隔（0 表示不启用定期备份）
+	Dir      string        `mapstructure:"dir" yaml:"dir"`           // 备份目录
+	Keep     int           `mapstructure:"keep" yaml:"keep"`         // 保留的备份数量（0 表示不清理）
+}
+
+// Enabled 是否启用定期备份
+func (c BackupConfig) Enabled() bool {
+	return c.Interval > 0 && c.Dir != ""
+}
+
+// CheckResult 数据库检查结果
+type CheckResult struct {
+	Integrity []string `json:"integrity"` // PRAGMA integrity_check 发现的问题（为空表示正常）
+	Orphans   []Orphan `json:"orphans"`   // 孤立记录
+}
+
+// Orphan 孤立记录统计
+type Orphan struct {
+	Table       string `json:"table"`
+	Count       int    `json:"count"`
+	Description string `json:"description"`
+}
+
+// OK 检查是否全部通过
+func (r *CheckResult) OK() bool {
+	return len(r.Integrity) == 0 && len(r.Orphans) == 0
+}
+
+// orphanCheck 孤立记录检查项
+type orphanCheck struct {
+	table       string
+	description string
+	where       string // 孤立记录的筛选条件
+}
+
+// orphanChecks 所有孤立记录检查项
+var orphanChecks = []orphanCheck{
+	{
+		table:       "auth_nonces",
+		description: "对应 token 已删除的 nonce 记录",
+		where:       "token_id NOT IN (SELECT id FROM tokens)",
+	},
+}
+
+// Backup 将数据库在线备份到指定文件（VACUUM INTO），目标文件已存在时返回错误
+// 备份在一个读事务中完成，得到一致的快照，期间不阻塞其他读写
+func (db *DB) Backup(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("备份文件 %s 已存在", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	if _, err := db.conn.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("备份数据库失败: %w", err)
+	}
+	return nil
+}
+
+// Restore 使用备份文件替换数据库文件
+// 备份文件需要通过完整性检查；替换前服务应已停止，避免覆盖正在使用的数据库
+func Restore(backupPath, dbPath string) error {
+	// 检查备份文件
+	if _, err := os.Stat(backupPath); err != nil {
+		return fmt.Errorf("备份文件不可用: %w", err)
+	}
+	backup, err := New(Config{Path: backupPath, DisableAutoMigrate: true})
+	if err != nil {
+		return fmt.Errorf("打开备份文件失败: %w", err)
+	}
+	problems, err := backup.integrityCheck()
+	backup.Close()
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("备份文件未通过完整性检查: %s", strings.Join(problems, "; "))
+	}
+
+	// 先复制到临时文件，再原子替换
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return fmt.Errorf("创建数据库目录失败: %w", err)
+	}
+	tmpPath := dbPath + ".restore"
+	if err := copyFile(backupPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("复制备份文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("替换数据库文件失败: %w", err)
+	}
+
+	// 删除旧数据库遗留的日志文件，避免与恢复后的数据库不一致
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		os.Remove(dbPath + suffix)
+	}
+	return nil
+}
+
+// Check 检查数据库完整性和孤立记录
+func (db *DB) Check() (*CheckResult, error) {
+	problems, err := db.integrityCheck()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{Integrity: problems}
+	for _, check := range orphanChecks {
+		exists, err := db.tableExists(check.table)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		var count int
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", check.table, check.where)
+		if err := db.conn.QueryRow(query).Scan(&count); err != nil {
+			return nil, fmt.Errorf("检查表 %s 的孤立记录失败: %w", check.table, err)
+		}
+		if count > 0 {
+			result.Orphans = append(result.Orphans, Orphan{Table: check.table, Count: count, Description: check.description})
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteOrphans 删除孤立记录，返回删除的记录数
+func (db *DB) DeleteOrphans() (int64, error) {
+	var total int64
+	for _, check := range orphanChecks {
+		exists, err := db.tableExists(check.table)
+		if err != nil {
+			return total, err
+		}
+		if !exists {
+			continue
+		}
+
+		result, err := db.conn.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", check.table, check.where))
+		if err != nil {
+			return total, fmt.Errorf("删除表 %s 的孤立记录失败: %w", check.table, err)
+		}
+		n, _ := result.RowsAffected()
+		total += n
+	}
+	return total, nil
+}
+
+// BackupToDir 在目录中创建带时间戳的备份，并按 keep 清理旧备份，返回备份文件路径
+func (db *DB) BackupToDir(dir string, keep int) (string, error) {
+	path := filepath.Join(dir, backupFilePrefix+time.Now().Format("20060102-150405")+".db")
+	if err := db.Backup(path); err != nil {
+		return "", err
+	}
+
+	if keep > 0 {
+		if err := pruneBackups(dir, keep); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// RunBackups 按配置定期备份数据库，直到 ctx 取消
+// 每次备份完成后调用 report（err 不为 nil 表示备份失败）
+func (db *DB) RunBackups(ctx context.Context, cfg BackupConfig, report func(path string, err error)) {
+	if !cfg.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			path, err := db.BackupToDir(cfg.Dir, cfg.Keep)
+			if report != nil {
+				report(path, err)
+			}
+		}
+	}
+}
+
+// pruneBackups 只保留目录中最新的 keep 个定期备份文件
+func pruneBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, backupFilePrefix) && strings.HasSuffix(name, ".db") {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= keep {
+		return nil
+	}
+
+	// 文件名中的时间戳按字典序即按时间排序
+	sort.Strings(backups)
+	for _, name := range backups[:len(backups)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("删除旧备份 %s 失败: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// integrityCheck 执行 PRAGMA integrity_check，返回发现的问题
+func (db *DB) integrityCheck() ([]string, error) {
+	rows, err := db.conn.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return nil, fmt.Errorf("完整性检查失败: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("读取完整性检查结果失败: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// tableExists 检查表是否存在
+func (db *DB) tableExists(table string) (bool, error) {
+	var name string
+	err := db.conn.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&name)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("查询表 %s 失败: %w", table, err)
+	}
+	return true, nil
+}
+
+// copyFile 复制文件并同步到磁盘
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	backupPath := filepath.Join(dir, "backups", "backup.db")
+
+	db := newTestDB(t, dbPath, true)
+	if _, err := db.conn.Exec(`INSERT INTO tokens (id, value, description, created_at) VALUES ('1', 'v1', 'before backup', '2025-01-01 00:00:00')`); err != nil {
+		t.Fatalf("插入数据失败: %v", err)
+	}
+
+	if err := db.Backup(backupPath); err != nil {
+		t.Fatalf("备份失败: %v", err)
+	}
+	if err := db.Backup(backupPath); err == nil {
+		t.Error("期望备份文件已存在时返回错误")
+	}
+
+	// 备份之后的修改在恢复后丢失
+	if _, err := db.conn.Exec(`DELETE FROM tokens`); err != nil {
+		t.Fatalf("删除数据失败: %v", err)
+	}
+	db.Close()
+
+	if err := Restore(backupPath, dbPath); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+
+	restored := newTestDB(t, dbPath, true)
+	var description string
+	if err := restored.conn.QueryRow(`SELECT description FROM tokens WHERE id = '1'`).Scan(&description); err != nil {
+		t.Fatalf("查询恢复后的数据失败: %v", err)
+	}
+	if description != "before backup" {
+		t.Errorf("期望恢复备份时的数据，实际 %s", description)
+	}
+}
+
+func TestRestore_InvalidBackup(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	newTestDB(t, dbPath, true)
+
+	if err := Restore(filepath.Join(dir, "missing.db"), dbPath); err == nil {
+		t.Error("期望备份文件不存在时返回错误")
+	}
+
+	invalid := filepath.Join(dir, "invalid.db")
+	if err := os.WriteFile(invalid, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+	if err := Restore(invalid, dbPath); err == nil {
+		t.Error("期望备份文件无效时返回错误")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), true)
+
+	result, err := db.Check()
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if !result.OK() {
+		t.Fatalf("期望新数据库检查通过，实际 %+v", result)
+	}
+
+	// token 已删除但 nonce 记录仍存在
+	if _, err := db.conn.Exec(`INSERT INTO auth_nonces (token_id, nonce, created_at) VALUES ('deleted', 'n1', 0)`); err != nil {
+		t.Fatalf("插入 nonce 失败: %v", err)
+	}
+
+	result, err = db.Check()
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if len(result.Orphans) != 1 || result.Orphans[0].Table != "auth_nonces" || result.Orphans[0].Count != 1 {
+		t.Fatalf("期望发现 1 条 auth_nonces 孤立记录，实际 %+v", result.Orphans)
+	}
+
+	deleted, err := db.DeleteOrphans()
+	if err != nil {
+		t.Fatalf("删除孤立记录失败: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("期望删除 1 条记录，实际 %d", deleted)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"youdu-20250101-000000.db",
+		"youdu-20250102-000000.db",
+		"youdu-20250103-000000.db",
+		"manual.db",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("写入文件失败: %v", err)
+		}
+	}
+
+	if err := pruneBackups(dir, 2); err != nil {
+		t.Fatalf("清理备份失败: %v", err)
+	}
+
+	for name, wantExists := range map[string]bool{
+		"youdu-20250101-000000.db": false,
+		"youdu-20250102-000000.db": true,
+		"youdu-20250103-000000.db": true,
+		"manual.db":                true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("%s: 期望存在=%v", name, wantExists)
+		}
+	}
+}
+
+func TestRunBackups(t *testing.T) {
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), true)
+	backupDir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan string, 1)
+	go db.RunBackups(ctx, BackupConfig{Interval: 10 * time.Millisecond, Dir: backupDir, Keep: 1}, func(path string, err error) {
+		// 只检查第一次备份（同一秒内的备份文件名相同）
+		cancel()
+		select {
+		case done <- path:
+			if err != nil {
+				t.Errorf("定期备份失败: %v", err)
+			}
+		default:
+		}
+	})
+
+	select {
+	case path := <-done:
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("期望备份文件存在: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待定期备份超时")
+	}
+}
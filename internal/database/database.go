@@ -11,13 +11,18 @@ import (
 
 // DB 数据库连接封装
 type DB struct {
-	conn *sql.DB
-	path string
+	conn   *sql.DB
+	path   string
+	config Config
+	keys   keyring // 加密密钥
 }
 
 // Config 数据库配置
 type Config struct {
-	Path string `mapstructure:"path" yaml:"path"` // 数据库文件路径
+	Path               string           `mapstructure:"path" yaml:"path"`                                 // 数据库文件路径
+	DisableAutoMigrate bool             `mapstructure:"disable_auto_migrate" yaml:"disable_auto_migrate"` // 禁用启动时自动迁移（使用 youdu-cli db migrate 手动执行）
+	Backup             BackupConfig     `mapstructure:"backup" yaml:"backup"`                             // 定期备份配置（serve-api）
+	Encryption         EncryptionConfig `mapstructure:"encryption" yaml:"encryption"`                     // 静态数据加密配置
 }
 
 // New 创建新的数据库连接
@@ -46,38 +51,35 @@ func New(config Config) (*DB, error) {
 	}
 
 	db := &DB{
-		conn: conn,
-		path: config.Path,
+		conn:   conn,
+		path:   config.Path,
+		config: config,
 	}
 
-	// 初始化数据库结构
-	if err := db.initSchema(); err != nil {
+	// 执行数据库迁移
+	if !config.DisableAutoMigrate {
+		if _, err := db.Migrate(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("数据库迁移失败: %w", err)
+		}
+	}
+
+	// 加载加密密钥
+	master, err := LoadMasterKey(config.Encryption)
+	if err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("初始化数据库结构失败: %w", err)
+		return nil, err
+	}
+	if master != nil {
+		if err := db.initEncryption(master); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("初始化数据加密失败: %w", err)
+		}
 	}
 
 	return db, nil
 }
 
-// initSchema 初始化数据库结构
-func (db *DB) initSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS tokens (
-		id TEXT PRIMARY KEY,
-		value TEXT UNIQUE NOT NULL,
-		description TEXT NOT NULL,
-		created_at DATETIME NOT NULL,
-		expires_at DATETIME
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_tokens_value ON tokens(value);
-	CREATE INDEX IF NOT EXISTS idx_tokens_expires_at ON tokens(expires_at);
-	`
-
-	_, err := db.conn.Exec(schema)
-	return err
-}
-
 // Close 关闭数据库连接
 func (db *DB) Close() error {
 	if db.conn != nil {
@@ -91,6 +93,11 @@ func (db *DB) GetConnection() *sql.DB {
 	return db.conn
 }
 
+// GetConfig 获取数据库配置
+func (db *DB) GetConfig() Config {
+	return db.config
+}
+
 // GetPath 获取数据库文件路径
 func (db *DB) GetPath() string {
 	return db.path
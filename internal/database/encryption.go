@@ -0,0 +1,503 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EncryptionKeyEnv 主密钥环境变量（base64 编码的 32 字节密钥，优先于 key_file）
+const EncryptionKeyEnv = "YOUDU_DB_ENCRYPTION_KEY"
+
+// encryptedPrefix 加密值前缀，格式为 enc:v1:<数据密钥 ID>:<base64(nonce|密文)>
+// 没有该前缀的值视为明文（启用加密之前写入的数据）
+const encryptedPrefix = "enc:v1:"
+
+// dataKeyAAD 包装数据密钥时使用的附加认证数据
+const dataKeyAAD = "youdu-data-key"
+
+// EncryptionConfig 静态数据加密配置
+type EncryptionConfig struct {
+	KeyFile string `mapstructure:"key_file" yaml:"key_file"` // 主密钥文件（base64 编码的 32 字节密钥）
+}
+
+// EncryptedColumn 需要加密存储的列
+type EncryptedColumn struct {
+	Table     string // 表名
+	Column    string // 列名
+	KeyColumn string // 主键列名（重新加密时定位记录）
+}
+
+// Name 返回列的完整名称（table.column），同时作为加密的附加认证数据
+func (c EncryptedColumn) Name() string {
+	return c.Table + "." + c.Column
+}
+
+// encryptedColumns 所有加密存储的列
+var encryptedColumns = []EncryptedColumn{
+	{Table: "tokens", Column: "value", KeyColumn: "id"}, // token 值（bearer token / HMAC 密钥）
+}
+
+// ErrStaleKeyring 其他进程更换了主密钥，当前进程持有的主密钥无法解包新的数据密钥，需要使用新的主密钥重启
+var ErrStaleKeyring = errors.New("加密密钥已被轮换，请使用新的主密钥重启服务")
+
+// errUnknownDataKey 密文使用的数据密钥不在当前密钥环中
+var errUnknownDataKey = errors.New("数据密钥不存在")
+
+// errWrongMasterKey 主密钥无法解包数据密钥
+var errWrongMasterKey = errors.New("主密钥不正确")
+
+// keyring 主密钥和已解包的数据密钥
+// 数据使用数据密钥加密，数据密钥使用主密钥包装后保存在 encryption_keys 表中（信封加密）
+type keyring struct {
+	mu       sync.RWMutex
+	master   []byte           // 主密钥（未配置时为 nil）
+	keys     map[int64][]byte // 数据密钥 ID -> 数据密钥
+	activeID int64            // 用于加密新数据的数据密钥 ID
+	stale    bool             // 已报告 ErrStaleKeyring（只输出一次）
+}
+
+// snapshot 返回主密钥、数据密钥和活动数据密钥 ID
+// 密钥环只会整体替换 keys，返回的 map 不会再被修改
+func (k *keyring) snapshot() ([]byte, map[int64][]byte, int64) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.master, k.keys, k.activeID
+}
+
+// LoadMasterKey 从环境变量 YOUDU_DB_ENCRYPTION_KEY 或密钥文件读取主密钥
+// 都未配置时返回 nil
+func LoadMasterKey(cfg EncryptionConfig) ([]byte, error) {
+	if value := os.Getenv(EncryptionKeyEnv); value != "" {
+		return ParseMasterKey(value)
+	}
+	if cfg.KeyFile == "" {
+		return nil, nil
+	}
+	return ReadMasterKeyFile(cfg.KeyFile)
+}
+
+// ReadMasterKeyFile 从文件读取主密钥
+func ReadMasterKeyFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取加密密钥文件失败: %w", err)
+	}
+	return ParseMasterKey(string(content))
+}
+
+// ParseMasterKey 解析 base64 编码的 32 字节主密钥
+func ParseMasterKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("加密密钥不是有效的 base64 编码: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("加密密钥长度应为 32 字节，实际 %d 字节", len(key))
+	}
+	return key, nil
+}
+
+// EncryptionEnabled 是否配置了主密钥
+func (db *DB) EncryptionEnabled() bool {
+	master, _, _ := db.keys.snapshot()
+	return master != nil
+}
+
+// EncryptValue 加密列值，未配置主密钥时原样返回
+// column 为 table.column 格式的列名
+func (db *DB) EncryptValue(column, plaintext string) (string, error) {
+	master, keys, activeID := db.keys.snapshot()
+	if master == nil {
+		return plaintext, nil
+	}
+	return encryptWithKey(activeID, keys[activeID], column, plaintext)
+}
+
+// DecryptValue 解密列值，明文（没有加密前缀的值）原样返回
+// 密文使用的数据密钥不在密钥环中时（其他进程轮换了密钥）重新加载数据密钥
+func (db *DB) DecryptValue(column, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+
+	master, keys, _ := db.keys.snapshot()
+	if master == nil {
+		return "", fmt.Errorf("%s 已加密，但未配置加密密钥（db.encryption.key_file 或 %s）", column, EncryptionKeyEnv)
+	}
+
+	plaintext, err := decryptWithKeys(keys, column, value)
+	if !errors.Is(err, errUnknownDataKey) {
+		return plaintext, err
+	}
+
+	if err := db.reloadKeys(); err != nil {
+		return "", err
+	}
+	_, keys, _ = db.keys.snapshot()
+	return decryptWithKeys(keys, column, value)
+}
+
+// Rekey 轮换加密密钥：使用 newMaster 包装新的数据密钥，用它重新加密所有加密列
+// newMaster 为 nil 时保留当前主密钥，只轮换数据密钥；首次启用加密时可用于加密已有的明文数据
+// 旧的数据密钥不会删除（更换主密钥时使用新主密钥重新包装），正在运行的其他进程在轮换期间写入的数据仍可解密；
+// 其他进程遇到新的数据密钥时自动重新加载，主密钥已更换时返回 ErrStaleKeyring
+// 返回重新加密的记录数
+func (db *DB) Rekey(newMaster []byte) (int, error) {
+	db.keys.mu.Lock()
+	defer db.keys.mu.Unlock()
+
+	master := db.keys.master
+	if newMaster == nil {
+		if master == nil {
+			return 0, fmt.Errorf("未配置加密密钥，请指定新的主密钥")
+		}
+		newMaster = master
+	}
+
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 已有的数据密钥（包括其他进程创建的），使用新主密钥重新包装
+	keys := make(map[int64][]byte)
+	if master != nil {
+		if keys, _, err = loadDataKeys(ctx, tx, master); err != nil {
+			return 0, err
+		}
+	} else if _, err := tx.ExecContext(ctx, `DELETE FROM encryption_keys`); err != nil {
+		// 未配置主密钥时已有的数据密钥无法解包，也不会有使用它们的密文
+		return 0, fmt.Errorf("删除无法解包的数据密钥失败: %w", err)
+	}
+	for id, key := range keys {
+		if err := rewrapDataKey(ctx, tx, newMaster, id, key); err != nil {
+			return 0, err
+		}
+	}
+
+	// 创建新的数据密钥
+	newID, newKey, err := createDataKey(ctx, tx, newMaster)
+	if err != nil {
+		return 0, err
+	}
+
+	// 重新加密所有加密列
+	total := 0
+	for _, col := range encryptedColumns {
+		n, err := reencryptColumn(ctx, tx, col, keys, func(column, plaintext string) (string, error) {
+			return encryptWithKey(newID, newKey, column, plaintext)
+		})
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交密钥轮换失败: %w", err)
+	}
+
+	newKeys := make(map[int64][]byte, len(keys)+1)
+	for id, key := range keys {
+		newKeys[id] = key
+	}
+	newKeys[newID] = newKey
+
+	db.keys.master = newMaster
+	db.keys.keys = newKeys
+	db.keys.activeID = newID
+	db.keys.stale = false
+	return total, nil
+}
+
+// Decrypt 将所有加密列解密为明文，返回解密的记录数
+// 用于停用加密或回滚到不支持加密的版本，完成后需要移除加密密钥配置，否则新写入的数据仍会加密
+func (db *DB) Decrypt() (int, error) {
+	master, keys, _ := db.keys.snapshot()
+	if master == nil {
+		return 0, fmt.Errorf("未配置加密密钥（db.encryption.key_file 或 %s）", EncryptionKeyEnv)
+	}
+
+	ctx := context.Background()
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	total := 0
+	for _, col := range encryptedColumns {
+		n, err := reencryptColumn(ctx, tx, col, keys, func(_, plaintext string) (string, error) {
+			return plaintext, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交解密失败: %w", err)
+	}
+	return total, nil
+}
+
+// initEncryption 使用主密钥解包已有的数据密钥，没有数据密钥时创建一个
+// 禁用自动迁移且尚未创建 encryption_keys 表时跳过（执行迁移后重新打开数据库即可）
+func (db *DB) initEncryption(master []byte) error {
+	ctx := context.Background()
+
+	exists, err := db.tableExists("encryption_keys")
+	if err != nil || !exists {
+		return err
+	}
+
+	keys, activeID, err := loadDataKeys(ctx, db.conn, master)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		id, key, err := createDataKey(ctx, db.conn, master)
+		if err != nil {
+			return err
+		}
+		keys[id] = key
+		activeID = id
+	}
+
+	db.keys.mu.Lock()
+	defer db.keys.mu.Unlock()
+	db.keys.master = master
+	db.keys.keys = keys
+	db.keys.activeID = activeID
+	return nil
+}
+
+// reloadKeys 重新加载数据密钥（其他进程执行了密钥轮换）
+// 主密钥无法解包新的数据密钥时返回 ErrStaleKeyring，并在标准错误输出提示（只输出一次）
+func (db *DB) reloadKeys() error {
+	db.keys.mu.Lock()
+	defer db.keys.mu.Unlock()
+
+	keys, activeID, err := loadDataKeys(context.Background(), db.conn, db.keys.master)
+	if errors.Is(err, errWrongMasterKey) {
+		err = fmt.Errorf("%w: %v", ErrStaleKeyring, err)
+		if !db.keys.stale {
+			db.keys.stale = true
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	db.keys.keys = keys
+	db.keys.activeID = activeID
+	return nil
+}
+
+// loadDataKeys 使用主密钥解包所有数据密钥，返回数据密钥和最新（用于加密新数据）的数据密钥 ID
+func loadDataKeys(ctx context.Context, tx execer, master []byte) (map[int64][]byte, int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, wrapped_key FROM encryption_keys ORDER BY id`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询数据密钥失败: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[int64][]byte)
+	var activeID int64
+	for rows.Next() {
+		var (
+			id      int64
+			wrapped string
+		)
+		if err := rows.Scan(&id, &wrapped); err != nil {
+			return nil, 0, fmt.Errorf("读取数据密钥失败: %w", err)
+		}
+		key, err := unwrapDataKey(master, wrapped)
+		if err != nil {
+			return nil, 0, fmt.Errorf("解包数据密钥 %d 失败（%w）: %v", id, errWrongMasterKey, err)
+		}
+		keys[id] = key
+		activeID = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return keys, activeID, nil
+}
+
+// createDataKey 生成数据密钥，使用主密钥包装后保存
+func createDataKey(ctx context.Context, tx execer, master []byte) (int64, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return 0, nil, fmt.Errorf("生成数据密钥失败: %w", err)
+	}
+
+	wrapped, err := seal(master, key, []byte(dataKeyAAD))
+	if err != nil {
+		return 0, nil, fmt.Errorf("包装数据密钥失败: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO encryption_keys (wrapped_key, created_at) VALUES (?, ?)`,
+		base64.StdEncoding.EncodeToString(wrapped), time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return 0, nil, fmt.Errorf("保存数据密钥失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, nil, fmt.Errorf("读取数据密钥 ID 失败: %w", err)
+	}
+	return id, key, nil
+}
+
+// rewrapDataKey 使用新的主密钥重新包装数据密钥
+func rewrapDataKey(ctx context.Context, tx execer, master []byte, id int64, key []byte) error {
+	wrapped, err := seal(master, key, []byte(dataKeyAAD))
+	if err != nil {
+		return fmt.Errorf("包装数据密钥失败: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE encryption_keys SET wrapped_key = ? WHERE id = ?`, base64.StdEncoding.EncodeToString(wrapped), id); err != nil {
+		return fmt.Errorf("更新数据密钥 %d 失败: %w", id, err)
+	}
+	return nil
+}
+
+// unwrapDataKey 使用主密钥解包数据密钥
+func unwrapDataKey(master []byte, wrapped string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return open(master, data, []byte(dataKeyAAD))
+}
+
+// reencryptColumn 使用 oldKeys 解密一列后通过 encrypt 重新写入，返回更新的记录数
+func reencryptColumn(ctx context.Context, tx *sql.Tx, col EncryptedColumn, oldKeys map[int64][]byte, encrypt func(column, plaintext string) (string, error)) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s, %s FROM %s", col.KeyColumn, col.Column, col.Table))
+	if err != nil {
+		return 0, fmt.Errorf("读取 %s 失败: %w", col.Name(), err)
+	}
+
+	type record struct {
+		key   any
+		value string
+	}
+	var records []record
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.key, &r.value); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("读取 %s 失败: %w", col.Name(), err)
+		}
+		records = append(records, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, r := range records {
+		plaintext := r.value
+		if strings.HasPrefix(r.value, encryptedPrefix) {
+			if plaintext, err = decryptWithKeys(oldKeys, col.Name(), r.value); err != nil {
+				return 0, err
+			}
+		}
+
+		encrypted, err := encrypt(col.Name(), plaintext)
+		if err != nil {
+			return 0, err
+		}
+		query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", col.Table, col.Column, col.KeyColumn)
+		if _, err := tx.ExecContext(ctx, query, encrypted, r.key); err != nil {
+			return 0, fmt.Errorf("更新 %s 失败: %w", col.Name(), err)
+		}
+	}
+	return len(records), nil
+}
+
+// encryptWithKey 使用数据密钥加密
+func encryptWithKey(id int64, key []byte, column, plaintext string) (string, error) {
+	sealed, err := seal(key, []byte(plaintext), []byte(column))
+	if err != nil {
+		return "", fmt.Errorf("加密 %s 失败: %w", column, err)
+	}
+	return encryptedPrefix + strconv.FormatInt(id, 10) + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptWithKeys 使用对应的数据密钥解密
+func decryptWithKeys(keys map[int64][]byte, column, value string) (string, error) {
+	idStr, payload, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", fmt.Errorf("%s 的加密格式无效", column)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%s 的数据密钥 ID 无效", column)
+	}
+	key, exists := keys[id]
+	if !exists {
+		return "", fmt.Errorf("%w: %s 使用的数据密钥 %d", errUnknownDataKey, column, id)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("%s 的加密数据无效: %w", column, err)
+	}
+	plaintext, err := open(key, data, []byte(column))
+	if err != nil {
+		return "", fmt.Errorf("解密 %s 失败: %w", column, err)
+	}
+	return string(plaintext), nil
+}
+
+// seal 使用 AES-256-GCM 加密，返回 nonce|密文
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open 解密 seal 的结果
+func open(key, data, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("加密数据过短")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], aad)
+}
+
+// newGCM 创建 AES-GCM
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,290 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newMasterKey 生成 base64 编码的测试主密钥
+func newMasterKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestParseMasterKey(t *testing.T) {
+	if _, err := ParseMasterKey(newMasterKey(t) + "\n"); err != nil {
+		t.Errorf("期望有效密钥解析成功: %v", err)
+	}
+	if _, err := ParseMasterKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("期望长度不足的密钥返回错误")
+	}
+	if _, err := ParseMasterKey("not base64!"); err == nil {
+		t.Error("期望非 base64 密钥返回错误")
+	}
+}
+
+func TestEncryptValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	key := newMasterKey(t)
+
+	t.Setenv(EncryptionKeyEnv, key)
+	db := newTestDB(t, path, true)
+	if !db.EncryptionEnabled() {
+		t.Fatal("期望配置密钥后启用加密")
+	}
+
+	encrypted, err := db.EncryptValue("tokens.value", "secret")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if !strings.HasPrefix(encrypted, encryptedPrefix) || strings.Contains(encrypted, "secret") {
+		t.Fatalf("期望得到密文，实际 %s", encrypted)
+	}
+
+	decrypted, err := db.DecryptValue("tokens.value", encrypted)
+	if err != nil || decrypted != "secret" {
+		t.Fatalf("期望解密得到 secret，实际 %q, %v", decrypted, err)
+	}
+
+	// 密文与列绑定，不能挪用到其他列
+	if _, err := db.DecryptValue("tokens.description", encrypted); err == nil {
+		t.Error("期望使用其他列名解密失败")
+	}
+
+	// 明文原样返回
+	if value, err := db.DecryptValue("tokens.value", "plain"); err != nil || value != "plain" {
+		t.Errorf("期望明文原样返回，实际 %q, %v", value, err)
+	}
+	db.Close()
+
+	// 使用错误的主密钥打开数据库
+	t.Setenv(EncryptionKeyEnv, newMasterKey(t))
+	if _, err := New(Config{Path: path}); err == nil {
+		t.Error("期望主密钥错误时打开数据库失败")
+	}
+
+	// 未配置主密钥时无法解密
+	t.Setenv(EncryptionKeyEnv, "")
+	plainDB := newTestDB(t, path, true)
+	if _, err := plainDB.DecryptValue("tokens.value", encrypted); err == nil {
+		t.Error("期望未配置密钥时解密失败")
+	}
+}
+
+func TestRekey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// 启用加密之前写入的明文数据
+	t.Setenv(EncryptionKeyEnv, "")
+	plainDB := newTestDB(t, path, true)
+	if _, err := plainDB.conn.Exec(`INSERT INTO tokens (id, value, description, created_at) VALUES ('1', 'plain-secret', 'legacy', '2025-01-01 00:00:00')`); err != nil {
+		t.Fatalf("插入数据失败: %v", err)
+	}
+	if _, err := plainDB.Rekey(nil); err == nil {
+		t.Error("期望未配置密钥且未指定新密钥时返回错误")
+	}
+	plainDB.Close()
+
+	oldKey := newMasterKey(t)
+	t.Setenv(EncryptionKeyEnv, oldKey)
+	db := newTestDB(t, path, true)
+
+	// 首次轮换加密已有的明文数据
+	count, err := db.Rekey(nil)
+	if err != nil {
+		t.Fatalf("密钥轮换失败: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("期望重新加密 1 条记录，实际 %d", count)
+	}
+
+	readValue := func(db *DB) string {
+		t.Helper()
+		var value string
+		if err := db.conn.QueryRow(`SELECT value FROM tokens WHERE id = '1'`).Scan(&value); err != nil {
+			t.Fatalf("查询数据失败: %v", err)
+		}
+		return value
+	}
+
+	stored := readValue(db)
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		t.Fatalf("期望轮换后数据加密存储，实际 %s", stored)
+	}
+
+	// 更换主密钥
+	newKey, err := ParseMasterKey(newMasterKey(t))
+	if err != nil {
+		t.Fatalf("解析密钥失败: %v", err)
+	}
+	if _, err := db.Rekey(newKey); err != nil {
+		t.Fatalf("更换主密钥失败: %v", err)
+	}
+	db.Close()
+
+	// 旧主密钥不再可用
+	if _, err := New(Config{Path: path}); err == nil {
+		t.Error("期望旧主密钥无法打开数据库")
+	}
+
+	t.Setenv(EncryptionKeyEnv, base64.StdEncoding.EncodeToString(newKey))
+	rekeyed := newTestDB(t, path, true)
+	value, err := rekeyed.DecryptValue("tokens.value", readValue(rekeyed))
+	if err != nil || value != "plain-secret" {
+		t.Errorf("期望使用新主密钥解密得到原始数据，实际 %q, %v", value, err)
+	}
+}
+
+func TestRollback_EncryptedData(t *testing.T) {
+	t.Setenv(EncryptionKeyEnv, newMasterKey(t))
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), true)
+
+	encrypted, err := db.EncryptValue("tokens.value", "secret")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	sum := sha256.Sum256([]byte("secret"))
+	hash := hex.EncodeToString(sum[:])
+	if _, err := db.conn.Exec(`INSERT INTO tokens (id, value, value_hash, description, created_at) VALUES ('1', ?, ?, 'test', '2025-01-01 00:00:00')`, encrypted, hash); err != nil {
+		t.Fatalf("插入数据失败: %v", err)
+	}
+
+	readHash := func() string {
+		t.Helper()
+		var value string
+		if err := db.conn.QueryRow(`SELECT value_hash FROM tokens WHERE id = '1'`).Scan(&value); err != nil {
+			t.Fatalf("查询数据失败: %v", err)
+		}
+		return value
+	}
+
+	// 存在加密数据时拒绝回滚，避免重新迁移时为密文计算 value_hash
+	if _, err := db.Rollback(1); err == nil {
+		t.Fatal("期望存在加密数据时拒绝回滚")
+	}
+	if _, err := db.Rollback(2); err == nil {
+		t.Fatal("期望存在加密数据时拒绝回滚 encryption_keys")
+	}
+	if !tableExists(t, db, "encryption_keys") || readHash() != hash {
+		t.Fatal("期望回滚失败时数据保持不变")
+	}
+
+	// 解密后可以回滚，重新迁移时按明文计算 value_hash
+	count, err := db.Decrypt()
+	if err != nil || count != 1 {
+		t.Fatalf("期望解密 1 条记录，实际 %d, %v", count, err)
+	}
+	if _, err := db.Rollback(2); err != nil {
+		t.Fatalf("解密后回滚失败: %v", err)
+	}
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("重新迁移失败: %v", err)
+	}
+	if got := readHash(); got != hash {
+		t.Errorf("期望 value_hash 为明文的哈希 %s，实际 %s", hash, got)
+	}
+
+	// 回填时遇到密文返回错误
+	if _, err := db.Rollback(1); err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if _, err := db.conn.Exec(`UPDATE tokens SET value = ? WHERE id = '1'`, encrypted); err != nil {
+		t.Fatalf("更新数据失败: %v", err)
+	}
+	if _, err := db.Migrate(); err == nil {
+		t.Error("期望为密文回填 value_hash 时返回错误")
+	}
+}
+
+func TestRekey_OtherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	t.Setenv(EncryptionKeyEnv, newMasterKey(t))
+
+	// 两个连接模拟 db rekey 和正在运行的服务
+	db := newTestDB(t, path, true)
+	server := newTestDB(t, path, true)
+
+	// 服务在轮换前加载的数据密钥加密的数据
+	staleValue, err := server.EncryptValue("tokens.value", "written-before")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if _, err := db.Rekey(nil); err != nil {
+		t.Fatalf("密钥轮换失败: %v", err)
+	}
+
+	// 服务遇到新的数据密钥时重新加载
+	fresh, err := db.EncryptValue("tokens.value", "written-after")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if value, err := server.DecryptValue("tokens.value", fresh); err != nil || value != "written-after" {
+		t.Errorf("期望服务重新加载数据密钥后解密成功，实际 %q, %v", value, err)
+	}
+
+	// 旧的数据密钥保留，服务轮换期间写入的数据仍可解密
+	if value, err := db.DecryptValue("tokens.value", staleValue); err != nil || value != "written-before" {
+		t.Errorf("期望旧数据密钥加密的数据仍可解密，实际 %q, %v", value, err)
+	}
+
+	// 更换主密钥后，持有旧主密钥的服务返回 ErrStaleKeyring
+	newKey, err := ParseMasterKey(newMasterKey(t))
+	if err != nil {
+		t.Fatalf("解析密钥失败: %v", err)
+	}
+	if _, err := db.Rekey(newKey); err != nil {
+		t.Fatalf("更换主密钥失败: %v", err)
+	}
+	rotated, err := db.EncryptValue("tokens.value", "written-rotated")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if _, err := server.DecryptValue("tokens.value", rotated); !errors.Is(err, ErrStaleKeyring) {
+		t.Errorf("期望返回 ErrStaleKeyring，实际 %v", err)
+	}
+	if value, err := db.DecryptValue("tokens.value", staleValue); err != nil || value != "written-before" {
+		t.Errorf("期望更换主密钥后旧数据仍可解密，实际 %q, %v", value, err)
+	}
+}
+
+func TestRekey_Concurrent(t *testing.T) {
+	t.Setenv(EncryptionKeyEnv, newMasterKey(t))
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				encrypted, err := db.EncryptValue("tokens.value", "secret")
+				if err != nil {
+					t.Errorf("加密失败: %v", err)
+					return
+				}
+				if value, err := db.DecryptValue("tokens.value", encrypted); err != nil || value != "secret" {
+					t.Errorf("期望解密得到 secret，实际 %q, %v", value, err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := db.Rekey(nil); err != nil {
+			t.Fatalf("密钥轮换失败: %v", err)
+		}
+	}
+	wg.Wait()
+}
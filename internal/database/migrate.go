@@ -0,0 +1,482 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// migrationLockTimeout 等待其他进程完成迁移的最长时间（毫秒）
+const migrationLockTimeout = 10000
+
+// execer 迁移使用的数据库执行接口（*sql.Conn 实现）
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// migrationFunc 迁移的执行函数
+type migrationFunc func(ctx context.Context, tx execer) error
+
+// Migration 一个带版本号的数据库结构变更
+type Migration struct {
+	Version int
+	Name    string
+	Up      migrationFunc
+	Down    migrationFunc
+}
+
+// MigrationStatus 迁移的执行状态
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // nil 表示尚未执行
+}
+
+// migrations 所有迁移，按版本号递增排列
+// 已发布的迁移不能修改，结构变更只能追加新的迁移
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_tokens",
+		Up: execStatements(`
+			CREATE TABLE IF NOT EXISTS tokens (
+				id TEXT PRIMARY KEY,
+				value TEXT UNIQUE NOT NULL,
+				description TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				expires_at DATETIME
+			);
+			CREATE INDEX IF NOT EXISTS idx_tokens_value ON tokens(value);
+			CREATE INDEX IF NOT EXISTS idx_tokens_expires_at ON tokens(expires_at);
+		`),
+		Down: execStatements(`DROP TABLE IF EXISTS tokens;`),
+	},
+	{
+		Version: 2,
+		Name:    "add_token_scopes",
+		Up:      addColumn("tokens", "scopes", "TEXT NOT NULL DEFAULT ''"),
+		Down:    dropColumn("tokens", "scopes"),
+	},
+	{
+		Version: 3,
+		Name:    "add_token_allowed_cidrs",
+		Up:      addColumn("tokens", "allowed_cidrs", "TEXT NOT NULL DEFAULT ''"),
+		Down:    dropColumn("tokens", "allowed_cidrs"),
+	},
+	{
+		Version: 4,
+		Name:    "add_token_auth_mode",
+		Up:      addColumn("tokens", "auth_mode", "TEXT NOT NULL DEFAULT 'bearer'"),
+		Down:    dropColumn("tokens", "auth_mode"),
+	},
+	{
+		// tokens 表版本号，任何变更都会递增，用于多进程间的 token 缓存失效
+		Version: 5,
+		Name:    "create_token_state",
+		Up: execStatements(`
+			CREATE TABLE IF NOT EXISTS token_state (
+				id INTEGER PRIMARY KEY CHECK (id = 1),
+				version INTEGER NOT NULL
+			);
+			INSERT OR IGNORE INTO token_state (id, version) VALUES (1, 0);
+
+			CREATE TRIGGER IF NOT EXISTS trg_tokens_insert AFTER INSERT ON tokens
+			BEGIN
+				UPDATE token_state SET version = version + 1 WHERE id = 1;
+			END;
+			CREATE TRIGGER IF NOT EXISTS trg_tokens_update AFTER UPDATE ON tokens
+			BEGIN
+				UPDATE token_state SET version = version + 1 WHERE id = 1;
+			END;
+			CREATE TRIGGER IF NOT EXISTS trg_tokens_delete AFTER DELETE ON tokens
+			BEGIN
+				UPDATE token_state SET version = version + 1 WHERE id = 1;
+			END;
+		`),
+		Down: execStatements(`
+			DROP TRIGGER IF EXISTS trg_tokens_insert;
+			DROP TRIGGER IF EXISTS trg_tokens_update;
+			DROP TRIGGER IF EXISTS trg_tokens_delete;
+			DROP TABLE IF EXISTS token_state;
+		`),
+	},
+	{
+		// 签名请求使用过的 nonce（防重放）
+		Version: 6,
+		Name:    "create_auth_nonces",
+		Up: execStatements(`
+			CREATE TABLE IF NOT EXISTS auth_nonces (
+				token_id TEXT NOT NULL,
+				nonce TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				PRIMARY KEY (token_id, nonce)
+			);
+			CREATE INDEX IF NOT EXISTS idx_auth_nonces_created_at ON auth_nonces(created_at);
+		`),
+		Down: execStatements(`DROP TABLE IF EXISTS auth_nonces;`),
+	},
+	{
+		// 信封加密的数据密钥（使用主密钥包装）
+		Version: 7,
+		Name:    "create_encryption_keys",
+		Up: execStatements(`
+			CREATE TABLE IF NOT EXISTS encryption_keys (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				wrapped_key TEXT NOT NULL,
+				created_at DATETIME NOT NULL
+			);
+		`),
+		// 删除数据密钥后密文无法再解密，存在加密数据时拒绝回滚
+		Down: func(ctx context.Context, tx execer) error {
+			if err := refuseEncryptedValues(ctx, tx); err != nil {
+				return err
+			}
+			return execStatements(`DROP TABLE IF EXISTS encryption_keys;`)(ctx, tx)
+		},
+	},
+	{
+		// token 值加密存储后通过哈希查找
+		Version: 8,
+		Name:    "add_token_value_hash",
+		Up: func(ctx context.Context, tx execer) error {
+			if err := addColumn("tokens", "value_hash", "TEXT")(ctx, tx); err != nil {
+				return err
+			}
+			if err := backfillTokenValueHash(ctx, tx); err != nil {
+				return err
+			}
+			return execStatements(`CREATE UNIQUE INDEX IF NOT EXISTS idx_tokens_value_hash ON tokens(value_hash);`)(ctx, tx)
+		},
+		// 旧版本按明文查找 token，重新迁移时也无法为密文计算 value_hash，存在加密数据时拒绝回滚
+		Down: func(ctx context.Context, tx execer) error {
+			if err := refuseEncryptedValues(ctx, tx); err != nil {
+				return err
+			}
+			if err := execStatements(`DROP INDEX IF EXISTS idx_tokens_value_hash;`)(ctx, tx); err != nil {
+				return err
+			}
+			return dropColumn("tokens", "value_hash")(ctx, tx)
+		},
+	},
+}
+
+// LatestVersion 返回最新的迁移版本号
+func LatestVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate 执行所有尚未执行的迁移，返回本次执行的迁移
+// 迁移在一个写事务中执行，多个进程同时启动时只有一个进程执行迁移，其余进程等待后跳过
+func (db *DB) Migrate() ([]Migration, error) {
+	var applied []Migration
+
+	err := db.withMigrationLock(func(ctx context.Context, tx execer) error {
+		versions, err := appliedVersions(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for version := range versions {
+			if version > LatestVersion() {
+				return fmt.Errorf("数据库结构版本 %d 高于当前程序支持的版本 %d，请升级程序", version, LatestVersion())
+			}
+		}
+
+		for _, m := range migrations {
+			if _, done := versions[m.Version]; done {
+				continue
+			}
+			if err := m.Up(ctx, tx); err != nil {
+				return fmt.Errorf("执行迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+				m.Version, m.Name, time.Now().UTC().Format(time.RFC3339),
+			); err != nil {
+				return fmt.Errorf("记录迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// Rollback 按执行顺序倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (db *DB) Rollback(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("回滚步数必须大于 0")
+	}
+
+	var rolledBack []Migration
+
+	err := db.withMigrationLock(func(ctx context.Context, tx execer) error {
+		versions, err := appliedVersions(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			m := migrations[i]
+			if _, done := versions[m.Version]; !done {
+				continue
+			}
+			if m.Down == nil {
+				return fmt.Errorf("迁移 %d_%s 不支持回滚", m.Version, m.Name)
+			}
+			if err := m.Down(ctx, tx); err != nil {
+				return fmt.Errorf("回滚迁移 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+				return fmt.Errorf("删除迁移记录 %d_%s 失败: %w", m.Version, m.Name, err)
+			}
+			rolledBack = append(rolledBack, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rolledBack, nil
+}
+
+// MigrationStatus 返回所有迁移的执行状态
+// 只读：在普通读事务中查询，不获取迁移锁，也不创建 schema_migrations 表（表不存在表示尚未执行任何迁移）
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	ctx := context.Background()
+
+	tx, err := db.conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("开始读事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	versions := map[int]time.Time{}
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&count); err != nil {
+		return nil, fmt.Errorf("查询 schema_migrations 表失败: %w", err)
+	}
+	if count > 0 {
+		if versions, err = appliedVersions(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if appliedAt, done := versions[m.Version]; done {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withMigrationLock 在独占写事务（BEGIN IMMEDIATE）中执行 fn
+// SQLite 同一时间只允许一个写事务，其他进程会等待最多 migrationLockTimeout 毫秒
+func (db *DB) withMigrationLock(fn func(ctx context.Context, tx execer) error) (err error) {
+	ctx := context.Background()
+
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", migrationLockTimeout)); err != nil {
+		return fmt.Errorf("设置数据库等待超时失败: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			conn.ExecContext(ctx, "ROLLBACK")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("创建 schema_migrations 表失败: %w", err)
+	}
+
+	if err := fn(ctx, conn); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return fmt.Errorf("提交迁移失败: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions 查询已执行的迁移版本号及执行时间
+func appliedVersions(ctx context.Context, tx execer) (map[int]time.Time, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt string
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+		}
+		t, _ := time.Parse(time.RFC3339, appliedAt)
+		versions[version] = t
+	}
+	return versions, rows.Err()
+}
+
+// backfillTokenValueHash 为已有 token 计算 value_hash（hex(SHA256(value))，与 token 包一致）
+// 该迁移之前不存在加密数据，value 均为明文；迁移中没有主密钥，遇到密文时返回错误，避免为密文计算哈希
+func backfillTokenValueHash(ctx context.Context, tx execer) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, value FROM tokens WHERE value_hash IS NULL`)
+	if err != nil {
+		return fmt.Errorf("查询 token 失败: %w", err)
+	}
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var id, value string
+		if err := rows.Scan(&id, &value); err != nil {
+			rows.Close()
+			return fmt.Errorf("读取 token 失败: %w", err)
+		}
+		if strings.HasPrefix(value, encryptedPrefix) {
+			rows.Close()
+			return fmt.Errorf("token %s 的值已加密，无法计算 value_hash，请先使用 youdu-cli db decrypt 解密", id)
+		}
+		sum := sha256.Sum256([]byte(value))
+		hashes[id] = hex.EncodeToString(sum[:])
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, hash := range hashes {
+		if _, err := tx.ExecContext(ctx, `UPDATE tokens SET value_hash = ? WHERE id = ?`, hash, id); err != nil {
+			return fmt.Errorf("更新 token %s 的 value_hash 失败: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// refuseEncryptedValues 加密列中存在密文时返回错误
+// 迁移中没有主密钥，无法解密；回滚加密相关的迁移前需要先使用 youdu-cli db decrypt 解密
+func refuseEncryptedValues(ctx context.Context, tx execer) error {
+	for _, col := range encryptedColumns {
+		exists, err := columnExists(ctx, tx, col.Table, col.Column)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s LIKE ?", col.Table, col.Column), encryptedPrefix+"%")
+		if err != nil {
+			return fmt.Errorf("查询 %s 失败: %w", col.Name(), err)
+		}
+		var count int
+		for rows.Next() {
+			if err := rows.Scan(&count); err != nil {
+				rows.Close()
+				return fmt.Errorf("查询 %s 失败: %w", col.Name(), err)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return fmt.Errorf("%s 中有 %d 条加密数据，回滚后将无法读取，请先使用 youdu-cli db decrypt 解密", col.Name(), count)
+		}
+	}
+	return nil
+}
+
+// execStatements 返回执行一组 SQL 语句的迁移函数
+func execStatements(statements string) migrationFunc {
+	return func(ctx context.Context, tx execer) error {
+		_, err := tx.ExecContext(ctx, statements)
+		return err
+	}
+}
+
+// addColumn 返回添加列的迁移函数（列已存在时跳过，兼容引入迁移之前创建的数据库）
+func addColumn(table, column, definition string) migrationFunc {
+	return func(ctx context.Context, tx execer) error {
+		exists, err := columnExists(ctx, tx, table, column)
+		if err != nil || exists {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+		return err
+	}
+}
+
+// dropColumn 返回删除列的迁移函数
+func dropColumn(table, column string) migrationFunc {
+	return func(ctx context.Context, tx execer) error {
+		exists, err := columnExists(ctx, tx, table, column)
+		if err != nil || !exists {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column))
+		return err
+	}
+}
+
+// columnExists 检查表中是否存在指定列
+func columnExists(ctx context.Context, tx execer, table, column string) (bool, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("查询表 %s 结构失败: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("读取表 %s 结构失败: %w", table, err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
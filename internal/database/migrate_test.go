@@ -0,0 +1,207 @@
+package database
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newTestDB 创建测试数据库，autoMigrate 为 false 时不执行迁移
+func newTestDB(t *testing.T, path string, autoMigrate bool) *DB {
+	t.Helper()
+
+	db, err := New(Config{Path: path, DisableAutoMigrate: !autoMigrate})
+	if err != nil {
+		t.Fatalf("创建测试数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// tableExists 检查表是否存在
+func tableExists(t *testing.T, db *DB, table string) bool {
+	t.Helper()
+
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+	if err != nil {
+		t.Fatalf("查询表失败: %v", err)
+	}
+	return count > 0
+}
+
+func TestMigrate(t *testing.T) {
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), false)
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("期望执行 %d 个迁移，实际 %d 个", len(migrations), len(applied))
+	}
+
+	for _, table := range []string{"tokens", "token_state", "auth_nonces", "schema_migrations"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("期望表 %s 存在", table)
+		}
+	}
+
+	// 再次执行不会重复迁移
+	applied, err = db.Migrate()
+	if err != nil {
+		t.Fatalf("重复迁移失败: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("期望没有新迁移，实际执行 %d 个", len(applied))
+	}
+}
+
+func TestRollback(t *testing.T) {
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), true)
+
+	// 回滚到版本 4（token_state 和 auth_nonces 之前）
+	steps := LatestVersion() - 4
+	rolledBack, err := db.Rollback(steps)
+	if err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if len(rolledBack) != steps || rolledBack[0].Version != LatestVersion() {
+		t.Fatalf("期望按倒序回滚最近 %d 个迁移，实际 %+v", steps, rolledBack)
+	}
+	if tableExists(t, db, "auth_nonces") || tableExists(t, db, "token_state") {
+		t.Error("期望回滚后 auth_nonces 和 token_state 表被删除")
+	}
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("查询迁移状态失败: %v", err)
+	}
+	for _, s := range statuses {
+		wantApplied := s.Version <= 4
+		if (s.AppliedAt != nil) != wantApplied {
+			t.Errorf("迁移 %d: 期望已执行=%v", s.Version, wantApplied)
+		}
+	}
+
+	// 回滚后可以重新迁移
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("重新迁移失败: %v", err)
+	}
+	if len(applied) != steps {
+		t.Errorf("期望重新执行 %d 个迁移，实际 %d 个", steps, len(applied))
+	}
+
+	if _, err := db.Rollback(0); err == nil {
+		t.Error("期望回滚步数为 0 时返回错误")
+	}
+}
+
+func TestMigrate_LegacySchema(t *testing.T) {
+	// 模拟引入迁移之前创建的数据库（已有部分新增列，但没有 schema_migrations 表）
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), false)
+	_, err := db.conn.Exec(`
+		CREATE TABLE tokens (
+			id TEXT PRIMARY KEY,
+			value TEXT UNIQUE NOT NULL,
+			description TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME,
+			scopes TEXT NOT NULL DEFAULT ''
+		);
+		INSERT INTO tokens (id, value, description, created_at) VALUES ('1', 'legacy', 'legacy token', '2025-01-01 00:00:00');
+	`)
+	if err != nil {
+		t.Fatalf("创建旧版结构失败: %v", err)
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("迁移旧版数据库失败: %v", err)
+	}
+
+	var authMode string
+	if err := db.conn.QueryRow(`SELECT auth_mode FROM tokens WHERE id = '1'`).Scan(&authMode); err != nil {
+		t.Fatalf("查询迁移后的 token 失败: %v", err)
+	}
+	if authMode != "bearer" {
+		t.Errorf("期望 auth_mode 默认值为 bearer，实际 %s", authMode)
+	}
+}
+
+func TestMigrate_NewerSchema(t *testing.T) {
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), true)
+
+	if _, err := db.conn.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', '2030-01-01T00:00:00Z')`, LatestVersion()+1); err != nil {
+		t.Fatalf("插入迁移记录失败: %v", err)
+	}
+
+	if _, err := db.Migrate(); err == nil {
+		t.Error("期望数据库版本高于程序支持的版本时返回错误")
+	}
+}
+
+func TestMigrate_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// 多个连接同时迁移，每个迁移只执行一次
+	const workers = 4
+	var wg sync.WaitGroup
+	results := make([]int, workers)
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		db := newTestDB(t, path, false)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			applied, err := db.Migrate()
+			results[i], errs[i] = len(applied), err
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("并发迁移失败: %v", errs[i])
+		}
+		total += results[i]
+	}
+	if total != len(migrations) {
+		t.Errorf("期望所有进程合计执行 %d 个迁移，实际 %d 个", len(migrations), total)
+	}
+}
+
+func TestMigrationStatus_ReadOnly(t *testing.T) {
+	db := newTestDB(t, filepath.Join(t.TempDir(), "test.db"), false)
+
+	// 未迁移的数据库：所有迁移均未执行，且不会创建 schema_migrations 表
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("查询迁移状态失败: %v", err)
+	}
+	if len(statuses) != len(migrations) {
+		t.Fatalf("期望 %d 个迁移状态，实际 %d 个", len(migrations), len(statuses))
+	}
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("期望迁移 %d 未执行", status.Version)
+		}
+	}
+	if tableExists(t, db, "schema_migrations") {
+		t.Error("查询迁移状态不应创建 schema_migrations 表")
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	statuses, err = db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("查询迁移状态失败: %v", err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			t.Errorf("期望迁移 %d 已执行", status.Version)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package lockout 按来源 IP 统计认证失败次数并临时锁定，serve-api 和 youdu-mcp --listen 共用
+package lockout
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+)
+
+// maxTrackedIPs 跟踪的 IP 数量超过该值时清理过期记录
+const maxTrackedIPs = 1024
+
+// Throttle 按来源 IP 统计认证失败次数，超过阈值后临时锁定
+type Throttle struct {
+	mu       sync.Mutex
+	attempts map[string]*authAttempts
+	config   config.LockoutConfig
+	now      func() time.Time
+}
+
+// authAttempts 单个 IP 的认证失败记录
+type authAttempts struct {
+	failures    int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// New 创建认证失败限流器
+func New(cfg config.LockoutConfig) *Throttle {
+	return &Throttle{
+		attempts: make(map[string]*authAttempts),
+		config:   cfg,
+		now:      time.Now,
+	}
+}
+
+// enabled 是否启用锁定
+func (t *Throttle) enabled() bool {
+	return t.config.MaxAttempts > 0 && t.config.Duration > 0
+}
+
+// LockedUntil 返回 IP 的锁定截止时间，未锁定时返回 false
+func (t *Throttle) LockedUntil(ip string) (time.Time, bool) {
+	if !t.enabled() {
+		return time.Time{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, exists := t.attempts[ip]
+	if !exists || !t.now().Before(entry.lockedUntil) {
+		return time.Time{}, false
+	}
+	return entry.lockedUntil, true
+}
+
+// RecordFailure 记录一次认证失败，达到阈值时锁定该 IP
+func (t *Throttle) RecordFailure(ip string) {
+	if !t.enabled() {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if len(t.attempts) >= maxTrackedIPs {
+		t.pruneLocked(now)
+	}
+
+	entry, exists := t.attempts[ip]
+	if !exists || (t.config.Window > 0 && now.Sub(entry.windowStart) > t.config.Window) {
+		entry = &authAttempts{windowStart: now}
+		t.attempts[ip] = entry
+	}
+
+	entry.failures++
+	if entry.failures >= t.config.MaxAttempts {
+		entry.lockedUntil = now.Add(t.config.Duration)
+		entry.failures = 0
+		entry.windowStart = now
+	}
+}
+
+// RecordSuccess 认证成功后清除该 IP 的失败记录
+func (t *Throttle) RecordSuccess(ip string) {
+	if !t.enabled() {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.attempts, ip)
+}
+
+// pruneLocked 清理已过期的记录（调用方需持有锁）
+func (t *Throttle) pruneLocked(now time.Time) {
+	for ip, entry := range t.attempts {
+		windowExpired := t.config.Window <= 0 || now.Sub(entry.windowStart) > t.config.Window
+		if windowExpired && !now.Before(entry.lockedUntil) {
+			delete(t.attempts, ip)
+		}
+	}
+}
+
+// ClientIP 返回请求的来源 IP（使用连接地址，不信任 X-Forwarded-For）
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,77 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+)
+
+func TestThrottle_LockoutAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttle := New(config.LockoutConfig{
+		MaxAttempts: 3,
+		Window:      time.Minute,
+		Duration:    10 * time.Minute,
+	})
+	throttle.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		throttle.RecordFailure("10.0.0.1")
+	}
+	if _, locked := throttle.LockedUntil("10.0.0.1"); locked {
+		t.Fatal("未达到阈值时不应该锁定")
+	}
+
+	throttle.RecordFailure("10.0.0.1")
+	if _, locked := throttle.LockedUntil("10.0.0.1"); !locked {
+		t.Fatal("达到阈值后应该锁定")
+	}
+
+	// 其他 IP 不受影响
+	if _, locked := throttle.LockedUntil("10.0.0.2"); locked {
+		t.Error("其他 IP 不应该被锁定")
+	}
+
+	// 锁定时间过后自动解锁
+	now = now.Add(11 * time.Minute)
+	if _, locked := throttle.LockedUntil("10.0.0.1"); locked {
+		t.Error("锁定时间过后应该解锁")
+	}
+}
+
+func TestThrottle_WindowAndSuccessReset(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttle := New(config.LockoutConfig{
+		MaxAttempts: 2,
+		Window:      time.Minute,
+		Duration:    time.Minute,
+	})
+	throttle.now = func() time.Time { return now }
+
+	// 时间窗口外的失败不累计
+	throttle.RecordFailure("10.0.0.1")
+	now = now.Add(2 * time.Minute)
+	throttle.RecordFailure("10.0.0.1")
+	if _, locked := throttle.LockedUntil("10.0.0.1"); locked {
+		t.Error("时间窗口外的失败不应该累计")
+	}
+
+	// 认证成功清除失败记录
+	throttle.RecordSuccess("10.0.0.1")
+	throttle.RecordFailure("10.0.0.1")
+	if _, locked := throttle.LockedUntil("10.0.0.1"); locked {
+		t.Error("认证成功后应该清除失败记录")
+	}
+}
+
+func TestThrottle_Disabled(t *testing.T) {
+	throttle := New(config.LockoutConfig{})
+
+	for i := 0; i < 100; i++ {
+		throttle.RecordFailure("10.0.0.1")
+	}
+	if _, locked := throttle.LockedUntil("10.0.0.1"); locked {
+		t.Error("未配置锁定策略时不应该锁定")
+	}
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+)
+
+// confirmArgument is the input property destructive tools require when
+// confirmation is enabled
+const confirmArgument = "confirm"
+
+// annotationOverrides adjusts hints for methods whose behavior differs from
+// what their resource/action implies
+var annotationOverrides = map[string]func(a *mcp.ToolAnnotations){
+	// Removing members deletes data although the permission action is update
+	"DelGroupMember": func(a *mcp.ToolAnnotations) {
+		a.DestructiveHint = boolPtr(true)
+	},
+	// Capability introspection only reads the local policy
+	"DescribeCapabilities": func(a *mcp.ToolAnnotations) {
+		a.ReadOnlyHint = true
+		a.IdempotentHint = true
+	},
+	// Session messages are checked as session updates but each call sends a new message
+	"SendTextSessionMessage":  notIdempotent,
+	"SendImageSessionMessage": notIdempotent,
+	"SendFileSessionMessage":  notIdempotent,
+}
+
+// toolAnnotations derives the MCP tool hints from the resource/action of the
+// adapter method: reads are read-only, deletes are destructive, and reads,
+// updates and deletes are idempotent.
+func toolAnnotations(methodName string) *mcp.ToolAnnotations {
+	annotations := &mcp.ToolAnnotations{}
+
+	op, _ := adapter.LookupOperation(methodName)
+	switch op.Action {
+	case permission.ActionRead:
+		annotations.ReadOnlyHint = true
+		annotations.IdempotentHint = true
+	case permission.ActionCreate:
+		annotations.DestructiveHint = boolPtr(false)
+	case permission.ActionUpdate:
+		annotations.DestructiveHint = boolPtr(false)
+		annotations.IdempotentHint = true
+	case permission.ActionDelete:
+		annotations.DestructiveHint = boolPtr(true)
+		annotations.IdempotentHint = true
+	}
+
+	if override, ok := annotationOverrides[methodName]; ok {
+		override(annotations)
+	}
+	return annotations
+}
+
+// isDestructive reports whether the annotations mark a tool as destructive
+func isDestructive(annotations *mcp.ToolAnnotations) bool {
+	return annotations != nil && !annotations.ReadOnlyHint &&
+		annotations.DestructiveHint != nil && *annotations.DestructiveHint
+}
+
+// addConfirmArgument adds the required confirm property to a tool's input schema
+func addConfirmArgument(inputSchema *schema.Schema) {
+	if inputSchema.Properties == nil {
+		inputSchema.Properties = map[string]*schema.Schema{}
+	}
+	inputSchema.Properties[confirmArgument] = &schema.Schema{
+		Type:        "boolean",
+		Description: "Set to true only after the user has explicitly confirmed this destructive operation",
+	}
+	inputSchema.Required = append(inputSchema.Required, confirmArgument)
+}
+
+// checkConfirmed returns an error unless the raw tool input sets confirm to true
+func checkConfirmed(toolName string, rawInput json.RawMessage) error {
+	var args struct {
+		Confirm bool `json:"confirm"`
+	}
+	if len(rawInput) > 0 {
+		if err := json.Unmarshal(rawInput, &args); err != nil {
+			return apperr.Wrap(apperr.CodeInvalidArgument, err, "failed to unmarshal input")
+		}
+	}
+	if !args.Confirm {
+		return apperr.New(apperr.CodeConfirmationRequired, "%s is destructive and requires confirmation: ask the user to confirm, then call it again with %q set to true", toolName, confirmArgument)
+	}
+	return nil
+}
+
+func notIdempotent(a *mcp.ToolAnnotations) {
+	a.IdempotentHint = false
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
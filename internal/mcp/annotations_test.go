@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+)
+
+func TestToolAnnotations(t *testing.T) {
+	tests := []struct {
+		method      string
+		readOnly    bool
+		destructive bool
+		idempotent  bool
+	}{
+		{"GetDeptList", true, false, true},
+		{"GetUser", true, false, true},
+		{"CreateUser", false, false, false},
+		{"UpdateUser", false, false, true},
+		{"DeleteUser", false, true, true},
+		{"DeleteGroup", false, true, true},
+		{"AddGroupMember", false, false, true},
+		{"DelGroupMember", false, true, true},
+		{"SendTextMessage", false, false, false},
+		{"SendTextSessionMessage", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			a := toolAnnotations(tt.method)
+			if a.ReadOnlyHint != tt.readOnly {
+				t.Errorf("readOnlyHint = %v, 期望 %v", a.ReadOnlyHint, tt.readOnly)
+			}
+			if isDestructive(a) != tt.destructive {
+				t.Errorf("destructiveHint = %v, 期望 %v", a.DestructiveHint, tt.destructive)
+			}
+			if a.IdempotentHint != tt.idempotent {
+				t.Errorf("idempotentHint = %v, 期望 %v", a.IdempotentHint, tt.idempotent)
+			}
+		})
+	}
+}
+
+func TestToolAnnotations_Listed(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("列出工具失败: %v", err)
+	}
+
+	for _, tool := range result.Tools {
+		if tool.Annotations == nil {
+			t.Errorf("工具 %s 缺少 annotations", tool.Name)
+			continue
+		}
+		if tool.Name == "delete_user" {
+			if _, ok := tool.InputSchema.(map[string]any)["properties"].(map[string]any)[confirmArgument]; ok {
+				t.Error("未开启确认时不应添加 confirm 参数")
+			}
+		}
+	}
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	session := setupInMemorySession(t, func(cfg *config.Config) {
+		cfg.MCP.ConfirmDestructive = true
+		// 策略禁止的工具不会被列出，这里允许删除用户
+		cfg.Permission.SetResourcePolicy(permission.ResourceUser, permission.ResourcePolicy{Read: true, Delete: true})
+	})
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("列出工具失败: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		properties, _ := tool.InputSchema.(map[string]any)["properties"].(map[string]any)
+		_, hasConfirm := properties[confirmArgument]
+		if want := isDestructive(tool.Annotations); hasConfirm != want {
+			t.Errorf("工具 %s confirm 参数 = %v, 期望 %v", tool.Name, hasConfirm, want)
+		}
+	}
+
+	// 未确认时拒绝执行
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "delete_user",
+		Arguments: map[string]any{"user_id": "test_user", "confirm": false},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "requires confirmation") {
+		t.Errorf("期望未确认时返回确认提示，得到 %+v", result.Content)
+	}
+
+	// 确认后继续执行
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "delete_user",
+		Arguments: map[string]any{"user_id": "test_user", "confirm": true},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; strings.Contains(text, "requires confirmation") {
+		t.Errorf("确认后不应再要求确认: %s", text)
+	}
+
+	// 非破坏性工具不受影响
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_dept_list",
+		Arguments: map[string]any{"dept_id": 0},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("期望只读工具直接执行，得到 %+v", result.Content)
+	}
+}
+
+func TestToolDescriptions_Language(t *testing.T) {
+	for _, language := range []string{config.LanguageZH, config.LanguageEN} {
+		t.Run(language, func(t *testing.T) {
+			session := setupInMemorySession(t, func(cfg *config.Config) {
+				cfg.Language = language
+			})
+
+			result, err := session.ListTools(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("列出工具失败: %v", err)
+			}
+			for _, tool := range result.Tools {
+				if tool.Name != "send_sys_message" {
+					continue
+				}
+				want := adapter.Describe("SendSysMessage", language)
+				if tool.Description != want.Text() || tool.Annotations.Title != want.Summary {
+					t.Errorf("描述 = %q，标题 = %q", tool.Description, tool.Annotations.Title)
+				}
+				return
+			}
+			t.Error("未列出 send_sys_message")
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/addcnos/youdu/v2"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+)
+
+// maxResolvedMembers limits how many member names are looked up for a summary
+const maxResolvedMembers = 10
+
+// maxDeptLookups limits how many department lists are fetched to resolve a
+// department name
+const maxDeptLookups = 10
+
+// confirmText is the wording of confirmation questions, selected by config.Language
+type confirmText struct {
+	deleteUser    string // user label
+	deleteDept    string // department label
+	deleteGroup   string // group label
+	removeMembers string // member labels, group label
+	moreMembers   string // number of members not listed
+	separator     string // between member labels
+}
+
+var confirmTexts = map[string]confirmText{
+	config.LanguageZH: {
+		deleteUser:    "确认删除用户 %s？",
+		deleteDept:    "确认删除部门 %s？",
+		deleteGroup:   "确认删除群 %s？",
+		removeMembers: "确认将 %s 移出群 %s？",
+		moreMembers:   "等另外 %d 人",
+		separator:     "、",
+	},
+	config.LanguageEN: {
+		deleteUser:    "Delete user %s?",
+		deleteDept:    "Delete department %s?",
+		deleteGroup:   "Delete group %s?",
+		removeMembers: "Remove %s from group %s?",
+		moreMembers:   "and %d more",
+		separator:     ", ",
+	},
+}
+
+// confirmSummaries builds the question shown to the user before a destructive
+// adapter method runs. Names are resolved from the directory on a best-effort
+// basis: lookups that fail (e.g. not allowed by the policy) fall back to IDs.
+var confirmSummaries = map[string]func(ctx context.Context, a *adapter.Adapter, text confirmText, input any) string{
+	"DeleteUser": func(ctx context.Context, a *adapter.Adapter, text confirmText, input any) string {
+		in := input.(*adapter.DeleteUserInput)
+		return fmt.Sprintf(text.deleteUser, userLabel(ctx, a, in.UserID))
+	},
+	"DeleteDept": func(ctx context.Context, a *adapter.Adapter, text confirmText, input any) string {
+		in := input.(*adapter.DeleteDeptInput)
+		return fmt.Sprintf(text.deleteDept, deptLabel(ctx, a, in.DeptID))
+	},
+	"DeleteGroup": func(ctx context.Context, a *adapter.Adapter, text confirmText, input any) string {
+		in := input.(*adapter.DeleteGroupInput)
+		return fmt.Sprintf(text.deleteGroup, groupLabel(ctx, a, in.GroupID))
+	},
+	"DelGroupMember": func(ctx context.Context, a *adapter.Adapter, text confirmText, input any) string {
+		in := input.(*adapter.DelGroupMemberInput)
+		members := make([]string, 0, len(in.Members))
+		for i, userID := range in.Members {
+			if i >= maxResolvedMembers {
+				members = append(members, fmt.Sprintf(text.moreMembers, len(in.Members)-i))
+				break
+			}
+			members = append(members, userLabel(ctx, a, userID))
+		}
+		return fmt.Sprintf(text.removeMembers, strings.Join(members, text.separator), groupLabel(ctx, a, in.GroupID))
+	},
+}
+
+// confirmText returns the confirmation wording for the configured language
+func (s *Server) confirmText() confirmText {
+	if s.config != nil {
+		if text, ok := confirmTexts[s.config.Language]; ok {
+			return text
+		}
+	}
+	return confirmTexts[config.LanguageZH]
+}
+
+// confirmWithUser asks the user to confirm a destructive call through MCP
+// elicitation. For clients that do not support elicitation, confirmed (the
+// client set the confirm argument under mcp.confirm_destructive) stands in for
+// the answer; otherwise the policy default from mcp.elicit_fallback applies,
+// which denies the call unless set to allow.
+func (s *Server) confirmWithUser(ctx context.Context, req *mcp.CallToolRequest, toolName, message string, confirmed bool) error {
+	if !supportsElicitation(req) {
+		if confirmed {
+			return nil
+		}
+		if s.config == nil || s.config.MCP.ElicitFallback != config.ElicitFallbackAllow {
+			return apperr.New(apperr.CodeConfirmationRequired, "%s requires user confirmation, but the client does not support elicitation", toolName)
+		}
+		return nil
+	}
+
+	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message: message,
+		RequestedSchema: &schema.Schema{
+			Type: "object",
+			Properties: map[string]*schema.Schema{
+				confirmArgument: {
+					Type:        "boolean",
+					Description: "Confirm this operation",
+				},
+			},
+			Required: []string{confirmArgument},
+		},
+	})
+	if err != nil {
+		return apperr.Wrap(apperr.CodeInternal, err, "failed to ask the user for confirmation")
+	}
+	if result.Action != "accept" {
+		return apperr.New(apperr.CodeConfirmationRequired, "%s was not confirmed by the user (%s)", toolName, result.Action)
+	}
+	if confirmed, _ := result.Content[confirmArgument].(bool); !confirmed {
+		return apperr.New(apperr.CodeConfirmationRequired, "%s was not confirmed by the user", toolName)
+	}
+	return nil
+}
+
+// supportsElicitation reports whether the client calling the tool declared
+// the elicitation capability
+func supportsElicitation(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// userLabel formats a user as "ID (name)" when the name can be resolved
+func userLabel(ctx context.Context, a *adapter.Adapter, userID string) string {
+	out, err := a.GetUser(ctx, adapter.GetUserInput{UserID: userID})
+	if err != nil || out.User.Name == "" {
+		return userID
+	}
+	return fmt.Sprintf("%s (%s)", userID, out.User.Name)
+}
+
+// deptLabel formats a department as "ID (name)" when the name can be resolved
+func deptLabel(ctx context.Context, a *adapter.Adapter, deptID int) string {
+	id := strconv.Itoa(deptID)
+	dept, ok := findDept(deptID, func(parentID int) ([]youdu.DeptItem, error) {
+		out, err := a.GetDeptList(ctx, adapter.DeptListInput{DeptID: parentID})
+		if err != nil {
+			return nil, err
+		}
+		return out.Departments, nil
+	})
+	if !ok || dept.Name == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", id, dept.Name)
+}
+
+// findDept looks a department up in the directory tree. There is no API to
+// get a single department, so the tree is walked breadth-first from the root
+// through list, fetching at most maxDeptLookups lists. Lists that fail (e.g.
+// not allowed by the policy) are skipped.
+func findDept(deptID int, list func(parentID int) ([]youdu.DeptItem, error)) (youdu.DeptItem, bool) {
+	queue := []int{0}
+	seen := map[int]bool{0: true}
+	for lookups := 0; len(queue) > 0 && lookups < maxDeptLookups; lookups++ {
+		parentID := queue[0]
+		queue = queue[1:]
+
+		depts, err := list(parentID)
+		if err != nil {
+			continue
+		}
+		for _, dept := range depts {
+			if dept.ID == deptID {
+				return dept, true
+			}
+			if !seen[dept.ID] {
+				seen[dept.ID] = true
+				queue = append(queue, dept.ID)
+			}
+		}
+	}
+	return youdu.DeptItem{}, false
+}
+
+// groupLabel formats a group as "ID (name)" when the name can be resolved
+func groupLabel(ctx context.Context, a *adapter.Adapter, groupID string) string {
+	out, err := a.GetGroupInfo(ctx, adapter.GetGroupInfoInput{GroupID: groupID})
+	if err != nil || out.Group.Name == "" {
+		return groupID
+	}
+	return fmt.Sprintf("%s (%s)", groupID, out.Group.Name)
+}
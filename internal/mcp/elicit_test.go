@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/addcnos/youdu/v2"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+)
+
+// allowDeleteUser 允许删除用户，使 delete_user 被列出
+func allowDeleteUser(cfg *config.Config) {
+	cfg.Permission.SetResourcePolicy(permission.ResourceUser, permission.ResourcePolicy{Read: true, Delete: true})
+}
+
+func callDeleteUser(t *testing.T, session *mcp.ClientSession) *mcp.CallToolResult {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "delete_user",
+		Arguments: map[string]any{"user_id": "10232"},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	return result
+}
+
+func TestElicitConfirmation(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    *mcp.ElicitResult
+		wantError string
+	}{
+		{"接受", &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": true}}, ""},
+		{"接受但未勾选", &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": false}}, "not confirmed"},
+		{"拒绝", &mcp.ElicitResult{Action: "decline"}, "not confirmed"},
+		{"取消", &mcp.ElicitResult{Action: "cancel"}, "not confirmed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var message string
+			session := setupInMemorySessionWithClient(t, allowDeleteUser, &mcp.ClientOptions{
+				ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+					message = req.Params.Message
+					return tt.result, nil
+				},
+			})
+
+			result := callDeleteUser(t, session)
+
+			// 摘要中包含从通讯录解析出的姓名
+			if message != "确认删除用户 10232 (Tc-黎明)？" {
+				t.Errorf("确认消息 = %q", message)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tt.wantError == "" {
+				if result.IsError {
+					t.Errorf("期望确认后执行，得到 %s", text)
+				}
+				return
+			}
+			if !result.IsError || !strings.Contains(text, tt.wantError) {
+				t.Errorf("期望返回 %q，得到 %s", tt.wantError, text)
+			}
+		})
+	}
+}
+
+func TestElicitConfirmation_Language(t *testing.T) {
+	var message string
+	session := setupInMemorySessionWithClient(t, func(cfg *config.Config) {
+		allowDeleteUser(cfg)
+		cfg.Language = config.LanguageEN
+	}, &mcp.ClientOptions{
+		ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			message = req.Params.Message
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		},
+	})
+
+	callDeleteUser(t, session)
+	if message != "Delete user 10232 (Tc-黎明)?" {
+		t.Errorf("确认消息 = %q", message)
+	}
+}
+
+func TestFindDept(t *testing.T) {
+	// 根部门 -> 1 -> 8 -> 20
+	tree := map[int][]youdu.DeptItem{
+		0: {{ID: 1, Name: "研发部"}, {ID: 2, Name: "市场部"}},
+		1: {{ID: 8, Name: "技术中心", ParentID: 1}},
+		8: {{ID: 20, Name: "平台组", ParentID: 8}},
+	}
+	var fetched []int
+	list := func(parentID int) ([]youdu.DeptItem, error) {
+		fetched = append(fetched, parentID)
+		if parentID == 2 {
+			return nil, errors.New("permission denied")
+		}
+		return tree[parentID], nil
+	}
+
+	// 子部门逐级查找
+	dept, ok := findDept(20, list)
+	if !ok || dept.Name != "平台组" {
+		t.Errorf("期望找到平台组，得到 %+v, %v", dept, ok)
+	}
+
+	// 不存在的部门在遍历完后返回 false，查询失败的部门被跳过
+	fetched = nil
+	if _, ok := findDept(99, list); ok {
+		t.Error("期望找不到部门 99")
+	}
+	if len(fetched) != 5 {
+		t.Errorf("期望查询 5 个部门列表，实际 %v", fetched)
+	}
+}
+
+func TestElicitFallback(t *testing.T) {
+	// 客户端不支持 elicitation 时默认拒绝执行
+	session := setupInMemorySession(t, allowDeleteUser)
+	result := callDeleteUser(t, session)
+	if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "does not support elicitation") {
+		t.Errorf("期望默认策略拒绝执行，得到 %+v", result.Content)
+	}
+
+	// elicit_fallback: allow 时直接执行
+	session = setupInMemorySession(t, func(cfg *config.Config) {
+		allowDeleteUser(cfg)
+		cfg.MCP.ElicitFallback = config.ElicitFallbackAllow
+	})
+	if result := callDeleteUser(t, session); result.IsError {
+		t.Errorf("期望允许执行，得到 %+v", result.Content)
+	}
+}
+
+func TestElicitWithConfirmArgument(t *testing.T) {
+	confirmDestructive := func(cfg *config.Config) {
+		allowDeleteUser(cfg)
+		cfg.MCP.ConfirmDestructive = true
+	}
+	callWith := func(session *mcp.ClientSession, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "delete_user", Arguments: args})
+		if err != nil {
+			t.Fatalf("调用工具失败: %v", err)
+		}
+		return result
+	}
+
+	// 客户端支持 elicitation 时始终向用户确认，confirm=true 不能跳过
+	elicited := false
+	session := setupInMemorySessionWithClient(t, confirmDestructive, &mcp.ClientOptions{
+		ElicitationHandler: func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			elicited = true
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		},
+	})
+	result := callWith(session, map[string]any{"user_id": "10232", "confirm": true})
+	if !elicited {
+		t.Error("期望 confirm=true 时仍请求 elicitation")
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "not confirmed") {
+		t.Errorf("期望用户拒绝后不执行，得到 %+v", result.Content)
+	}
+
+	// 客户端不支持 elicitation 时使用 confirm 参数
+	session = setupInMemorySession(t, confirmDestructive)
+	if result := callWith(session, map[string]any{"user_id": "10232"}); !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "requires confirmation") {
+		t.Errorf("期望缺少 confirm 时拒绝执行，得到 %+v", result.Content)
+	}
+	if result := callWith(session, map[string]any{"user_id": "10232", "confirm": true}); result.IsError {
+		t.Errorf("期望 confirm=true 时执行，得到 %+v", result.Content)
+	}
+}
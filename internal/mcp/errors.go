@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+)
+
+// toolError converts an error into an IsError tool result. The text content
+// reads "[code] message" followed by a remediation hint; the same fields are
+// available to programs under _meta.error.
+func (s *Server) toolError(err error) *mcp.CallToolResult {
+	e := apperr.From(err)
+
+	language := ""
+	if s.config != nil {
+		language = s.config.Language
+	}
+	hint := e.Hint(language)
+
+	detail := map[string]any{
+		"code":    e.Code,
+		"message": e.Message,
+		"hint":    hint,
+	}
+	if e.YouduErrCode != 0 {
+		detail["youdu_errcode"] = e.YouduErrCode
+	}
+
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("[%s] %s\nHint: %s", e.Code, e.Message, hint)}},
+		Meta:    mcp.Meta{"error": detail},
+	}
+}
+
+// decodeArguments unmarshals tool arguments into input after checking that
+// they form an object with all required fields, then validates enums and
+// ranges declared in the schema. Field errors are reported in English.
+func decodeArguments(raw json.RawMessage, inputSchema *schema.Schema, input any) error {
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return apperr.Wrap(apperr.CodeInvalidArgument, err, "arguments must be a JSON object")
+	}
+	for _, name := range inputSchema.Required {
+		if value, ok := args[name]; !ok || string(value) == "null" {
+			return fieldError(&schema.FieldError{Field: name, Message: "为必填项", English: "is required"})
+		}
+	}
+	if err := json.Unmarshal(raw, input); err != nil {
+		return apperr.Wrap(apperr.CodeInvalidArgument, err, "failed to unmarshal input")
+	}
+	if err := schema.Validate(input); err != nil {
+		var fieldErr *schema.FieldError
+		if errors.As(err, &fieldErr) {
+			return fieldError(fieldErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// fieldError converts a schema field error into an invalid_argument error
+// with the English message
+func fieldError(err *schema.FieldError) *apperr.Error {
+	return &apperr.Error{Code: apperr.CodeInvalidArgument, Message: err.EnglishError(), Err: err}
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+)
+
+func TestToolErrors(t *testing.T) {
+	// annotate 模式下被禁止的工具仍然列出，调用时返回 permission_denied
+	denyUserRead := func(cfg *config.Config) {
+		cfg.MCP.ForbiddenTools = config.ForbiddenToolsAnnotate
+		cfg.Permission.SetResourcePolicy(permission.ResourceUser, permission.ResourcePolicy{Read: false})
+	}
+
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+		tool      string
+		args      map[string]any
+		wantCode  string
+	}{
+		{"缺少必填参数", nil, "get_user", map[string]any{}, "invalid_argument"},
+		{"必填参数为 null", nil, "get_user", map[string]any{"user_id": nil}, "invalid_argument"},
+		{"参数类型错误", nil, "get_user", map[string]any{"user_id": 10232}, "invalid_argument"},
+		{"权限拒绝", denyUserRead, "get_user", map[string]any{"user_id": "10232"}, "permission_denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := setupInMemorySession(t, tt.configure)
+
+			// 错误作为工具结果返回，而不是协议错误
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+				Name:      tt.tool,
+				Arguments: tt.args,
+			})
+			if err != nil {
+				t.Fatalf("期望返回 IsError 结果，得到协议错误: %v", err)
+			}
+			if !result.IsError {
+				t.Fatalf("期望 IsError，得到 %+v", result.Content)
+			}
+
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !strings.HasPrefix(text, "["+tt.wantCode+"] ") || !strings.Contains(text, "\nHint: ") {
+				t.Errorf("错误文本格式不正确: %s", text)
+			}
+
+			detail, ok := result.Meta["error"].(map[string]any)
+			if !ok {
+				t.Fatalf("_meta.error 缺失: %+v", result.Meta)
+			}
+			if detail["code"] != tt.wantCode {
+				t.Errorf("code = %v, 期望 %s", detail["code"], tt.wantCode)
+			}
+			if hint, _ := detail["hint"].(string); hint == "" {
+				t.Error("hint 为空")
+			}
+		})
+	}
+}
+
+func TestToolErrors_EnglishFieldErrors(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"缺少必填参数", "get_user", map[string]any{}, "argument user_id is required"},
+		{"枚举取值无效", "upload_file", map[string]any{"file_type": "pdf", "file_path": "a.txt"}, "argument file_type must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+				Name:      tt.tool,
+				Arguments: tt.args,
+			})
+			if err != nil {
+				t.Fatalf("调用工具失败: %v", err)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !result.IsError || !strings.Contains(text, tt.want) {
+				t.Errorf("期望包含 %q，得到 %s", tt.want, text)
+			}
+		})
+	}
+}
+
+func TestToolErrors_Language(t *testing.T) {
+	session := setupInMemorySession(t, func(cfg *config.Config) {
+		cfg.Language = config.LanguageEN
+	})
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_user",
+		Arguments: map[string]any{},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+
+	hint, _ := result.Meta["error"].(map[string]any)["hint"].(string)
+	if !strings.Contains(hint, "input schema") {
+		t.Errorf("期望英文提示，得到 %q", hint)
+	}
+}
@@ -0,0 +1,355 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
+	"github.com/yourusername/youdu-app-mcp/internal/lockout"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// HTTP endpoints served by Handler
+const (
+	StreamablePath = "/mcp" // Streamable HTTP transport
+	SSEPath        = "/sse" // legacy HTTP+SSE transport
+)
+
+// tokenIDKey is the TokenInfo.Extra key holding the authenticated token ID
+const tokenIDKey = "token_id"
+
+// Handler returns an http.Handler serving the MCP server over Streamable HTTP
+// (StreamablePath) and SSE (SSEPath). Requests are authenticated with bearer
+// tokens from the config's token manager whenever token auth is enabled,
+// unless the embedding server already authenticated them (token.NewContext).
+func (s *Server) Handler() http.Handler {
+	getServer := func(*http.Request) *mcp.Server { return s.server }
+
+	mux := http.NewServeMux()
+	mux.Handle(StreamablePath, s.requireToken(mcp.NewStreamableHTTPHandler(getServer, nil), false))
+	mux.Handle(SSEPath, s.requireToken(mcp.NewSSEHandler(getServer, nil), true))
+	return mux
+}
+
+// ListenAndServe serves the MCP server over HTTP on addr until ctx is done,
+// over HTTPS when tls is configured (the same settings as serve-api).
+// Serving a non-loopback address without token auth is refused unless
+// insecure is set, so the directory is not exposed to the network by accident.
+func (s *Server) ListenAndServe(ctx context.Context, addr string, insecure bool) error {
+	if !insecure && !s.authEnabled() && !isLoopback(addr) {
+		return fmt.Errorf("refusing to serve MCP on non-loopback address %s without token auth: generate a token, set token.required, listen on a loopback address, or pass --insecure", addr)
+	}
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: s.Handler(),
+	}
+	scheme := "http"
+	if s.config.TLS.Enabled() {
+		tlsConfig, err := s.config.TLS.Build()
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = tlsConfig
+		scheme = "https"
+	}
+	fmt.Fprintf(os.Stderr, "youdu-mcp listening on %s://%s (Streamable HTTP: %s, SSE: %s)\n", scheme, addr, StreamablePath, SSEPath)
+
+	errCh := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			errCh <- server.ListenAndServeTLS("", "")
+			return
+		}
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// authEnabled reports whether HTTP requests currently require a token
+func (s *Server) authEnabled() bool {
+	return s.config.TokenManager != nil && s.config.TokenManager.AuthEnabled()
+}
+
+// isLoopback reports whether addr only listens on a loopback interface.
+// An empty host listens on all interfaces.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireToken wraps next with bearer token authentication.
+// Whether auth is required is decided per request, so tokens generated or
+// revoked while the server runs take effect immediately.
+//
+// When the handler is mounted on the API server, requests have already been
+// authenticated there (lockout, IP allowlist, client certificates, signed
+// requests) and carry the token in their context; it is trusted as is and the
+// request is passed on unchanged.
+//
+// Sessions are bound to the token that first used them (sessionBindings).
+//
+// The SSE transport does not carry token info into tool calls, so tokens
+// restricted by scope are rejected there (rejectScoped) and must use
+// Streamable HTTP instead.
+func (s *Server) requireToken(next http.Handler, rejectScoped bool) http.Handler {
+	sessions := newSessionBindings()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok, ok := token.FromContext(r.Context())
+		if !ok {
+			if !s.authEnabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if tok, ok = s.authenticate(w, r); !ok {
+				return
+			}
+		}
+
+		if rejectScoped && tok.HasScopes() {
+			http.Error(w, fmt.Sprintf("tokens with scopes must use the Streamable HTTP endpoint %s", StreamablePath), http.StatusUnauthorized)
+			return
+		}
+		if !sessions.bind(sessionID(r), tok.ID) {
+			http.Error(w, "session belongs to a different token", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(contextWithTokenInfo(r.Context(), tokenInfo(tok))))
+	})
+}
+
+// authenticate checks the bearer token of a request that was not
+// authenticated by an embedding server, writing the error response on
+// failure. Failed attempts count towards the lockout of the client address,
+// as they do on the API server.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (*token.Token, bool) {
+	ip := lockout.ClientIP(r)
+	if until, locked := s.throttle.LockedUntil(ip); locked {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(until).Seconds())+1))
+		http.Error(w, "too many failed authentication attempts, try again later", http.StatusTooManyRequests)
+		return nil, false
+	}
+
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		http.Error(w, "no bearer token", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	tok, err := s.verifyToken(fields[1], ip)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		s.throttle.RecordFailure(ip)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	s.throttle.RecordSuccess(ip)
+	return tok, true
+}
+
+// tokenInfo describes an authenticated token to the SDK, which passes it to
+// tool calls as req.Extra.TokenInfo
+func tokenInfo(tok *token.Token) *auth.TokenInfo {
+	// The SDK requires an expiration; permanent tokens are valid for this request
+	expiration := time.Now().Add(time.Hour)
+	if tok.ExpiresAt != nil {
+		expiration = *tok.ExpiresAt
+	}
+	return &auth.TokenInfo{
+		Scopes:     tok.Scopes,
+		Expiration: expiration,
+		Extra:      map[string]any{tokenIDKey: tok.ID},
+	}
+}
+
+// contextWithTokenInfo returns ctx carrying info where the SDK transports look
+// for it. The SDK keeps the context key unexported and only sets it in
+// auth.RequireBearerToken, so the middleware is run on a throwaway request
+// whose only purpose is to capture the resulting context; the request being
+// served is not modified.
+func contextWithTokenInfo(ctx context.Context, info *auth.TokenInfo) context.Context {
+	probe, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	probe.Header.Set("Authorization", "Bearer authenticated")
+
+	result := ctx
+	verifier := func(context.Context, string, *http.Request) (*auth.TokenInfo, error) {
+		return info, nil
+	}
+	capture := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		result = r.Context()
+	})
+	auth.RequireBearerToken(verifier, nil)(capture).ServeHTTP(discardWriter{}, probe)
+	return result
+}
+
+// discardWriter is the response writer of the throwaway request in
+// contextWithTokenInfo; the verifier never fails, so nothing is written
+type discardWriter struct{}
+
+func (discardWriter) Header() http.Header         { return http.Header{} }
+func (discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardWriter) WriteHeader(int)             {}
+
+// Session bindings are kept for sessionBindingTTL after their last request,
+// and expired ones are pruned once more than maxSessionBindings are tracked
+const (
+	maxSessionBindings = 1024
+	sessionBindingTTL  = 24 * time.Hour
+)
+
+// sessionBindings binds MCP session IDs to the token that first used them, so
+// a session cannot be continued with a different token. (The SDK version in
+// use has no TokenInfo.UserID to bind sessions itself.)
+type sessionBindings struct {
+	mu       sync.Mutex
+	sessions map[string]sessionBinding
+	now      func() time.Time
+}
+
+// sessionBinding is the token bound to a session
+type sessionBinding struct {
+	tokenID  string
+	lastSeen time.Time
+}
+
+func newSessionBindings() *sessionBindings {
+	return &sessionBindings{
+		sessions: make(map[string]sessionBinding),
+		now:      time.Now,
+	}
+}
+
+// bind records that tokenID uses the session and reports whether the session
+// is unbound or already bound to the same token. Requests without a session
+// (initialize, opening an SSE stream) are always allowed.
+func (b *sessionBindings) bind(sessionID, tokenID string) bool {
+	if sessionID == "" {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if len(b.sessions) >= maxSessionBindings {
+		for id, binding := range b.sessions {
+			if now.Sub(binding.lastSeen) > sessionBindingTTL {
+				delete(b.sessions, id)
+			}
+		}
+	}
+
+	if binding, exists := b.sessions[sessionID]; exists && binding.tokenID != tokenID {
+		return false
+	}
+	b.sessions[sessionID] = sessionBinding{tokenID: tokenID, lastSeen: now}
+	return true
+}
+
+// sessionID returns the MCP session of a request: the Mcp-Session-Id header
+// (Streamable HTTP) or the sessionid query parameter (SSE)
+func sessionID(r *http.Request) string {
+	if id := r.Header.Get("Mcp-Session-Id"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("sessionid")
+}
+
+// verifyToken validates a bearer token value for an MCP HTTP request from ip
+func (s *Server) verifyToken(value, ip string) (*token.Token, error) {
+	mgr := s.config.TokenManager
+
+	// Store failures (e.g. a rotated encryption key) surface as 500 rather
+	// than rejecting a valid token as invalid
+	tok, err := mgr.Lookup(value)
+	if errors.Is(err, token.ErrNotFound) {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up token: %w", err)
+	}
+
+	// Signing tokens are HMAC secrets and must never be sent on the wire
+	if tok.SignsRequests() {
+		return nil, fmt.Errorf("%w: token only supports signed requests", auth.ErrInvalidToken)
+	}
+
+	if !tok.AllowsIP(net.ParseIP(ip)) {
+		return nil, fmt.Errorf("%w: token is not allowed from %s", auth.ErrInvalidToken, ip)
+	}
+
+	if !mgr.Validate(value) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return tok, nil
+}
+
+// checkToolScope checks that the token authenticating an HTTP request may call
+// the tool. Requests without token info (stdio, auth disabled) are allowed.
+func checkToolScope(req *mcp.CallToolRequest, toolName, methodName string) error {
+	if req == nil {
+		return nil
+	}
+	return checkScope(req.Extra, toolName, methodName)
+}
+
+// withTokenScopes attaches the request's token scopes to ctx, so that adapter
+// methods such as DescribeCapabilities can take them into account.
+func withTokenScopes(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil || req.Extra == nil || req.Extra.TokenInfo == nil {
+		return ctx
+	}
+	return token.NewContext(ctx, &token.Token{Scopes: req.Extra.TokenInfo.Scopes})
+}
+
+// checkScope checks the request's token scopes against the endpoint name and
+// the resource/action of the adapter method backing it.
+func checkScope(extra *mcp.RequestExtra, name, methodName string) error {
+	if extra == nil || extra.TokenInfo == nil || !adapter.RequiresScope(methodName) {
+		return nil
+	}
+
+	var resource, action string
+	if op, found := adapter.LookupOperation(methodName); found {
+		resource, action = string(op.Resource), string(op.Action)
+	}
+
+	tok := &token.Token{Scopes: extra.TokenInfo.Scopes}
+	if !tok.Allows(name, resource, action) {
+		return apperr.New(apperr.CodePermissionDenied, "token is not allowed to call %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,400 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/lockout"
+	"github.com/yourusername/youdu-app-mcp/internal/token"
+)
+
+// bearerTransport adds an Authorization header to every request
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// setupHTTPServer starts the MCP HTTP handler backed by the mock YouDu server
+func setupHTTPServer(t *testing.T, configure func(cfg *config.Config)) (*httptest.Server, *token.Manager) {
+	t.Helper()
+
+	cfg, err := config.LoadFromFile("../../config_test.yaml")
+	if err != nil {
+		t.Fatalf("加载测试配置失败: %v", err)
+	}
+
+	mockServer := testdata.NewMockYouDuServer(cfg.Youdu.AesKey, cfg.Youdu.AppID)
+	t.Cleanup(mockServer.Close)
+	cfg.Youdu.Addr = mockServer.URL()
+
+	mgr := token.NewManager(nil)
+	cfg.TokenManager = mgr
+	if configure != nil {
+		configure(cfg)
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建 MCP 服务器失败: %v", err)
+	}
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+	return httpServer, mgr
+}
+
+// connect opens a client session with the given bearer token
+func connect(t *testing.T, transport mcp.Transport) (*mcp.ClientSession, error) {
+	t.Helper()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	return client.Connect(context.Background(), transport, nil)
+}
+
+func TestHTTPHandler_TokenAuth(t *testing.T) {
+	httpServer, mgr := setupHTTPServer(t, nil)
+
+	full, err := mgr.Generate("full access", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+	scoped, err := mgr.GenerateWithOptions("read only", nil, token.GenerateOptions{Scopes: []string{"dept:read"}})
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	streamable := func(value string) mcp.Transport {
+		return &mcp.StreamableClientTransport{
+			Endpoint:   httpServer.URL + StreamablePath,
+			HTTPClient: &http.Client{Transport: &bearerTransport{token: value}},
+		}
+	}
+
+	t.Run("缺少 token", func(t *testing.T) {
+		if session, err := connect(t, streamable("")); err == nil {
+			session.Close()
+			t.Fatal("期望缺少 token 时连接失败")
+		}
+	})
+
+	t.Run("无效 token", func(t *testing.T) {
+		if session, err := connect(t, streamable("invalid")); err == nil {
+			session.Close()
+			t.Fatal("期望无效 token 时连接失败")
+		}
+	})
+
+	t.Run("有效 token", func(t *testing.T) {
+		session, err := connect(t, streamable(full.Value))
+		if err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer session.Close()
+
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "get_dept_list",
+			Arguments: map[string]any{"dept_id": 0},
+		})
+		if err != nil {
+			t.Fatalf("调用工具失败: %v", err)
+		}
+		if result.IsError {
+			t.Errorf("期望调用成功，得到错误结果: %+v", result.Content)
+		}
+	})
+
+	t.Run("scope 限制工具调用", func(t *testing.T) {
+		session, err := connect(t, streamable(scoped.Value))
+		if err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer session.Close()
+
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "send_text_message",
+			Arguments: map[string]any{"to_user": "test", "content": "hello"},
+		})
+		if err != nil {
+			t.Fatalf("调用工具失败: %v", err)
+		}
+		if !result.IsError {
+			t.Error("期望 scope 外的工具调用返回错误结果")
+		}
+
+		result, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "get_dept_list",
+			Arguments: map[string]any{"dept_id": 0},
+		})
+		if err != nil {
+			t.Fatalf("调用工具失败: %v", err)
+		}
+		if result.IsError {
+			t.Errorf("期望 scope 内的工具调用成功，得到错误结果: %+v", result.Content)
+		}
+
+		// 能力说明不受 scope 限制，且按 scope 过滤
+		result, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "describe_capabilities"})
+		if err != nil {
+			t.Fatalf("调用工具失败: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("期望能力说明调用成功，得到错误结果: %+v", result.Content)
+		}
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			t.Fatalf("序列化结构化内容失败: %v", err)
+		}
+		var output adapter.DescribeCapabilitiesOutput
+		if err := json.Unmarshal(data, &output); err != nil {
+			t.Fatalf("解析结构化内容失败: %v", err)
+		}
+		for _, c := range output.Resources {
+			if c.Resource != "dept" && len(c.Operations) > 0 {
+				t.Errorf("scope 外的资源不应有可调用的工具: %+v", c)
+			}
+		}
+	})
+
+	t.Run("SSE 拒绝限制了 scope 的 token", func(t *testing.T) {
+		transport := &mcp.SSEClientTransport{
+			Endpoint:   httpServer.URL + SSEPath,
+			HTTPClient: &http.Client{Transport: &bearerTransport{token: scoped.Value}},
+		}
+		if session, err := connect(t, transport); err == nil {
+			session.Close()
+			t.Fatal("期望 SSE 拒绝限制了 scope 的 token")
+		}
+	})
+
+	t.Run("SSE 有效 token", func(t *testing.T) {
+		transport := &mcp.SSEClientTransport{
+			Endpoint:   httpServer.URL + SSEPath,
+			HTTPClient: &http.Client{Transport: &bearerTransport{token: full.Value}},
+		}
+		session, err := connect(t, transport)
+		if err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer session.Close()
+
+		if _, err := session.ListTools(context.Background(), nil); err != nil {
+			t.Errorf("列出工具失败: %v", err)
+		}
+	})
+}
+
+func TestHTTPHandler_AuthDisabled(t *testing.T) {
+	httpServer, _ := setupHTTPServer(t, nil)
+
+	// 没有任何 token 时不需要认证
+	session, err := connect(t, &mcp.StreamableClientTransport{Endpoint: httpServer.URL + StreamablePath})
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer session.Close()
+
+	if _, err := session.ListTools(context.Background(), nil); err != nil {
+		t.Errorf("列出工具失败: %v", err)
+	}
+}
+
+func TestHTTPHandler_Lockout(t *testing.T) {
+	httpServer, mgr := setupHTTPServer(t, func(cfg *config.Config) {
+		cfg.Token.Lockout = config.LockoutConfig{MaxAttempts: 3, Window: time.Minute, Duration: time.Minute}
+	})
+	valid, err := mgr.Generate("full access", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	send := func(value string) int {
+		req, _ := http.NewRequest(http.MethodPost, httpServer.URL+StreamablePath, strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+value)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for i := 0; i < 3; i++ {
+		if status := send("wrong-token"); status != http.StatusUnauthorized {
+			t.Fatalf("第 %d 次失败：期望状态码 %v，得到 %v", i+1, http.StatusUnauthorized, status)
+		}
+	}
+
+	// 锁定后，即使使用有效 token 也被拒绝
+	if status := send(valid.Value); status != http.StatusTooManyRequests {
+		t.Errorf("期望状态码 %v，得到 %v", http.StatusTooManyRequests, status)
+	}
+}
+
+func TestListenAndServe_RequiresAuthOffLoopback(t *testing.T) {
+	cfg, err := config.LoadFromFile("../../config_test.yaml")
+	if err != nil {
+		t.Fatalf("加载测试配置失败: %v", err)
+	}
+	cfg.TokenManager = token.NewManager(nil)
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建 MCP 服务器失败: %v", err)
+	}
+
+	// 未启用认证时拒绝监听所有网卡
+	if err := server.ListenAndServe(context.Background(), ":0", false); err == nil || !strings.Contains(err.Error(), "--insecure") {
+		t.Errorf("期望拒绝在非回环地址上无认证监听，得到 %v", err)
+	}
+
+	// 回环地址和显式 --insecure 允许监听
+	for _, tt := range []struct {
+		addr     string
+		insecure bool
+	}{
+		{"127.0.0.1:0", false},
+		{":0", true},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := server.ListenAndServe(ctx, tt.addr, tt.insecure); err != nil {
+			t.Errorf("%s (insecure=%v): 期望正常监听，得到 %v", tt.addr, tt.insecure, err)
+		}
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8090": true,
+		"[::1]:8090":     true,
+		"localhost:8090": true,
+		":8090":          false,
+		"0.0.0.0:8090":   false,
+		"10.0.0.1:8090":  false,
+		"8090":           false,
+	}
+	for addr, want := range tests {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v，期望 %v", addr, got, want)
+		}
+	}
+}
+
+func TestHTTPHandler_TrustedToken(t *testing.T) {
+	cfg, err := config.LoadFromFile("../../config_test.yaml")
+	if err != nil {
+		t.Fatalf("加载测试配置失败: %v", err)
+	}
+	mockServer := testdata.NewMockYouDuServer(cfg.Youdu.AesKey, cfg.Youdu.AppID)
+	defer mockServer.Close()
+	cfg.Youdu.Addr = mockServer.URL()
+
+	cfg.TokenManager = token.NewManager(nil)
+	scoped, err := cfg.TokenManager.GenerateWithOptions("read only", nil, token.GenerateOptions{Scopes: []string{"dept:read"}})
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建 MCP 服务器失败: %v", err)
+	}
+
+	// 模拟 API 服务器已完成认证（例如客户端证书），请求不带 bearer token
+	handler := server.Handler()
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(token.NewContext(r.Context(), scoped)))
+	}))
+	defer httpServer.Close()
+
+	session, err := connect(t, &mcp.StreamableClientTransport{Endpoint: httpServer.URL + StreamablePath})
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "send_text_message",
+		Arguments: map[string]any{"to_user": "test", "content": "hello"},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if !result.IsError {
+		t.Error("期望 scope 外的工具调用返回错误结果")
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_dept_list",
+		Arguments: map[string]any{"dept_id": 0},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("期望 scope 内的工具调用成功，得到错误结果: %+v", result.Content)
+	}
+}
+
+func TestRequireToken_TrustedContext(t *testing.T) {
+	s := &Server{config: &config.Config{TokenManager: token.NewManager(nil)}, throttle: lockout.New(config.LockoutConfig{})}
+
+	var (
+		info   *auth.TokenInfo
+		header http.Header
+	)
+	handler := s.requireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info = auth.TokenInfoFromContext(r.Context())
+		header = r.Header
+	}), false)
+
+	send := func(tok *token.Token, session string) int {
+		req := httptest.NewRequest(http.MethodPost, StreamablePath, nil)
+		if session != "" {
+			req.Header.Set("Mcp-Session-Id", session)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req.WithContext(token.NewContext(req.Context(), tok)))
+		return rr.Code
+	}
+
+	// API 服务器认证的 token 直接转换为 token info，不改写请求 header
+	certToken := &token.Token{ID: "cert-token", Scopes: []string{"dept:read"}}
+	if status := send(certToken, "session-1"); status != http.StatusOK {
+		t.Fatalf("期望状态码 %v，得到 %v", http.StatusOK, status)
+	}
+	if info == nil || info.Extra[tokenIDKey] != "cert-token" || len(info.Scopes) != 1 || info.Scopes[0] != "dept:read" {
+		t.Errorf("期望 token info 来自上下文中的 token，得到 %+v", info)
+	}
+	if header.Get("Authorization") != "" {
+		t.Errorf("期望不改写 Authorization header，得到 %q", header.Get("Authorization"))
+	}
+
+	// 会话绑定到首次使用它的 token
+	other := &token.Token{ID: "other-token"}
+	if status := send(other, "session-1"); status != http.StatusForbidden {
+		t.Errorf("期望其他 token 使用同一会话时返回 %v，得到 %v", http.StatusForbidden, status)
+	}
+	if status := send(certToken, "session-1"); status != http.StatusOK {
+		t.Errorf("期望原 token 继续使用会话，得到 %v", status)
+	}
+	if status := send(other, "session-2"); status != http.StatusOK {
+		t.Errorf("期望其他 token 使用自己的会话，得到 %v", status)
+	}
+}
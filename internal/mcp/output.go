@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// renderText renders a tool output as indented "key: value" text for clients
+// that display the text content instead of the structured content
+func renderText(output interface{}) string {
+	var b strings.Builder
+	writeValue(&b, reflect.ValueOf(output), 0)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// writeValue writes v at the given indentation level. Scalars are written
+// inline; structs, maps and slices start on the next line.
+func writeValue(b *strings.Builder, v reflect.Value, indent int) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			b.WriteString("null\n")
+			return
+		}
+		v = v.Elem()
+	}
+
+	pad := strings.Repeat("  ", indent)
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+			writeEntry(b, pad, name, v.Field(i), indent)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = fmt.Sprint(k.Interface())
+		}
+		order := make(map[string]reflect.Value, len(keys))
+		for i, k := range keys {
+			order[names[i]] = v.MapIndex(k)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			writeEntry(b, pad, name, order[name], indent)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if isScalar(item) {
+				fmt.Fprintf(b, "%s- %s\n", pad, scalarString(item))
+				continue
+			}
+			fmt.Fprintf(b, "%s- #%d\n", pad, i+1)
+			writeValue(b, item, indent+1)
+		}
+	default:
+		fmt.Fprintf(b, "%s%s\n", pad, scalarString(v))
+	}
+}
+
+// writeEntry writes a named value, inline for scalars and nested otherwise
+func writeEntry(b *strings.Builder, pad, name string, v reflect.Value, indent int) {
+	if isScalar(v) {
+		fmt.Fprintf(b, "%s%s: %s\n", pad, name, scalarString(v))
+		return
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			fmt.Fprintf(b, "%s%s: (none)\n", pad, name)
+			return
+		}
+		fmt.Fprintf(b, "%s%s (%d):\n", pad, name, v.Len())
+	default:
+		fmt.Fprintf(b, "%s%s:\n", pad, name)
+	}
+	writeValue(b, v, indent+1)
+}
+
+// isScalar reports whether v is written inline
+func isScalar(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		return false
+	case reflect.Slice, reflect.Array:
+		// Short lists of scalars (e.g. department IDs) stay on one line
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+		return v.Len() > 0 && v.Len() <= 10 && isScalarKind(v.Type().Elem().Kind())
+	default:
+		return true
+	}
+}
+
+// isScalarKind reports whether values of kind k are written as plain values
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Pointer, reflect.Interface:
+		return false
+	default:
+		return true
+	}
+}
+
+// scalarString formats a scalar value (or a short list of scalars)
+func scalarString(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "null"
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 {
+			return `""`
+		}
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("<%d bytes>", v.Len())
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = scalarString(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// jsonFieldName returns the JSON name of an exported struct field
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	jsonTag := field.Tag.Get("json")
+	if jsonTag == "-" {
+		return "", false
+	}
+	if name := strings.Split(jsonTag, ",")[0]; name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
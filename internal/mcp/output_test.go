@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/addcnos/youdu/v2"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+)
+
+func TestRenderText(t *testing.T) {
+	text := renderText(&adapter.DeptListOutput{
+		Departments: []youdu.DeptItem{
+			{ID: 1, Name: "研发部", ParentID: 0},
+			{ID: 2, Name: "测试组", ParentID: 1},
+		},
+	})
+	for _, want := range []string{"departments (2):", "- #1", "name: 研发部", "parentId: 1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("渲染结果缺少 %q:\n%s", want, text)
+		}
+	}
+
+	if got := renderText(&adapter.DeleteUserOutput{Success: true}); got != "success: true" {
+		t.Errorf("渲染结果 = %q, 期望 %q", got, "success: true")
+	}
+
+	if got := renderText(&adapter.DeptUserListOutput{}); got != "users: (none)" {
+		t.Errorf("渲染结果 = %q, 期望 %q", got, "users: (none)")
+	}
+
+	text = renderText(&adapter.GetUserOutput{User: youdu.UserResponse{UserID: "zhangsan", Dept: []int{1, 2}}})
+	if !strings.Contains(text, "dept: [1, 2]") {
+		t.Errorf("期望短列表在同一行渲染:\n%s", text)
+	}
+}
+
+func TestToolStructuredOutput(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("列出工具失败: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.OutputSchema == nil {
+			t.Errorf("工具 %s 缺少输出 schema", tool.Name)
+		}
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_dept_list",
+		Arguments: map[string]any{"dept_id": 0},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("期望调用成功，得到错误结果: %+v", result.Content)
+	}
+
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("序列化结构化内容失败: %v", err)
+	}
+	var out adapter.DeptListOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("结构化内容与输出类型不一致: %v", err)
+	}
+
+	if len(result.Content) != 1 {
+		t.Fatalf("期望 1 条文本内容，得到 %d 条", len(result.Content))
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || !strings.HasPrefix(text.Text, "departments") {
+		t.Errorf("期望可读文本渲染，得到 %+v", result.Content[0])
+	}
+}
+
+func TestDescribeCapabilitiesTool(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+
+	// 无参数调用
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "describe_capabilities"})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("期望调用成功，得到 %+v", result.Content)
+	}
+
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("序列化结构化内容失败: %v", err)
+	}
+	var output adapter.DescribeCapabilitiesOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatalf("解析结构化内容失败: %v", err)
+	}
+	if !output.PermissionEnabled || len(output.Resources) != 5 {
+		t.Errorf("能力说明 = %+v", output)
+	}
+	for _, c := range output.Resources {
+		if c.Resource == "dept" && strings.Contains(strings.Join(c.Operations, ","), "delete_dept") {
+			t.Errorf("config_test.yaml 不允许删除部门: %+v", c)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+)
+
+// forbiddenNote prefixes the description of tools kept in annotate mode
+const forbiddenNote = "[Not allowed by the current permission policy] "
+
+// registeredTool is an adapter tool that can be (re-)added to the MCP server
+type registeredTool struct {
+	tool       *mcp.Tool
+	handler    mcp.ToolHandler
+	methodName string
+}
+
+// syncTools makes the listed tools match the permission policy. Tools whose
+// resource/action the policy denies are removed, or kept with a note in their
+// description when mcp.forbidden_tools is "annotate". The SDK sends
+// notifications/tools/list_changed to connected clients for every change.
+func (s *Server) syncTools() {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+
+	annotate := s.config != nil && s.config.MCP.ForbiddenTools == config.ForbiddenToolsAnnotate
+
+	var remove []string
+	for _, rt := range s.tools {
+		name := rt.tool.Name
+		current, listed := s.toolState[name]
+
+		description := rt.tool.Description
+		if !s.toolAllowed(rt.methodName) {
+			if !annotate {
+				if listed {
+					remove = append(remove, name)
+					delete(s.toolState, name)
+				}
+				continue
+			}
+			description = forbiddenNote + description
+		}
+
+		if listed && current == description {
+			continue
+		}
+
+		tool := *rt.tool
+		tool.Description = description
+		s.server.AddTool(&tool, rt.handler)
+		s.toolState[name] = description
+	}
+
+	if len(remove) > 0 {
+		s.server.RemoveTools(remove...)
+	}
+}
+
+// toolAllowed reports whether the policy allows the resource/action of an
+// adapter method. Row-level allowlists are checked when the tool is called.
+func (s *Server) toolAllowed(methodName string) bool {
+	if s.config == nil || s.config.GetPermission() == nil {
+		return true
+	}
+	op, found := adapter.LookupOperation(methodName)
+	if !found {
+		return true
+	}
+	return s.config.GetPermission().Check(op.Resource, op.Action) == nil
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+)
+
+// listToolNames returns the descriptions of the listed tools by name
+func listToolNames(t *testing.T, session *mcp.ClientSession) map[string]string {
+	t.Helper()
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("列出工具失败: %v", err)
+	}
+	tools := map[string]string{}
+	for _, tool := range result.Tools {
+		tools[tool.Name] = tool.Description
+	}
+	return tools
+}
+
+func TestSyncTools_HideForbidden(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+	tools := listToolNames(t, session)
+
+	// config_test.yaml 允许读取，禁止创建/更新/删除用户和部门
+	for _, name := range []string{"get_user", "get_dept_list", "send_text_message"} {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("期望列出允许的工具 %s", name)
+		}
+	}
+	for _, name := range []string{"delete_dept", "create_user", "update_session"} {
+		if _, ok := tools[name]; ok {
+			t.Errorf("不应列出策略禁止的工具 %s", name)
+		}
+	}
+}
+
+func TestSyncTools_Annotate(t *testing.T) {
+	session := setupInMemorySession(t, func(cfg *config.Config) {
+		cfg.MCP.ForbiddenTools = config.ForbiddenToolsAnnotate
+	})
+	tools := listToolNames(t, session)
+
+	if desc, ok := tools["delete_dept"]; !ok || !strings.HasPrefix(desc, forbiddenNote) {
+		t.Errorf("期望保留并标注 delete_dept，得到 %q (%v)", desc, ok)
+	}
+	if desc := tools["get_user"]; strings.HasPrefix(desc, forbiddenNote) {
+		t.Errorf("允许的工具不应被标注: %q", desc)
+	}
+}
+
+func TestSyncTools_PolicyReload(t *testing.T) {
+	cfgData, err := os.ReadFile("../../config_test.yaml")
+	if err != nil {
+		t.Fatalf("读取 config_test.yaml 失败: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, cfgData, 0600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+
+	cfg, err := config.LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	mockServer := testdata.NewMockYouDuServer(cfg.Youdu.AesKey, cfg.Youdu.AppID)
+	t.Cleanup(mockServer.Close)
+	cfg.Youdu.Addr = mockServer.URL()
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建 MCP 服务器失败: %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("服务端连接失败: %v", err)
+	}
+	defer serverSession.Close()
+
+	changed := make(chan struct{}, 16)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	defer session.Close()
+
+	if _, ok := listToolNames(t, session)["delete_dept"]; ok {
+		t.Fatal("重新加载前不应列出 delete_dept")
+	}
+
+	// 修改配置文件允许删除部门后重新加载
+	updated := strings.Replace(string(cfgData), "delete: false   # 禁止删除部门", "delete: true", 1)
+	if updated == string(cfgData) {
+		t.Fatal("未能修改测试配置")
+	}
+	if err := os.WriteFile(configPath, []byte(updated), 0600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	if err := cfg.ReloadPermission(); err != nil {
+		t.Fatalf("重新加载权限失败: %v", err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到 tools/list_changed 通知")
+	}
+	if _, ok := listToolNames(t, session)["delete_dept"]; !ok {
+		t.Error("重新加载后应列出 delete_dept")
+	}
+
+	// 策略收紧后再次隐藏
+	cfg.Permission.SetResourcePolicy(permission.ResourceDept, permission.ResourcePolicy{Read: true})
+	if _, ok := listToolNames(t, session)["delete_dept"]; ok {
+		t.Error("策略收紧后不应列出 delete_dept")
+	}
+}
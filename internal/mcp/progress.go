@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+)
+
+// progressTotal is the total of every progress notification; adapter progress
+// is scaled to it
+const progressTotal = 100
+
+// progressNotifier sends notifications/progress for a tool call's progress
+// token. Every call that carries a token gets at least a start and a finish
+// notification; multi-step adapter methods report their steps in between.
+type progressNotifier struct {
+	ctx     context.Context
+	session *mcp.ServerSession
+	token   any
+
+	mu   sync.Mutex
+	last float64
+	sent bool
+}
+
+// newProgressNotifier returns a notifier for the request's progress token, or
+// nil when the client did not ask for progress.
+func newProgressNotifier(ctx context.Context, req *mcp.CallToolRequest) *progressNotifier {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+	return &progressNotifier{ctx: ctx, session: req.Session, token: token}
+}
+
+// notify sends progress out of progressTotal. Progress that does not increase
+// is dropped, since clients expect it to grow with every notification.
+func (p *progressNotifier) notify(progress float64, message string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sent && progress <= p.last {
+		return
+	}
+	p.sent, p.last = true, progress
+
+	// Progress is best-effort: a failed notification must not fail the call
+	_ = p.session.NotifyProgress(p.ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Progress:      progress,
+		Total:         progressTotal,
+		Message:       message,
+	})
+}
+
+// attach forwards adapter progress to the client through ctx
+func (p *progressNotifier) attach(ctx context.Context) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return adapter.WithProgress(ctx, func(progress, total float64, message string) {
+		// Progress with an unknown total cannot be scaled
+		if total > 0 {
+			p.notify(progress/total*progressTotal, message)
+		}
+	})
+}
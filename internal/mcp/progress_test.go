@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
+)
+
+func TestToolProgressNotifications(t *testing.T) {
+	notifications := make(chan *mcp.ProgressNotificationParams, 256)
+	session := setupInMemorySessionWithClient(t, nil, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			notifications <- req.Params
+		},
+	})
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name: "send_file_with_upload",
+		Arguments: map[string]any{
+			"to_user":   "10232",
+			"file_path": testdata.UploadFilePath,
+		},
+		Meta: mcp.Meta{"progressToken": "upload-1"},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("期望调用成功，得到 %+v", result.Content)
+	}
+
+	// 通知异步送达，等待最后一次进度
+	var last float64
+	for last < 100 {
+		select {
+		case n := <-notifications:
+			if n.ProgressToken != "upload-1" {
+				t.Errorf("progressToken = %v", n.ProgressToken)
+			}
+			if n.Total != 100 || n.Progress < last {
+				t.Errorf("进度 = %v/%v，上一次 %v", n.Progress, n.Total, last)
+			}
+			last = n.Progress
+		case <-time.After(5 * time.Second):
+			t.Fatalf("未收到完成进度通知，最后一次进度 %v", last)
+		}
+	}
+}
+
+func TestToolProgressNotifications_StartAndFinish(t *testing.T) {
+	notifications := make(chan *mcp.ProgressNotificationParams, 16)
+	session := setupInMemorySessionWithClient(t, nil, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			notifications <- req.Params
+		},
+	})
+
+	// 没有分步骤进度的工具也报告开始和完成
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_dept_list",
+		Arguments: map[string]any{"dept_id": 0},
+		Meta:      mcp.Meta{"progressToken": "dept-1"},
+	})
+	if err != nil {
+		t.Fatalf("调用工具失败: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("期望调用成功，得到 %+v", result.Content)
+	}
+
+	var got []float64
+	for len(got) < 2 {
+		select {
+		case n := <-notifications:
+			if n.ProgressToken != "dept-1" || n.Total != 100 {
+				t.Errorf("通知 = %+v", n)
+			}
+			got = append(got, n.Progress)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("期望收到开始和完成通知，得到 %v", got)
+		}
+	}
+	if got[0] != 0 || got[1] != 100 {
+		t.Errorf("进度 = %v，期望 [0 100]", got)
+	}
+}
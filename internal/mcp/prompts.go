@@ -0,0 +1,255 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
+)
+
+// announceToDeptArgs are the arguments of the announce_to_department prompt
+type announceToDeptArgs struct {
+	DeptID  int    `json:"dept_id" jsonschema:"description=Department ID to announce to,required"`
+	Title   string `json:"title" jsonschema:"description=Announcement title (sent as a system message when set)"`
+	Message string `json:"message" jsonschema:"description=Announcement content,required"`
+}
+
+// onboardNewHireArgs are the arguments of the onboard_new_hire prompt
+type onboardNewHireArgs struct {
+	UserID  string `json:"user_id" jsonschema:"description=User ID of the new hire,required"`
+	Name    string `json:"name" jsonschema:"description=Name of the new hire,required"`
+	DeptID  int    `json:"dept_id" jsonschema:"description=Department ID the new hire joins,required"`
+	Mobile  string `json:"mobile" jsonschema:"description=Mobile phone number"`
+	Email   string `json:"email" jsonschema:"description=Email address"`
+	GroupID string `json:"group_id" jsonschema:"description=Group ID to add the new hire to"`
+	Buddy   string `json:"buddy" jsonschema:"description=User ID of the onboarding buddy to notify"`
+}
+
+// incidentBroadcastArgs are the arguments of the incident_broadcast prompt
+type incidentBroadcastArgs struct {
+	Severity  string `json:"severity" jsonschema:"description=Incident severity: critical, major or minor,required"`
+	Summary   string `json:"summary" jsonschema:"description=What is happening and who is affected,required"`
+	ToDept    string `json:"to_dept" jsonschema:"description=Department IDs to notify (use pipe | to separate multiple departments)"`
+	ToUser    string `json:"to_user" jsonschema:"description=User IDs to notify (use pipe | to separate multiple users)"`
+	StatusURL string `json:"status_url" jsonschema:"description=Link to the incident status page"`
+}
+
+// summarizeGroupArgs are the arguments of the summarize_group_membership prompt
+type summarizeGroupArgs struct {
+	GroupID string `json:"group_id" jsonschema:"description=Group ID to summarize,required"`
+}
+
+// incidentSeverities are the accepted incident_broadcast severities
+var incidentSeverities = []string{"critical", "major", "minor"}
+
+// registerPrompts registers the workflow prompts with the MCP server
+func (s *Server) registerPrompts() {
+	addPrompt(s, "announce_to_department", "Announce to department",
+		"Send an announcement to every member of a department", renderAnnounceToDept)
+	addPrompt(s, "onboard_new_hire", "Onboard a new hire",
+		"Create a user account, add it to a group and send a welcome message", renderOnboardNewHire)
+	addPrompt(s, "incident_broadcast", "Incident broadcast",
+		"Broadcast an incident notice to departments and users", renderIncidentBroadcast)
+	addPrompt(s, "summarize_group_membership", "Summarize group membership",
+		"Summarize who belongs to a group and which departments they come from", renderSummarizeGroup)
+}
+
+// addPrompt registers a prompt whose arguments are described by the struct A.
+// Arguments are derived from A's json/jsonschema tags and decoded into A
+// before render builds the user message.
+func addPrompt[A any](s *Server, name, title, description string, render func(A) (string, error)) {
+	argsType := reflect.TypeFor[A]()
+
+	prompt := &mcp.Prompt{
+		Name:        name,
+		Title:       title,
+		Description: description,
+		Arguments:   generatePromptArguments(argsType),
+	}
+
+	handler := func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		var args A
+		if err := decodePromptArguments(req.Params.Arguments, reflect.ValueOf(&args).Elem()); err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", name, err)
+		}
+
+		text, err := render(args)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", name, err)
+		}
+
+		return &mcp.GetPromptResult{
+			Description: description,
+			Messages: []*mcp.PromptMessage{{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: text},
+			}},
+		}, nil
+	}
+
+	s.server.AddPrompt(prompt, handler)
+}
+
+// generatePromptArguments builds prompt arguments from a struct's json and jsonschema tags
+func generatePromptArguments(argsType reflect.Type) []*mcp.PromptArgument {
+	var arguments []*mcp.PromptArgument
+	for i := 0; i < argsType.NumField(); i++ {
+		field := argsType.Field(i)
+		name, ok := promptArgumentName(field)
+		if !ok {
+			continue
+		}
+
+		tag := schema.ParseTag(field.Tag.Get("jsonschema"))
+		arguments = append(arguments, &mcp.PromptArgument{
+			Name:        name,
+			Description: tag.Description,
+			Required:    tag.Required,
+		})
+	}
+	return arguments
+}
+
+// decodePromptArguments converts the string arguments of a prompt request into
+// the fields of args, checking required arguments and numeric values
+func decodePromptArguments(raw map[string]string, args reflect.Value) error {
+	argsType := args.Type()
+	for i := 0; i < argsType.NumField(); i++ {
+		field := argsType.Field(i)
+		name, ok := promptArgumentName(field)
+		if !ok {
+			continue
+		}
+
+		value := strings.TrimSpace(raw[name])
+		if value == "" {
+			if schema.ParseTag(field.Tag.Get("jsonschema")).Required {
+				return fmt.Errorf("missing required argument %q", name)
+			}
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.String:
+			args.Field(i).SetString(value)
+		case reflect.Int, reflect.Int64:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("argument %q must be an integer", name)
+			}
+			args.Field(i).SetInt(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("argument %q must be a boolean", name)
+			}
+			args.Field(i).SetBool(b)
+		default:
+			return fmt.Errorf("argument %q has unsupported type %s", name, field.Type)
+		}
+	}
+	return nil
+}
+
+// promptArgumentName returns the argument name from the field's json tag
+func promptArgumentName(field reflect.StructField) (string, bool) {
+	jsonTag := field.Tag.Get("json")
+	if jsonTag == "" || jsonTag == "-" {
+		return "", false
+	}
+	return strings.Split(jsonTag, ",")[0], true
+}
+
+func renderAnnounceToDept(args announceToDeptArgs) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Announce the following to department %d.\n\n", args.DeptID)
+	fmt.Fprintf(&b, "1. Call get_dept_list with dept_id=%d to confirm the department exists and note its name.\n", args.DeptID)
+	if args.Title != "" {
+		fmt.Fprintf(&b, "2. Call send_sys_message with to_dept=\"%d\", title=%q and the content below.\n", args.DeptID, args.Title)
+	} else {
+		fmt.Fprintf(&b, "2. Call send_text_message with to_dept=\"%d\" and the content below.\n", args.DeptID)
+	}
+	b.WriteString("3. Report the department name and whether the announcement was sent.\n\n")
+	fmt.Fprintf(&b, "Content:\n%s", args.Message)
+	return b.String(), nil
+}
+
+func renderOnboardNewHire(args onboardNewHireArgs) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Onboard %s (user ID %s) into department %d.\n\n", args.Name, args.UserID, args.DeptID)
+	fmt.Fprintf(&b, "1. Call get_user with user_id=%q. If the user already exists, skip step 2.\n", args.UserID)
+	fmt.Fprintf(&b, "2. Call create_user with user_id=%q, name=%q, dept_id=%d", args.UserID, args.Name, args.DeptID)
+	if args.Mobile != "" {
+		fmt.Fprintf(&b, ", mobile=%q", args.Mobile)
+	}
+	if args.Email != "" {
+		fmt.Fprintf(&b, ", email=%q", args.Email)
+	}
+	b.WriteString(".\n")
+
+	step := 3
+	if args.GroupID != "" {
+		fmt.Fprintf(&b, "%d. Call add_group_member with group_id=%q and members=[%q].\n", step, args.GroupID, args.UserID)
+		step++
+	}
+	fmt.Fprintf(&b, "%d. Call send_text_message with to_user=%q and a short, friendly welcome message.\n", step, args.UserID)
+	step++
+	if args.Buddy != "" {
+		fmt.Fprintf(&b, "%d. Call send_text_message with to_user=%q introducing %s as their new colleague.\n", step, args.Buddy, args.Name)
+		step++
+	}
+	fmt.Fprintf(&b, "%d. Summarize which steps succeeded and which failed.", step)
+	return b.String(), nil
+}
+
+func renderIncidentBroadcast(args incidentBroadcastArgs) (string, error) {
+	severity := strings.ToLower(args.Severity)
+	valid := false
+	for _, s := range incidentSeverities {
+		if severity == s {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", fmt.Errorf("argument \"severity\" must be one of %s", strings.Join(incidentSeverities, ", "))
+	}
+	if args.ToDept == "" && args.ToUser == "" {
+		return "", fmt.Errorf("at least one of \"to_dept\" or \"to_user\" is required")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Broadcast a %s incident notice.\n\n", severity)
+	b.WriteString("1. Call send_sys_message with")
+	if args.ToDept != "" {
+		fmt.Fprintf(&b, " to_dept=%q", args.ToDept)
+	}
+	if args.ToUser != "" {
+		fmt.Fprintf(&b, " to_user=%q", args.ToUser)
+	}
+	fmt.Fprintf(&b, ", title=\"[%s] Incident\" and a concise content built from the summary below", strings.ToUpper(severity))
+	if severity == "critical" {
+		b.WriteString(", with pop_duration=60 so the notice pops up")
+	}
+	b.WriteString(".\n")
+	if args.StatusURL != "" {
+		fmt.Fprintf(&b, "2. Call send_link_message to the same recipients with title=\"Incident status\" and url=%q.\n", args.StatusURL)
+	}
+	b.WriteString("Keep the notice factual: what is affected, current impact and when the next update follows.\n\n")
+	fmt.Fprintf(&b, "Summary:\n%s", args.Summary)
+	return b.String(), nil
+}
+
+func renderSummarizeGroup(args summarizeGroupArgs) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summarize the membership of group %s.\n\n", args.GroupID)
+	fmt.Fprintf(&b, "1. Read the resource youdu://group/%s (or call get_group_info with group_id=%q).\n", args.GroupID, args.GroupID)
+	b.WriteString("2. For each member, call get_user to resolve their name and departments.\n")
+	b.WriteString("3. Report the group name, the member count and members grouped by department. ")
+	b.WriteString("List members that could not be resolved separately.")
+	return b.String(), nil
+}
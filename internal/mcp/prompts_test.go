@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPrompts_List(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+
+	result, err := session.ListPrompts(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("列出 prompts 失败: %v", err)
+	}
+
+	prompts := map[string]*mcp.Prompt{}
+	for _, p := range result.Prompts {
+		prompts[p.Name] = p
+	}
+	for _, name := range []string{"announce_to_department", "onboard_new_hire", "incident_broadcast", "summarize_group_membership"} {
+		if prompts[name] == nil {
+			t.Errorf("缺少 prompt %s", name)
+		}
+	}
+
+	// 参数从结构体标签生成
+	announce := prompts["announce_to_department"]
+	if announce == nil {
+		return
+	}
+	required := map[string]bool{}
+	for _, arg := range announce.Arguments {
+		required[arg.Name] = arg.Required
+	}
+	if !required["dept_id"] || !required["message"] || required["title"] {
+		t.Errorf("announce_to_department 参数不符合预期: %v", required)
+	}
+}
+
+func TestPrompts_Get(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		args     map[string]string
+		wantErr  bool
+		contains []string
+	}{
+		{
+			name:     "announce_to_department",
+			args:     map[string]string{"dept_id": "12", "message": "明天放假"},
+			contains: []string{"send_text_message", `to_dept="12"`, "明天放假"},
+		},
+		{
+			name:     "announce_to_department",
+			args:     map[string]string{"dept_id": "12", "title": "通知", "message": "明天放假"},
+			contains: []string{"send_sys_message"},
+		},
+		{
+			name:    "announce_to_department",
+			args:    map[string]string{"dept_id": "abc", "message": "明天放假"},
+			wantErr: true,
+		},
+		{
+			name:    "announce_to_department",
+			args:    map[string]string{"dept_id": "12"},
+			wantErr: true,
+		},
+		{
+			name:     "onboard_new_hire",
+			args:     map[string]string{"user_id": "zhangsan", "name": "张三", "dept_id": "3", "group_id": "g1"},
+			contains: []string{"create_user", "add_group_member", `group_id="g1"`},
+		},
+		{
+			name:     "incident_broadcast",
+			args:     map[string]string{"severity": "Critical", "summary": "数据库不可用", "to_dept": "1"},
+			contains: []string{"[CRITICAL]", "pop_duration", "数据库不可用"},
+		},
+		{
+			name:    "incident_broadcast",
+			args:    map[string]string{"severity": "unknown", "summary": "x", "to_dept": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "incident_broadcast",
+			args:    map[string]string{"severity": "minor", "summary": "x"},
+			wantErr: true,
+		},
+		{
+			name:     "summarize_group_membership",
+			args:     map[string]string{"group_id": "g1"},
+			contains: []string{"youdu://group/g1", "get_user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: tt.name, Arguments: tt.args})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望参数 %v 返回错误", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("获取 prompt 失败: %v", err)
+			}
+			if len(result.Messages) != 1 {
+				t.Fatalf("期望 1 条消息，得到 %d 条", len(result.Messages))
+			}
+			text, ok := result.Messages[0].Content.(*mcp.TextContent)
+			if !ok {
+				t.Fatalf("期望文本内容，得到 %T", result.Messages[0].Content)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(text.Text, want) {
+					t.Errorf("prompt 内容缺少 %q:\n%s", want, text.Text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+)
+
+// resourceScheme is the URI scheme of YouDu resources
+const resourceScheme = "youdu"
+
+// resourceTemplate describes a YouDu entity exposed as an MCP resource template.
+// Reads go through the adapter method named by methodName, so permission and
+// row-level checks are the same as for the matching tool.
+type resourceTemplate struct {
+	template   *mcp.ResourceTemplate
+	methodName string
+	parse      func(segments []string) (bool, string)
+	read       func(ctx context.Context, a *adapter.Adapter, id string) (any, error)
+}
+
+// resourceTemplates lists the resource templates served by the MCP server
+var resourceTemplates = []resourceTemplate{
+	{
+		template: &mcp.ResourceTemplate{
+			Name:        "dept",
+			Title:       "Department",
+			Description: "Department and its sub-departments",
+			URITemplate: "youdu://dept/{id}",
+			MIMEType:    "application/json",
+		},
+		methodName: "GetDeptList",
+		parse:      matchSegments("dept", ""),
+		read: func(ctx context.Context, a *adapter.Adapter, id string) (any, error) {
+			deptID, err := strconv.Atoi(id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid department ID %q", id)
+			}
+			return a.GetDeptList(ctx, adapter.DeptListInput{DeptID: deptID})
+		},
+	},
+	{
+		template: &mcp.ResourceTemplate{
+			Name:        "dept_users",
+			Title:       "Department users",
+			Description: "Users belonging to a department",
+			URITemplate: "youdu://dept/{id}/users",
+			MIMEType:    "application/json",
+		},
+		methodName: "GetDeptUserList",
+		parse:      matchSegments("dept", "users"),
+		read: func(ctx context.Context, a *adapter.Adapter, id string) (any, error) {
+			deptID, err := strconv.Atoi(id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid department ID %q", id)
+			}
+			return a.GetDeptUserList(ctx, adapter.DeptUserListInput{DeptID: deptID})
+		},
+	},
+	{
+		template: &mcp.ResourceTemplate{
+			Name:        "user",
+			Title:       "User",
+			Description: "User profile",
+			URITemplate: "youdu://user/{id}",
+			MIMEType:    "application/json",
+		},
+		methodName: "GetUser",
+		parse:      matchSegments("user", ""),
+		read: func(ctx context.Context, a *adapter.Adapter, id string) (any, error) {
+			return a.GetUser(ctx, adapter.GetUserInput{UserID: id})
+		},
+	},
+	{
+		template: &mcp.ResourceTemplate{
+			Name:        "group",
+			Title:       "Group",
+			Description: "Group information and members",
+			URITemplate: "youdu://group/{id}",
+			MIMEType:    "application/json",
+		},
+		methodName: "GetGroupInfo",
+		parse:      matchSegments("group", ""),
+		read: func(ctx context.Context, a *adapter.Adapter, id string) (any, error) {
+			return a.GetGroupInfo(ctx, adapter.GetGroupInfoInput{GroupID: id})
+		},
+	},
+}
+
+// matchSegments returns a parser accepting URIs of the form
+// youdu://<kind>/<id>[/<suffix>] and extracting the ID
+func matchSegments(kind, suffix string) func(segments []string) (bool, string) {
+	return func(segments []string) (bool, string) {
+		want := 2
+		if suffix != "" {
+			want = 3
+		}
+		if len(segments) != want || segments[0] != kind || segments[1] == "" {
+			return false, ""
+		}
+		if suffix != "" && segments[2] != suffix {
+			return false, ""
+		}
+		return true, segments[1]
+	}
+}
+
+// registerResources registers the YouDu resource templates with the MCP server.
+// All templates share one handler that dispatches on the parsed URI, since the
+// SDK's template matching does not distinguish youdu://dept/{id} from
+// youdu://dept/{id}/users reliably.
+func (s *Server) registerResources() {
+	for _, rt := range resourceTemplates {
+		s.server.AddResourceTemplate(rt.template, s.readResource)
+	}
+}
+
+// readResource reads the entity addressed by a youdu:// URI
+func (s *Server) readResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	segments, err := splitResourceURI(uri)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	for _, rt := range resourceTemplates {
+		ok, id := rt.parse(segments)
+		if !ok {
+			continue
+		}
+
+		// Enforce token scopes for HTTP transports
+		if err := checkScope(req.Extra, adapter.ToSnakeCase(rt.methodName), rt.methodName); err != nil {
+			return nil, err
+		}
+
+		output, err := rt.read(ctx, s.adapter, id)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal resource %s: %w", uri, err)
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      uri,
+				MIMEType: rt.template.MIMEType,
+				Text:     string(data),
+			}},
+		}, nil
+	}
+
+	return nil, mcp.ResourceNotFoundError(uri)
+}
+
+// splitResourceURI splits a youdu:// URI into its unescaped path segments,
+// treating the host as the first segment (youdu://dept/1 -> [dept 1])
+func splitResourceURI(uri string) ([]string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != resourceScheme {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	segments := []string{u.Host}
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		segments = append(segments, strings.Split(path, "/")...)
+	}
+	return segments, nil
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
+	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/permission"
+)
+
+// setupInMemorySession connects a client to the MCP server over in-memory transports
+func setupInMemorySession(t *testing.T, configure func(cfg *config.Config)) *mcp.ClientSession {
+	t.Helper()
+	return setupInMemorySessionWithClient(t, configure, nil)
+}
+
+// setupInMemorySessionWithClient is setupInMemorySession with custom client options
+func setupInMemorySessionWithClient(t *testing.T, configure func(cfg *config.Config), opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	cfg, err := config.LoadFromFile("../../config_test.yaml")
+	if err != nil {
+		t.Fatalf("加载测试配置失败: %v", err)
+	}
+
+	mockServer := testdata.NewMockYouDuServer(cfg.Youdu.AesKey, cfg.Youdu.AppID)
+	t.Cleanup(mockServer.Close)
+	cfg.Youdu.Addr = mockServer.URL()
+
+	if configure != nil {
+		configure(cfg)
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("创建 MCP 服务器失败: %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("服务端连接失败: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestResourceTemplates_List(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+
+	result, err := session.ListResourceTemplates(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("列出资源模板失败: %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, tmpl := range result.ResourceTemplates {
+		got[tmpl.URITemplate] = true
+	}
+	for _, want := range []string{"youdu://dept/{id}", "youdu://dept/{id}/users", "youdu://user/{id}", "youdu://group/{id}"} {
+		if !got[want] {
+			t.Errorf("缺少资源模板 %s", want)
+		}
+	}
+}
+
+func TestResourceTemplates_Read(t *testing.T) {
+	session := setupInMemorySession(t, nil)
+	ctx := context.Background()
+
+	t.Run("读取部门", func(t *testing.T) {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "youdu://dept/0"})
+		if err != nil {
+			t.Fatalf("读取资源失败: %v", err)
+		}
+		if len(result.Contents) != 1 || result.Contents[0].MIMEType != "application/json" {
+			t.Fatalf("资源内容不符合预期: %+v", result.Contents)
+		}
+		var out map[string]any
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &out); err != nil {
+			t.Fatalf("资源内容不是 JSON: %v", err)
+		}
+		if _, ok := out["departments"]; !ok {
+			t.Errorf("期望包含 departments 字段，得到 %v", out)
+		}
+	})
+
+	t.Run("读取用户", func(t *testing.T) {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "youdu://user/test_user"})
+		if err != nil {
+			t.Fatalf("读取资源失败: %v", err)
+		}
+		if result.Contents[0].URI != "youdu://user/test_user" {
+			t.Errorf("资源 URI 不符合预期: %s", result.Contents[0].URI)
+		}
+	})
+
+	t.Run("无效部门 ID", func(t *testing.T) {
+		if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "youdu://dept/abc"}); err == nil {
+			t.Error("期望无效部门 ID 返回错误")
+		}
+	})
+
+	t.Run("未知资源", func(t *testing.T) {
+		if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "youdu://dept/1/unknown"}); err == nil {
+			t.Error("期望未知资源返回错误")
+		}
+	})
+}
+
+func TestResourceTemplates_PermissionDenied(t *testing.T) {
+	session := setupInMemorySession(t, func(cfg *config.Config) {
+		cfg.Permission.SetResourcePolicy(permission.ResourceUser, permission.ResourcePolicy{Read: false})
+	})
+
+	if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "youdu://user/test_user"}); err == nil {
+		t.Error("期望没有读取权限时返回错误")
+	}
+}
+
+func TestSplitResourceURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want []string
+	}{
+		{"youdu://dept/1", []string{"dept", "1"}},
+		{"youdu://dept/1/users", []string{"dept", "1", "users"}},
+		{"youdu://user/zhang%2Esan", []string{"user", "zhang.san"}},
+		{"youdu://group/", []string{"group"}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitResourceURI(tt.uri)
+		if err != nil {
+			t.Errorf("splitResourceURI(%q) 返回错误: %v", tt.uri, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitResourceURI(%q) = %v, 期望 %v", tt.uri, got, tt.want)
+		}
+	}
+
+	if _, err := splitResourceURI("http://dept/1"); err == nil {
+		t.Error("期望非 youdu scheme 返回错误")
+	}
+}
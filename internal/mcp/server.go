@@ -5,18 +5,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
-	"strings"
-	"unicode"
+	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/yourusername/youdu-app-mcp/internal/adapter"
 	"github.com/yourusername/youdu-app-mcp/internal/config"
+	"github.com/yourusername/youdu-app-mcp/internal/lockout"
+	"github.com/yourusername/youdu-app-mcp/internal/schema"
 )
 
 // Server represents the MCP server
 type Server struct {
-	server  *mcp.Server
-	adapter *adapter.Adapter
+	server   *mcp.Server
+	adapter  *adapter.Adapter
+	config   *config.Config
+	throttle *lockout.Throttle // authentication failure lockout (HTTP transports)
+
+	toolsMu   sync.Mutex
+	tools     []*registeredTool // all adapter tools, in registration order
+	toolState map[string]string // description of each currently listed tool
 }
 
 // New creates a new MCP server
@@ -32,8 +39,11 @@ func New(cfg *config.Config) (*Server, error) {
 	}, nil)
 
 	s := &Server{
-		server:  server,
-		adapter: adapter,
+		server:    server,
+		adapter:   adapter,
+		config:    cfg,
+		throttle:  lockout.New(cfg.Token.Lockout),
+		toolState: make(map[string]string),
 	}
 
 	// Register all adapter methods as MCP tools
@@ -41,10 +51,22 @@ func New(cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to register tools: %w", err)
 	}
 
+	// List only the tools the permission policy allows, and re-sync on policy changes
+	s.syncTools()
+	if perm := cfg.GetPermission(); perm != nil {
+		perm.OnChange(s.syncTools)
+	}
+
+	// Expose departments, users and groups as resources
+	s.registerResources()
+
+	// Offer prompts for common workflows
+	s.registerPrompts()
+
 	return s, nil
 }
 
-// Run starts the MCP server
+// Run starts the MCP server on stdio
 func (s *Server) Run(ctx context.Context) error {
 	transport := &mcp.StdioTransport{}
 	return s.server.Run(ctx, transport)
@@ -65,7 +87,7 @@ func (s *Server) registerTools() error {
 		}
 
 		// Convert method name to snake_case for MCP tool name
-		toolName := toSnakeCase(method.Name)
+		toolName := adapter.ToSnakeCase(method.Name)
 
 		// Get method type information
 		methodType := method.Type
@@ -89,8 +111,8 @@ func (s *Server) registerTools() error {
 		inputType := methodType.In(2)
 		outputType := methodType.Out(0)
 
-		// Create tool description from method name
-		description := generateDescription(method.Name)
+		// Look up the curated description in the configured language
+		description := adapter.Describe(method.Name, s.config.Language)
 
 		// Register the tool
 		if err := s.registerTool(toolName, description, method, adapterValue, inputType, outputType); err != nil {
@@ -101,28 +123,73 @@ func (s *Server) registerTools() error {
 	return nil
 }
 
-// registerTool registers a single tool with the MCP server
-func (s *Server) registerTool(name, description string, method reflect.Method, adapterValue reflect.Value, inputType, outputType reflect.Type) error {
-	// Create input schema from the input type
-	inputSchema := generateInputSchema(inputType)
+// registerTool builds a single tool; syncTools adds it to the MCP server
+func (s *Server) registerTool(name string, description adapter.Description, method reflect.Method, adapterValue reflect.Value, inputType, outputType reflect.Type) error {
+	// Create input and output schemas from the adapter types
+	inputSchema := schema.For(inputType)
+
+	// Derive hints from the permission model; destructive tools may require confirmation
+	annotations := toolAnnotations(method.Name)
+	annotations.Title = description.Summary
+	requireConfirm := s.config != nil && s.config.MCP.ConfirmDestructive && isDestructive(annotations)
+	if requireConfirm {
+		addConfirmArgument(inputSchema)
+	}
 
 	// Create tool definition
 	tool := &mcp.Tool{
-		Name:        name,
-		Description: description,
-		InputSchema: inputSchema,
+		Name:         name,
+		Description:  description.Text(),
+		InputSchema:  inputSchema,
+		OutputSchema: schema.ForOutput(outputType),
+		Annotations:  annotations,
 	}
 
-	// Create handler function
-	handler := func(ctx context.Context, req *mcp.CallToolRequest, rawInput json.RawMessage) (*mcp.CallToolResult, any, error) {
-		// Create new input instance
+	// Create handler function. Errors are returned as IsError results with a
+	// machine-readable code and a hint, so that agents can recover from them.
+	call := func(ctx context.Context, req *mcp.CallToolRequest) (any, error) {
+		// Enforce token scopes for HTTP transports
+		if err := checkToolScope(req, name, method.Name); err != nil {
+			return nil, err
+		}
+		ctx = withTokenScopes(ctx, req)
+
+		rawInput := req.Params.Arguments
+		if len(rawInput) == 0 || string(rawInput) == "null" {
+			rawInput = json.RawMessage("{}")
+		}
+
+		// Destructive tools run only after the client confirmed with the user.
+		// Tools with a confirmation summary always ask the user through
+		// elicitation; the confirm argument is only the fallback for clients
+		// that do not support it
+		summarize, elicit := confirmSummaries[method.Name]
+		if requireConfirm && !(elicit && supportsElicitation(req)) {
+			if err := checkConfirmed(name, rawInput); err != nil {
+				return nil, err
+			}
+		}
+
+		// Decode and validate the arguments against the input schema
 		input := reflect.New(inputType).Interface()
+		if err := decodeArguments(rawInput, inputSchema, input); err != nil {
+			return nil, err
+		}
 
-		// Unmarshal input
-		if err := json.Unmarshal(rawInput, input); err != nil {
-			return nil, nil, fmt.Errorf("failed to unmarshal input: %w", err)
+		// Ask the user before deleting directory data
+		if elicit {
+			if err := s.confirmWithUser(ctx, req, name, summarize(ctx, s.adapter, s.confirmText(), input), requireConfirm); err != nil {
+				return nil, err
+			}
 		}
 
+		// Report progress to the client when it asked for it: start, adapter
+		// steps and finish. The SDK cancels ctx when the client sends
+		// notifications/cancelled
+		progress := newProgressNotifier(ctx, req)
+		progress.notify(0, "Calling "+name)
+		ctx = progress.attach(ctx)
+
 		// Call the adapter method
 		results := method.Func.Call([]reflect.Value{
 			adapterValue,
@@ -132,124 +199,31 @@ func (s *Server) registerTool(name, description string, method reflect.Method, a
 
 		// Check for error
 		if !results[1].IsNil() {
-			err := results[1].Interface().(error)
-			return nil, nil, err
-		}
-
-		// Return output
-		output := results[0].Interface()
-		return &mcp.CallToolResult{}, output, nil
-	}
-
-	// Add tool to server
-	mcp.AddTool(s.server, tool, handler)
-
-	return nil
-}
-
-// toSnakeCase converts a string from PascalCase to snake_case
-func toSnakeCase(s string) string {
-	var result strings.Builder
-	for i, r := range s {
-		if i > 0 && unicode.IsUpper(r) {
-			result.WriteRune('_')
+			progress.notify(progressTotal, name+" failed")
+			return nil, results[1].Interface().(error)
 		}
-		result.WriteRune(unicode.ToLower(r))
+		progress.notify(progressTotal, name+" finished")
+		return results[0].Interface(), nil
 	}
-	return result.String()
-}
-
-// generateDescription generates a human-readable description from a method name
-func generateDescription(methodName string) string {
-	// Convert PascalCase to space-separated words
-	var words []string
-	var currentWord strings.Builder
 
-	for i, r := range methodName {
-		if i > 0 && unicode.IsUpper(r) {
-			words = append(words, currentWord.String())
-			currentWord.Reset()
+	handler := func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		output, err := call(ctx, req)
+		if err != nil {
+			return s.toolError(err), nil
 		}
-		currentWord.WriteRune(unicode.ToLower(r))
-	}
-	words = append(words, currentWord.String())
 
-	return strings.Join(words, " ")
-}
-
-// generateInputSchema generates a JSON schema for the input type
-func generateInputSchema(inputType reflect.Type) map[string]interface{} {
-	schema := map[string]interface{}{
-		"type":       "object",
-		"properties": make(map[string]interface{}),
+		// Return structured output along with a readable text rendering
+		return &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: renderText(output)}},
+			StructuredContent: output,
+		}, nil
 	}
 
-	properties := schema["properties"].(map[string]interface{})
-	required := []string{}
-
-	// Iterate through struct fields
-	for i := 0; i < inputType.NumField(); i++ {
-		field := inputType.Field(i)
-
-		// Get JSON tag
-		jsonTag := field.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
-			continue
-		}
-
-		// Extract field name from JSON tag
-		fieldName := strings.Split(jsonTag, ",")[0]
-
-		// Get jsonschema tag for description and required
-		schemaTag := field.Tag.Get("jsonschema")
-		fieldSchema := map[string]interface{}{
-			"type": getJSONType(field.Type),
-		}
+	s.tools = append(s.tools, &registeredTool{
+		tool:       tool,
+		handler:    handler,
+		methodName: method.Name,
+	})
 
-		// Parse jsonschema tag
-		if schemaTag != "" {
-			parts := strings.Split(schemaTag, ",")
-			for _, part := range parts {
-				part = strings.TrimSpace(part)
-				if strings.HasPrefix(part, "description=") {
-					fieldSchema["description"] = strings.TrimPrefix(part, "description=")
-				} else if part == "required" {
-					required = append(required, fieldName)
-				} else if strings.HasPrefix(part, "default=") {
-					// Skip default values - MCP SDK has strict type checking
-					// Users can provide defaults in their requests
-					continue
-				}
-			}
-		}
-
-		properties[fieldName] = fieldSchema
-	}
-
-	if len(required) > 0 {
-		schema["required"] = required
-	}
-
-	return schema
-}
-
-// getJSONType returns the JSON schema type for a Go type
-func getJSONType(t reflect.Type) string {
-	switch t.Kind() {
-	case reflect.String:
-		return "string"
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return "integer"
-	case reflect.Float32, reflect.Float64:
-		return "number"
-	case reflect.Bool:
-		return "boolean"
-	case reflect.Slice, reflect.Array:
-		return "array"
-	case reflect.Map, reflect.Struct:
-		return "object"
-	default:
-		return "string"
-	}
+	return nil
 }
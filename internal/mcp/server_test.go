@@ -7,9 +7,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
 	"github.com/yourusername/youdu-app-mcp/internal/adapter/testdata"
 	"github.com/yourusername/youdu-app-mcp/internal/config"
 	"gopkg.in/yaml.v3"
@@ -150,8 +152,9 @@ func TestMCP_WithMockServer(t *testing.T) {
 			t.Fatal("工具列表格式错误")
 		}
 
-		if len(tools) != 28 {
-			t.Errorf("期望 28 个工具，得到 %d 个", len(tools))
+		// 只列出 config_test.yaml 权限策略允许的工具
+		if want := countAllowedTools(t, cfg); len(tools) != want {
+			t.Errorf("期望 %d 个工具，得到 %d 个", want, len(tools))
 		}
 
 		t.Logf("✓ 工具列表获取成功: %d 个工具", len(tools))
@@ -162,7 +165,7 @@ func TestMCP_WithMockServer(t *testing.T) {
 	for _, tc := range testdata.AllTestCases {
 		t.Run(tc.Name, func(t *testing.T) {
 			// 转换方法名为 snake_case
-			toolName := toSnakeCase(tc.Method)
+			toolName := adapter.ToSnakeCase(tc.Method)
 
 			request := map[string]interface{}{
 				"jsonrpc": "2.0",
@@ -190,8 +193,10 @@ func TestMCP_WithMockServer(t *testing.T) {
 
 			// 验证响应
 			if tc.ShouldError {
-				// 期望返回错误
-				if result, ok := response["result"].(map[string]interface{}); ok {
+				// 期望返回错误：策略禁止的工具不会注册，调用返回 JSON-RPC 错误
+				if _, ok := response["error"]; ok {
+					t.Logf("✓ 权限控制正常（工具未注册）: %s", tc.ErrorMsg)
+				} else if result, ok := response["result"].(map[string]interface{}); ok {
 					if isError, _ := result["isError"].(bool); !isError {
 						t.Errorf("期望操作被拒绝，但成功了\n完整响应: %+v\n测试用例: %+v", response, tc)
 					} else {
@@ -212,6 +217,25 @@ func TestMCP_WithMockServer(t *testing.T) {
 	}
 }
 
+// countAllowedTools 统计权限策略允许的 adapter 方法数量
+func countAllowedTools(t *testing.T, cfg *config.Config) int {
+	t.Helper()
+
+	count := 0
+	adapterType := reflect.TypeOf((*adapter.Adapter)(nil))
+	for i := 0; i < adapterType.NumMethod(); i++ {
+		method := adapterType.Method(i)
+		if method.Name == "Close" || method.Name == "Context" || method.Type.NumIn() != 3 || method.Type.NumOut() != 2 {
+			continue
+		}
+		op, found := adapter.LookupOperation(method.Name)
+		if !found || cfg.Permission.Check(op.Resource, op.Action) == nil {
+			count++
+		}
+	}
+	return count
+}
+
 // createTempTestConfig 创建临时测试配置文件
 // 基于 config_test.yaml，但覆盖 youdu.addr 为 Mock Server URL
 func createTempTestConfig(t *testing.T, mockServerURL string) string {
@@ -308,4 +332,3 @@ func TestMCP_UnitTests(t *testing.T) {
 
 	t.Log("✓ MCP 服务器创建成功")
 }
-
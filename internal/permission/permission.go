@@ -1,8 +1,9 @@
 package permission
 
 import (
-	"fmt"
 	"sync"
+
+	"github.com/yourusername/youdu-app-mcp/internal/apperr"
 )
 
 // Action 操作类型
@@ -32,6 +33,7 @@ type Permission struct {
 	AllowAll  bool                        // 是否允许所有操作（调试用）
 	Resources map[Resource]ResourcePolicy // 资源权限策略
 	mu        sync.RWMutex                // 保护并发访问
+	listeners []func()                    // 策略变更回调
 }
 
 // AllowSend 消息发送权限配置
@@ -83,7 +85,7 @@ func (p *Permission) CheckWithID(resource Resource, action Action, resourceID st
 	// 检查资源权限
 	policy, exists := p.Resources[resource]
 	if !exists {
-		return fmt.Errorf("权限拒绝：未配置资源 '%s' 的权限策略", resource)
+		return apperr.New(apperr.CodePermissionDenied, "权限拒绝：未配置资源 '%s' 的权限策略", resource)
 	}
 
 	// 检查具体操作权限
@@ -98,11 +100,11 @@ func (p *Permission) CheckWithID(resource Resource, action Action, resourceID st
 	case ActionDelete:
 		allowed = policy.Delete
 	default:
-		return fmt.Errorf("权限拒绝：未知的操作类型 '%s'", action)
+		return apperr.New(apperr.CodePermissionDenied, "权限拒绝：未知的操作类型 '%s'", action)
 	}
 
 	if !allowed {
-		return fmt.Errorf("权限拒绝：不允许对资源 '%s' 执行 '%s' 操作", resource, action)
+		return apperr.New(apperr.CodePermissionDenied, "权限拒绝：不允许对资源 '%s' 执行 '%s' 操作", resource, action)
 	}
 
 	// 检查行级权限（如果配置了 allowlist 且提供了 resourceID）
@@ -116,7 +118,7 @@ func (p *Permission) CheckWithID(resource Resource, action Action, resourceID st
 			}
 		}
 		if !found {
-			return fmt.Errorf("权限拒绝：资源 ID '%s' 不在允许列表中", resourceID)
+			return apperr.New(apperr.CodePermissionDenied, "权限拒绝：资源 ID '%s' 不在允许列表中", resourceID)
 		}
 	}
 
@@ -138,12 +140,12 @@ func (p *Permission) CheckMessageSend(toUser, toDept string) error {
 	// 检查资源权限
 	policy, exists := p.Resources[ResourceMessage]
 	if !exists {
-		return fmt.Errorf("权限拒绝：未配置资源 'message' 的权限策略")
+		return apperr.New(apperr.CodePermissionDenied, "权限拒绝：未配置资源 'message' 的权限策略")
 	}
 
 	// 检查创建权限（发送消息需要 create 权限）
 	if !policy.Create {
-		return fmt.Errorf("权限拒绝：不允许发送消息")
+		return apperr.New(apperr.CodePermissionDenied, "权限拒绝：不允许发送消息")
 	}
 
 	// 检查是否配置了 allowsend 限制
@@ -161,7 +163,7 @@ func (p *Permission) CheckMessageSend(toUser, toDept string) error {
 		users := splitIDs(toUser)
 		for _, userID := range users {
 			if !contains(policy.AllowSend.Users, userID) {
-				return fmt.Errorf("权限拒绝：不允许向用户 '%s' 发送消息", userID)
+				return apperr.New(apperr.CodePermissionDenied, "权限拒绝：不允许向用户 '%s' 发送消息", userID)
 			}
 		}
 	}
@@ -172,7 +174,7 @@ func (p *Permission) CheckMessageSend(toUser, toDept string) error {
 		depts := splitIDs(toDept)
 		for _, deptID := range depts {
 			if !contains(policy.AllowSend.Dept, deptID) {
-				return fmt.Errorf("权限拒绝：不允许向部门 '%s' 发送消息", deptID)
+				return apperr.New(apperr.CodePermissionDenied, "权限拒绝：不允许向部门 '%s' 发送消息", deptID)
 			}
 		}
 	}
@@ -180,7 +182,7 @@ func (p *Permission) CheckMessageSend(toUser, toDept string) error {
 	// 如果配置了限制，但没有指定任何接收者
 	if toUser == "" && toDept == "" {
 		if hasUserLimit || hasDeptLimit {
-			return fmt.Errorf("权限拒绝：必须指定接收者")
+			return apperr.New(apperr.CodePermissionDenied, "权限拒绝：必须指定接收者")
 		}
 	}
 
@@ -246,12 +248,14 @@ func contains(slice []string, item string) bool {
 // SetResourcePolicy 设置资源权限策略
 func (p *Permission) SetResourcePolicy(resource Resource, policy ResourcePolicy) {
 	p.mu.Lock()
-	defer p.mu.Unlock()
 
 	if p.Resources == nil {
 		p.Resources = make(map[Resource]ResourcePolicy)
 	}
 	p.Resources[resource] = policy
+	p.mu.Unlock()
+
+	p.notify()
 }
 
 // GetResourcePolicy 获取资源权限策略
@@ -266,17 +270,21 @@ func (p *Permission) GetResourcePolicy(resource Resource) (ResourcePolicy, bool)
 // Enable 启用权限检查
 func (p *Permission) Enable() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
 	p.Enabled = true
 	p.AllowAll = false
+	p.mu.Unlock()
+
+	p.notify()
 }
 
 // Disable 禁用权限检查
 func (p *Permission) Disable() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
 	p.Enabled = false
 	p.AllowAll = true
+	p.mu.Unlock()
+
+	p.notify()
 }
 
 // IsEnabled 检查权限系统是否启用
@@ -285,3 +293,49 @@ func (p *Permission) IsEnabled() bool {
 	defer p.mu.RUnlock()
 	return p.Enabled
 }
+
+// IsAllowAll 检查是否允许所有操作（未启用权限检查时同样允许所有操作）
+func (p *Permission) IsAllowAll() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return !p.Enabled || p.AllowAll
+}
+
+// Replace 用 other 的策略替换当前策略（例如重新加载配置文件后）
+// 持有同一个 Permission 实例的 adapter、API、MCP 服务器立即使用新策略
+func (p *Permission) Replace(other *Permission) {
+	other.mu.RLock()
+	enabled, allowAll := other.Enabled, other.AllowAll
+	resources := make(map[Resource]ResourcePolicy, len(other.Resources))
+	for resource, policy := range other.Resources {
+		resources[resource] = policy
+	}
+	other.mu.RUnlock()
+
+	p.mu.Lock()
+	p.Enabled = enabled
+	p.AllowAll = allowAll
+	p.Resources = resources
+	p.mu.Unlock()
+
+	p.notify()
+}
+
+// OnChange 注册策略变更回调（Replace、SetResourcePolicy、Enable、Disable 后调用）
+func (p *Permission) OnChange(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, fn)
+}
+
+// notify 调用所有策略变更回调（不持有锁，回调中可以读取策略）
+func (p *Permission) notify() {
+	p.mu.RLock()
+	listeners := make([]func(), len(p.listeners))
+	copy(listeners, p.listeners)
+	p.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn()
+	}
+}
@@ -160,3 +160,35 @@ func TestPermission_Check_BackwardCompatibility(t *testing.T) {
 		t.Errorf("向后兼容性测试失败，Check 方法返回错误: %v", err)
 	}
 }
+
+func TestPermission_ReplaceAndOnChange(t *testing.T) {
+	p := New(true, false, map[Resource]ResourcePolicy{
+		ResourceDept: {Read: true},
+	})
+
+	calls := 0
+	p.OnChange(func() {
+		calls++
+		// 回调中可以读取新策略
+		_ = p.Check(ResourceDept, ActionDelete)
+	})
+
+	if err := p.Check(ResourceDept, ActionDelete); err == nil {
+		t.Fatal("替换前不应允许删除部门")
+	}
+
+	p.Replace(New(true, false, map[Resource]ResourcePolicy{
+		ResourceDept: {Read: true, Delete: true},
+	}))
+	if err := p.Check(ResourceDept, ActionDelete); err != nil {
+		t.Errorf("替换后应允许删除部门: %v", err)
+	}
+
+	p.SetResourcePolicy(ResourceUser, ResourcePolicy{Read: true})
+	p.Disable()
+	p.Enable()
+
+	if calls != 4 {
+		t.Errorf("期望回调 4 次，实际 %d 次", calls)
+	}
+}
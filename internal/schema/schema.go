@@ -0,0 +1,335 @@
+// Package schema 根据 Go 类型生成 JSON Schema
+//
+// MCP 工具、REST endpoint 列表和 CLI 参数共用同一套生成逻辑，保证三者看到的
+// 类型、枚举、默认值和取值范围一致。字段信息来自 json 标签和 jsonschema 标签：
+//
+//	FileType string `json:"file_type" jsonschema:"description=文件类型,enum=image,enum=file,default=file"`
+//
+// jsonschema 标签支持的键：description、required、enum（可重复）、default、
+// minimum、maximum、minLength、maxLength、minItems、maxItems。
+// description 中可以包含逗号，无法识别为键的片段会拼接回 description。
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema JSON Schema（draft 2020-12 的子集）
+type Schema struct {
+	Type                 any                `json:"type,omitempty"` // string 或 []string（可为 null 时）
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// Field 结构体的一个顶层字段
+type Field struct {
+	Name     string       // JSON 字段名
+	Index    int          // 在结构体中的字段序号
+	Type     reflect.Type // Go 类型
+	Schema   *Schema      // 字段 schema
+	Required bool         // 是否必填
+}
+
+// Tag 解析后的 jsonschema 标签
+type Tag struct {
+	Description string
+	Required    bool
+	Enum        []string
+	Default     string
+	HasDefault  bool
+	Minimum     string
+	Maximum     string
+	MinLength   string
+	MaxLength   string
+	MinItems    string
+	MaxItems    string
+}
+
+// tagKeys jsonschema 标签中可识别的键
+var tagKeys = map[string]bool{
+	"description": true,
+	"enum":        true,
+	"default":     true,
+	"minimum":     true,
+	"maximum":     true,
+	"minLength":   true,
+	"maxLength":   true,
+	"minItems":    true,
+	"maxItems":    true,
+}
+
+// ParseTag 解析 jsonschema 标签
+func ParseTag(tag string) Tag {
+	var t Tag
+	last := ""
+	for _, part := range strings.Split(tag, ",") {
+		trimmed := strings.TrimSpace(part)
+		if trimmed == "required" {
+			t.Required = true
+			last = ""
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok || !tagKeys[key] {
+			// 不是键值对，属于上一个值（例如 description 中的逗号）
+			switch last {
+			case "description":
+				t.Description += "," + part
+			case "default":
+				t.Default += "," + part
+			}
+			continue
+		}
+
+		last = key
+		switch key {
+		case "description":
+			t.Description = value
+		case "enum":
+			t.Enum = append(t.Enum, value)
+		case "default":
+			t.Default = value
+			t.HasDefault = true
+		case "minimum":
+			t.Minimum = value
+		case "maximum":
+			t.Maximum = value
+		case "minLength":
+			t.MinLength = value
+		case "maxLength":
+			t.MaxLength = value
+		case "minItems":
+			t.MinItems = value
+		case "maxItems":
+			t.MaxItems = value
+		}
+	}
+	return t
+}
+
+// For 生成类型 t 的输入 schema（指针会被解引用）
+func For(t reflect.Type) *Schema {
+	return generate(t, false, map[reflect.Type]bool{})
+}
+
+// ForOutput 生成类型 t 的输出 schema
+// 输出中的切片、map 和指针序列化后可能为 null，因此声明为可为 null，
+// 且不包含 required，避免输出校验拒绝空结果。
+func ForOutput(t reflect.Type) *Schema {
+	return generate(t, true, map[reflect.Type]bool{})
+}
+
+// Fields 返回结构体类型 t 的顶层字段及其 schema
+func Fields(t reflect.Type) []Field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		tag := ParseTag(f.Tag.Get("jsonschema"))
+		fields = append(fields, Field{
+			Name:     name,
+			Index:    i,
+			Type:     f.Type,
+			Schema:   fieldSchema(f.Type, tag, false, map[reflect.Type]bool{}),
+			Required: tag.Required,
+		})
+	}
+	return fields
+}
+
+// generate 递归生成 schema，seen 用于防止递归类型无限展开
+func generate(t reflect.Type, output bool, seen map[reflect.Type]bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	s := &Schema{}
+	switch t.Kind() {
+	case reflect.Struct:
+		s.Type = "object"
+		if seen[t] {
+			return s
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s.Properties = map[string]*Schema{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := fieldName(f)
+			if !ok {
+				continue
+			}
+			tag := ParseTag(f.Tag.Get("jsonschema"))
+			s.Properties[name] = fieldSchema(f.Type, tag, output, seen)
+			if tag.Required && !output {
+				s.Required = append(s.Required, name)
+			}
+		}
+		// 根对象不可为 null
+		return s
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte 按 encoding/json 的约定序列化为 base64 字符串
+			s.Type = "string"
+		} else {
+			s.Type = "array"
+			s.Items = generate(t.Elem(), output, seen)
+		}
+		nullable = nullable || t.Kind() == reflect.Slice
+	case reflect.Map:
+		s.Type = "object"
+		s.AdditionalProperties = generate(t.Elem(), output, seen)
+		nullable = true
+	default:
+		s.Type = jsonType(t)
+	}
+
+	if output && nullable {
+		s.Type = []string{s.Type.(string), "null"}
+	}
+	return s
+}
+
+// fieldSchema 生成字段的 schema 并应用标签
+func fieldSchema(t reflect.Type, tag Tag, output bool, seen map[reflect.Type]bool) *Schema {
+	s := generate(t, output, seen)
+	s.Description = tag.Description
+
+	// 枚举、默认值和范围只用于描述输入
+	if output {
+		return s
+	}
+
+	base := t
+	for base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	target := s
+	valueType := base
+	if (base.Kind() == reflect.Slice || base.Kind() == reflect.Array) && s.Items != nil {
+		// 切片的枚举约束作用于元素
+		target = s.Items
+		valueType = base.Elem()
+	}
+
+	for _, e := range tag.Enum {
+		if v, err := convert(e, valueType); err == nil {
+			target.Enum = append(target.Enum, v)
+		}
+	}
+	if tag.HasDefault {
+		if v, err := convert(tag.Default, base); err == nil {
+			s.Default = v
+		}
+	}
+	s.Minimum = parseFloat(tag.Minimum)
+	s.Maximum = parseFloat(tag.Maximum)
+	s.MinLength = parseInt(tag.MinLength)
+	s.MaxLength = parseInt(tag.MaxLength)
+	s.MinItems = parseInt(tag.MinItems)
+	s.MaxItems = parseInt(tag.MaxItems)
+	return s
+}
+
+// jsonType 返回 Go 基本类型对应的 JSON 类型
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// convert 将标签中的字符串值转换为与类型 t 匹配的 JSON 值
+func convert(value string, t reflect.Type) (any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	default:
+		return nil, fmt.Errorf("不支持的默认值类型: %s", t)
+	}
+}
+
+// fieldName 返回导出字段的 JSON 名称
+func fieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	jsonTag := f.Tag.Get("json")
+	if jsonTag == "-" {
+		return "", false
+	}
+	if name := strings.Split(jsonTag, ",")[0]; name != "" {
+		return name, true
+	}
+	return f.Name, true
+}
+
+func parseFloat(s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+func parseInt(s string) *int {
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
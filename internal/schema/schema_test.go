@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/youdu-app-mcp/internal/adapter"
+)
+
+type nestedItem struct {
+	ID   int    `json:"id" jsonschema:"description=条目ID,minimum=1"`
+	Name string `json:"name"`
+}
+
+type nestedInput struct {
+	Kind   string            `json:"kind" jsonschema:"description=类型: a, b 或 c,enum=a,enum=b,enum=c,default=a"`
+	Count  int               `json:"count" jsonschema:"description=数量,minimum=0,maximum=10,default=1"`
+	Tags   []string          `json:"tags" jsonschema:"description=标签,minItems=1,maxItems=3,enum=x,enum=y"`
+	Items  []nestedItem      `json:"items" jsonschema:"description=条目,required"`
+	Labels map[string]string `json:"labels"`
+	Next   *nestedInput      `json:"next"`
+	hidden string
+}
+
+func TestParseTag(t *testing.T) {
+	tag := ParseTag("description=Type of file: image, file, voice,enum=image,enum=file,default=file,required")
+	if tag.Description != "Type of file: image, file, voice" {
+		t.Errorf("description = %q", tag.Description)
+	}
+	if !reflect.DeepEqual(tag.Enum, []string{"image", "file"}) {
+		t.Errorf("enum = %v", tag.Enum)
+	}
+	if !tag.HasDefault || tag.Default != "file" {
+		t.Errorf("default = %q (%v)", tag.Default, tag.HasDefault)
+	}
+	if !tag.Required {
+		t.Error("期望 required 为 true")
+	}
+}
+
+func TestFor(t *testing.T) {
+	s := For(reflect.TypeOf(nestedInput{}))
+
+	if s.Type != "object" {
+		t.Fatalf("期望根类型为 object，得到 %v", s.Type)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"items"}) {
+		t.Errorf("required = %v", s.Required)
+	}
+	if _, ok := s.Properties["hidden"]; ok {
+		t.Error("非导出字段不应出现在 schema 中")
+	}
+
+	kind := s.Properties["kind"]
+	if !reflect.DeepEqual(kind.Enum, []any{"a", "b", "c"}) || kind.Default != "a" {
+		t.Errorf("kind = %+v", kind)
+	}
+	if kind.Description != "类型: a, b 或 c" {
+		t.Errorf("kind.description = %q", kind.Description)
+	}
+
+	count := s.Properties["count"]
+	if count.Type != "integer" || count.Default != int64(1) || *count.Minimum != 0 || *count.Maximum != 10 {
+		t.Errorf("count = %+v", count)
+	}
+
+	tags := s.Properties["tags"]
+	if tags.Type != "array" || tags.Items.Type != "string" || *tags.MinItems != 1 || *tags.MaxItems != 3 {
+		t.Errorf("tags = %+v", tags)
+	}
+	if !reflect.DeepEqual(tags.Items.Enum, []any{"x", "y"}) {
+		t.Errorf("tags.items.enum = %v", tags.Items.Enum)
+	}
+
+	items := s.Properties["items"]
+	if items.Items == nil || items.Items.Type != "object" {
+		t.Fatalf("items = %+v", items)
+	}
+	id := items.Items.Properties["id"]
+	if id.Type != "integer" || *id.Minimum != 1 {
+		t.Errorf("items.id = %+v", id)
+	}
+
+	labels := s.Properties["labels"]
+	if labels.Type != "object" || labels.AdditionalProperties.Type != "string" {
+		t.Errorf("labels = %+v", labels)
+	}
+
+	// 递归类型不会无限展开
+	if next := s.Properties["next"]; next.Type != "object" || next.Properties != nil {
+		t.Errorf("next = %+v", next)
+	}
+}
+
+func TestForOutput(t *testing.T) {
+	s := ForOutput(reflect.TypeOf(&adapter.DeptListOutput{}))
+
+	if s.Type != "object" || len(s.Required) != 0 {
+		t.Fatalf("根 schema = %+v", s)
+	}
+	departments := s.Properties["departments"]
+	if !reflect.DeepEqual(departments.Type, []string{"array", "null"}) {
+		t.Errorf("departments.type = %v", departments.Type)
+	}
+	if departments.Items.Properties["name"].Type != "string" {
+		t.Errorf("departments.items = %+v", departments.Items)
+	}
+}
+
+func TestAdapterSchemas(t *testing.T) {
+	upload := For(reflect.TypeOf(adapter.UploadFileInput{}))
+	fileType := upload.Properties["file_type"]
+	if !reflect.DeepEqual(fileType.Enum, []any{"image", "file", "voice", "video"}) || fileType.Default != "file" {
+		t.Errorf("file_type = %+v", fileType)
+	}
+
+	session := For(reflect.TypeOf(adapter.CreateSessionInput{}))
+	if !reflect.DeepEqual(session.Properties["type"].Enum, []any{"single", "group"}) {
+		t.Errorf("type.enum = %v", session.Properties["type"].Enum)
+	}
+
+	// schema 可以序列化为 JSON
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+	if decoded["type"] != "object" {
+		t.Errorf("序列化结果不符合预期: %s", data)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   nestedInput
+		wantErr bool
+	}{
+		{"零值不校验", nestedInput{}, false},
+		{"合法值", nestedInput{Kind: "b", Count: 5, Tags: []string{"x"}}, false},
+		{"枚举不匹配", nestedInput{Kind: "d"}, true},
+		{"超出最大值", nestedInput{Count: 11}, true},
+		{"元素枚举不匹配", nestedInput{Tags: []string{"z"}}, true},
+		{"超出最大项数", nestedInput{Tags: []string{"x", "y", "x", "y"}}, true},
+		{"嵌套元素范围", nestedInput{Items: []nestedItem{{ID: -1}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Validate(&adapter.CreateSessionInput{Type: "multi"}); err == nil {
+		t.Error("期望非法会话类型返回错误")
+	}
+}
@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError 字段校验错误
+type FieldError struct {
+	Field   string // JSON 字段名
+	Message string // 错误说明
+	English string // 英文错误说明（MCP 工具的错误信息使用英文），为空时使用 Message
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("参数 %s %s", e.Field, e.Message)
+}
+
+// EnglishError 返回英文错误信息
+func (e *FieldError) EnglishError() string {
+	if e.English == "" {
+		return e.Error()
+	}
+	return fmt.Sprintf("argument %s %s", e.Field, e.English)
+}
+
+// InvalidArgument 标记为参数错误，apperr 据此归类为 invalid_argument
+func (e *FieldError) InvalidArgument() bool {
+	return true
+}
+
+// Validate 按 jsonschema 标签校验结构体 v 的顶层字段（枚举、取值范围、长度）
+// 零值字段视为未提供，不做校验；必填校验由调用方或下游负责。
+func Validate(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range Fields(value.Type()) {
+		fv := value.Field(field.Index)
+		if fv.IsZero() {
+			continue
+		}
+		if err := validateValue(field.Name, fv, field.Schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue 校验单个值
+func validateValue(name string, v reflect.Value, s *Schema) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if s.MinItems != nil && v.Len() < *s.MinItems {
+			return &FieldError{Field: name, Message: fmt.Sprintf("至少需要 %d 项", *s.MinItems), English: fmt.Sprintf("must have at least %d items", *s.MinItems)}
+		}
+		if s.MaxItems != nil && v.Len() > *s.MaxItems {
+			return &FieldError{Field: name, Message: fmt.Sprintf("最多 %d 项", *s.MaxItems), English: fmt.Sprintf("must have at most %d items", *s.MaxItems)}
+		}
+		if s.Items != nil {
+			for i := 0; i < v.Len(); i++ {
+				if err := validateValue(fmt.Sprintf("%s[%d]", name, i), v.Index(i), s.Items); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case reflect.Struct:
+		for _, field := range Fields(v.Type()) {
+			fv := v.Field(field.Index)
+			if fv.IsZero() {
+				continue
+			}
+			if err := validateValue(name+"."+field.Name, fv, field.Schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.String:
+		length := utf8.RuneCountInString(v.String())
+		if s.MinLength != nil && length < *s.MinLength {
+			return &FieldError{Field: name, Message: fmt.Sprintf("长度不能小于 %d", *s.MinLength), English: fmt.Sprintf("must be at least %d characters", *s.MinLength)}
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return &FieldError{Field: name, Message: fmt.Sprintf("长度不能大于 %d", *s.MaxLength), English: fmt.Sprintf("must be at most %d characters", *s.MaxLength)}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err := checkRange(name, float64(v.Int()), s); err != nil {
+			return err
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if err := checkRange(name, float64(v.Uint()), s); err != nil {
+			return err
+		}
+	case reflect.Float32, reflect.Float64:
+		if err := checkRange(name, v.Float(), s); err != nil {
+			return err
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		actual := fmt.Sprint(v.Interface())
+		allowed := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			allowed[i] = fmt.Sprint(e)
+			if allowed[i] == actual {
+				return nil
+			}
+		}
+		return &FieldError{Field: name, Message: fmt.Sprintf("必须是以下值之一: %s", strings.Join(allowed, ", ")), English: fmt.Sprintf("must be one of: %s", strings.Join(allowed, ", "))}
+	}
+	return nil
+}
+
+// checkRange 校验数值范围
+func checkRange(name string, n float64, s *Schema) error {
+	if s.Minimum != nil && n < *s.Minimum {
+		return &FieldError{Field: name, Message: fmt.Sprintf("不能小于 %v", *s.Minimum), English: fmt.Sprintf("must be at least %v", *s.Minimum)}
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		return &FieldError{Field: name, Message: fmt.Sprintf("不能大于 %v", *s.Maximum), English: fmt.Sprintf("must be at most %v", *s.Maximum)}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package token
+
+import (
+	"sync"
+	"time"
+)
+
+// versionCheckInterval 检查存储版本号的最小间隔
+// 本进程内的变更会立即使缓存失效；其他进程（例如 CLI 撤销 token）的变更最迟在该间隔后生效
+const versionCheckInterval = time.Second
+
+// validationCache token 验证缓存
+// 以存储的版本号判断缓存是否过期（SQLite 存储的版本号由 tokens 表上的触发器维护）
+type validationCache struct {
+	mu        sync.Mutex
+	entries   map[string]*Token // token value -> token（只缓存存在的 token，数量不超过存储中的 token 数）
+	count     int
+	hasCount  bool
+	version   int64
+	checkedAt time.Time
+	enabled   bool
+}
+
+// newValidationCache 创建验证缓存
+func newValidationCache() *validationCache {
+	return &validationCache{
+		entries: make(map[string]*Token),
+		version: -1,
+	}
+}
+
+// lookup 查找缓存的 token
+func (c *validationCache) lookup(tokenValue string) (*Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, found := c.entries[tokenValue]
+	return token, found
+}
+
+// store 缓存 token 查询结果
+func (c *validationCache) store(tokenValue string, token *Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[tokenValue] = token
+}
+
+// lookupCount 查找缓存的 token 数量
+func (c *validationCache) lookupCount() (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.count, c.hasCount
+}
+
+// storeCount 缓存 token 数量
+func (c *validationCache) storeCount(count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.count = count
+	c.hasCount = true
+}
+
+// reset 清空缓存，并要求下次使用前重新检查版本号
+func (c *validationCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*Token)
+	c.hasCount = false
+	c.checkedAt = time.Time{}
+}
+
+// Invalidate 使 token 缓存失效
+// 撤销、添加 token 时会自动调用
+func (m *Manager) Invalidate() {
+	m.cache.reset()
+}
+
+// syncCache 根据存储中的版本号同步缓存，返回缓存是否可用
+func (m *Manager) syncCache() bool {
+	c := m.cache
+
+	c.mu.Lock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < versionCheckInterval {
+		enabled := c.enabled
+		c.mu.Unlock()
+		return enabled
+	}
+	c.mu.Unlock()
+
+	version, err := m.store.Version()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 存储不支持版本号（例如数据库中没有版本表）时不使用缓存
+	if err != nil {
+		c.enabled = false
+		c.entries = make(map[string]*Token)
+		c.hasCount = false
+		c.checkedAt = time.Now()
+		return false
+	}
+
+	if version != c.version {
+		c.entries = make(map[string]*Token)
+		c.hasCount = false
+		c.version = version
+	}
+	c.enabled = true
+	c.checkedAt = time.Now()
+	return true
+}
@@ -0,0 +1,131 @@
+package token
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/database"
+)
+
+// setupStoreDB 使用完整的数据库结构（包含 token_state 版本表）创建测试数据库
+func setupStoreDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	db, err := database.New(database.Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("创建测试数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestManager_CacheInvalidatedOnRevoke(t *testing.T) {
+	db := setupStoreDB(t)
+	m := NewManager(db.GetConnection())
+
+	token, err := m.Generate("cached token", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	// 第一次验证会填充缓存
+	if !m.Validate(token.Value) {
+		t.Fatal("期望 token 有效")
+	}
+
+	if err := m.Revoke(token.Value); err != nil {
+		t.Fatalf("撤销 token 失败: %v", err)
+	}
+
+	// 撤销后立即失效
+	if m.Validate(token.Value) {
+		t.Error("期望撤销后的 token 立即无效")
+	}
+}
+
+func TestManager_CacheInvalidatedByOtherManager(t *testing.T) {
+	db := setupStoreDB(t)
+
+	// 两个 manager 共享同一个数据库，模拟 serve-api 和 CLI 两个进程
+	server := NewManager(db.GetConnection())
+	cli := NewManager(db.GetConnection())
+
+	if server.Count() != 0 {
+		t.Fatal("期望初始 token 数量为 0")
+	}
+
+	token, err := cli.Generate("cli token", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	// 版本号检查间隔过后，另一个 manager 的变更可见
+	time.Sleep(versionCheckInterval + 100*time.Millisecond)
+	if !server.AuthEnabled() {
+		t.Error("期望生成 token 后认证自动启用")
+	}
+	if !server.Validate(token.Value) {
+		t.Fatal("期望 token 有效")
+	}
+
+	if err := cli.RevokeByID(token.ID); err != nil {
+		t.Fatalf("撤销 token 失败: %v", err)
+	}
+
+	time.Sleep(versionCheckInterval + 100*time.Millisecond)
+	if server.Validate(token.Value) {
+		t.Error("期望另一个 manager 撤销后 token 无效")
+	}
+}
+
+func TestManager_CacheBoundedByStoredTokens(t *testing.T) {
+	db := setupStoreDB(t)
+	m := NewManager(db.GetConnection())
+
+	token, err := m.Generate("cached token", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+	if !m.Validate(token.Value) {
+		t.Fatal("期望 token 有效")
+	}
+
+	// 大量无效 token 不会进入缓存
+	for i := 0; i < 1000; i++ {
+		if m.Validate(fmt.Sprintf("invalid-%d", i)) {
+			t.Fatal("期望无效 token 验证失败")
+		}
+	}
+
+	m.cache.mu.Lock()
+	entries := len(m.cache.entries)
+	m.cache.mu.Unlock()
+	if entries != 1 {
+		t.Errorf("期望缓存只包含 1 个存在的 token，得到 %d 项", entries)
+	}
+}
+
+func TestManager_AuthEnabled(t *testing.T) {
+	db := setupStoreDB(t)
+	m := NewManager(db.GetConnection())
+
+	if m.AuthEnabled() {
+		t.Error("期望没有 token 时不需要认证")
+	}
+
+	m.SetRequired(true)
+	if !m.AuthEnabled() {
+		t.Error("期望强制模式下需要认证")
+	}
+
+	m.SetRequired(false)
+	if _, err := m.Generate("token", nil); err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+	if !m.AuthEnabled() {
+		t.Error("期望存在 token 时需要认证")
+	}
+}
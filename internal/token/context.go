@@ -0,0 +1,17 @@
+package token
+
+import "context"
+
+// contextKey 认证通过的 token 在请求 context 中的 key
+type contextKey struct{}
+
+// NewContext 返回携带已认证 token 的 context
+func NewContext(ctx context.Context, tok *Token) context.Context {
+	return context.WithValue(ctx, contextKey{}, tok)
+}
+
+// FromContext 返回 context 中已认证的 token
+func FromContext(ctx context.Context) (*Token, bool) {
+	tok, ok := ctx.Value(contextKey{}).(*Token)
+	return tok, ok && tok != nil
+}
@@ -0,0 +1,72 @@
+package token
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// HasNetworkRestriction 判断 token 是否限制了来源 IP
+func (t *Token) HasNetworkRestriction() bool {
+	return len(t.AllowedCIDRs) > 0
+}
+
+// AllowsIP 检查来源 IP 是否在 token 的 CIDR 允许列表中
+// 未配置允许列表的 token 不限制来源 IP
+func (t *Token) AllowsIP(ip net.IP) bool {
+	if !t.HasNetworkRestriction() {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range t.AllowedCIDRs {
+		network, err := parseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateCIDRs 检查 CIDR 允许列表格式是否正确
+// 支持 CIDR（10.0.0.0/8）和单个 IP 地址（10.0.0.5）
+func ValidateCIDRs(cidrs []string) error {
+	for _, cidr := range cidrs {
+		if _, err := parseCIDR(cidr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCIDR 解析 CIDR 或单个 IP 地址
+func parseCIDR(cidr string) (*net.IPNet, error) {
+	cidr = strings.TrimSpace(cidr)
+	if cidr == "" {
+		return nil, fmt.Errorf("CIDR 不能为空")
+	}
+
+	if strings.Contains(cidr, "/") {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 CIDR '%s': %w", cidr, err)
+		}
+		return network, nil
+	}
+
+	ip := net.ParseIP(cidr)
+	if ip == nil {
+		return nil, fmt.Errorf("无效的 IP 地址 '%s'", cidr)
+	}
+	bits := 32
+	if ip.To4() == nil {
+		bits = 128
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
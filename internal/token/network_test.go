@@ -0,0 +1,66 @@
+package token
+
+import (
+	"net"
+	"testing"
+)
+
+func TestToken_AllowsIP(t *testing.T) {
+	tok := &Token{AllowedCIDRs: []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"}}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"2001:db8::1", true},
+		{"172.16.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := tok.AllowsIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("AllowsIP(%s) = %v，期望 %v", tt.ip, got, tt.want)
+		}
+	}
+
+	// 未配置允许列表时不限制
+	if !(&Token{}).AllowsIP(net.ParseIP("8.8.8.8")) {
+		t.Error("期望未配置允许列表的 token 不限制来源 IP")
+	}
+}
+
+func TestValidateCIDRs(t *testing.T) {
+	if err := ValidateCIDRs([]string{"10.0.0.0/8", "127.0.0.1", "::1"}); err != nil {
+		t.Errorf("期望 CIDR 有效，得到错误: %v", err)
+	}
+
+	for _, cidr := range []string{"", "10.0.0.0/33", "not-an-ip"} {
+		if err := ValidateCIDRs([]string{cidr}); err == nil {
+			t.Errorf("期望 CIDR '%s' 无效", cidr)
+		}
+	}
+}
+
+func TestManager_GenerateWithAllowedCIDRs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	m := NewManager(db)
+
+	token, err := m.GenerateWithOptions("office token", nil, GenerateOptions{
+		AllowedCIDRs: []string{"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	stored, ok := m.Get(token.Value)
+	if !ok {
+		t.Fatal("期望 token 存在")
+	}
+	if len(stored.AllowedCIDRs) != 1 || stored.AllowedCIDRs[0] != "10.0.0.0/8" {
+		t.Errorf("期望 allowed_cidrs 为 [10.0.0.0/8]，得到 %v", stored.AllowedCIDRs)
+	}
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ScopeAll 允许访问所有 endpoint 的 scope
+const ScopeAll = "*"
+
+// scopeActionAliases 操作别名（例如 message:send 等价于 message:create）
+var scopeActionAliases = map[string]string{
+	"send": "create",
+}
+
+// HasScopes 判断 token 是否限制了 scope
+// 未配置任何 scope 的 token 可以访问所有 endpoint（向后兼容）
+func (t *Token) HasScopes() bool {
+	return len(t.Scopes) > 0
+}
+
+// Allows 检查 token 的 scope 是否允许访问指定 endpoint
+// endpoint: endpoint 名称（例如 send_text_message）
+// resource/action: endpoint 对应的资源和操作类型（未知时传空字符串）
+//
+// 支持的 scope 格式：
+//   - "*"：允许所有 endpoint
+//   - "resource:action"：例如 user:read、message:send、group:*
+//   - endpoint glob：例如 send_*、get_user
+func (t *Token) Allows(endpoint, resource, action string) bool {
+	if !t.HasScopes() {
+		return true
+	}
+
+	for _, scope := range t.Scopes {
+		if matchScope(scope, endpoint, resource, action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchScope 检查单个 scope 是否匹配
+func matchScope(scope, endpoint, resource, action string) bool {
+	scope = strings.TrimSpace(scope)
+	if scope == "" {
+		return false
+	}
+	if scope == ScopeAll {
+		return true
+	}
+
+	// resource:action 格式
+	if scopeResource, scopeAction, ok := strings.Cut(scope, ":"); ok {
+		if resource == "" || scopeResource != resource {
+			return false
+		}
+		if alias, exists := scopeActionAliases[scopeAction]; exists {
+			scopeAction = alias
+		}
+		return scopeAction == "*" || scopeAction == action
+	}
+
+	// endpoint glob 格式
+	matched, err := path.Match(scope, endpoint)
+	return err == nil && matched
+}
+
+// ValidateScopes 检查 scope 格式是否正确
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			return fmt.Errorf("scope 不能为空")
+		}
+		if scope == ScopeAll {
+			continue
+		}
+
+		if scopeResource, scopeAction, ok := strings.Cut(scope, ":"); ok {
+			if scopeResource == "" || scopeAction == "" {
+				return fmt.Errorf("无效的 scope '%s'：格式应为 resource:action", scope)
+			}
+			continue
+		}
+
+		if _, err := path.Match(scope, ""); err != nil {
+			return fmt.Errorf("无效的 scope '%s': %w", scope, err)
+		}
+	}
+	return nil
+}
+
+// parseList 解析数据库中以逗号分隔存储的列表（scope、CIDR）
+func parseList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// formatList 将列表（scope、CIDR）格式化为数据库存储格式
+func formatList(items []string) string {
+	return strings.Join(items, ",")
+}
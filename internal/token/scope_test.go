@@ -0,0 +1,81 @@
+package token
+
+import (
+	"testing"
+)
+
+func TestToken_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		endpoint string
+		resource string
+		action   string
+		want     bool
+	}{
+		{"无 scope 允许全部", nil, "delete_user", "user", "delete", true},
+		{"通配符", []string{"*"}, "delete_user", "user", "delete", true},
+		{"resource:action 匹配", []string{"user:read"}, "get_user", "user", "read", true},
+		{"resource:action 不匹配", []string{"user:read"}, "delete_user", "user", "delete", false},
+		{"message:send 别名", []string{"message:send"}, "send_text_message", "message", "create", true},
+		{"resource:* 匹配", []string{"group:*"}, "delete_group", "group", "delete", true},
+		{"endpoint glob 匹配", []string{"send_*"}, "send_sys_message", "message", "create", true},
+		{"endpoint glob 不匹配", []string{"send_*"}, "delete_user", "user", "delete", false},
+		{"多个 scope", []string{"user:read", "send_*"}, "send_text_message", "message", "create", true},
+		{"未知资源不匹配 resource:action", []string{"user:read"}, "unknown", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok := &Token{Scopes: tt.scopes}
+			if got := tok.Allows(tt.endpoint, tt.resource, tt.action); got != tt.want {
+				t.Errorf("Allows(%s) = %v，期望 %v", tt.endpoint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateScopes(t *testing.T) {
+	if err := ValidateScopes([]string{"*", "user:read", "send_*"}); err != nil {
+		t.Errorf("期望 scope 有效，得到错误: %v", err)
+	}
+
+	invalid := [][]string{
+		{""},
+		{"user:"},
+		{":read"},
+		{"send_["},
+	}
+	for _, scopes := range invalid {
+		if err := ValidateScopes(scopes); err == nil {
+			t.Errorf("期望 scope %v 无效", scopes)
+		}
+	}
+}
+
+func TestManager_GenerateWithScopes(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	m := NewManager(db)
+
+	token, err := m.GenerateWithOptions("ci token", nil, GenerateOptions{
+		Scopes: []string{"message:send", "user:read"},
+	})
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	stored, ok := m.Get(token.Value)
+	if !ok {
+		t.Fatal("期望 token 存在")
+	}
+
+	if len(stored.Scopes) != 2 || stored.Scopes[0] != "message:send" || stored.Scopes[1] != "user:read" {
+		t.Errorf("期望 scopes 为 [message:send user:read]，得到 %v", stored.Scopes)
+	}
+
+	if _, err := m.GenerateWithOptions("bad token", nil, GenerateOptions{Scopes: []string{"user:"}}); err == nil {
+		t.Error("期望无效 scope 返回错误")
+	}
+}
@@ -0,0 +1,74 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 认证方式
+const (
+	AuthModeBearer = "bearer" // 在 Authorization header 中直接携带 token
+	AuthModeHMAC   = "hmac"   // 使用 token 值作为密钥对请求签名，token 本身不在请求中传输
+)
+
+// 签名请求使用的 header
+const (
+	HeaderKeyID     = "X-Youdu-Key-Id"    // token ID
+	HeaderTimestamp = "X-Youdu-Timestamp" // Unix 时间戳（秒）
+	HeaderNonce     = "X-Youdu-Nonce"     // 随机数，每个请求唯一
+	HeaderSignature = "X-Youdu-Signature" // 十六进制编码的 HMAC-SHA256 签名
+)
+
+// SignsRequests 判断 token 是否使用签名认证
+func (t *Token) SignsRequests() bool {
+	return t.AuthMode == AuthModeHMAC
+}
+
+// ValidateAuthMode 检查认证方式是否有效（空字符串视为 bearer）
+func ValidateAuthMode(mode string) error {
+	switch mode {
+	case "", AuthModeBearer, AuthModeHMAC:
+		return nil
+	default:
+		return fmt.Errorf("无效的认证方式 '%s'：可选值为 %s、%s", mode, AuthModeBearer, AuthModeHMAC)
+	}
+}
+
+// SignRequest 计算请求签名
+// 签名内容依次为 method、path（包含查询参数）、timestamp、nonce 和 body 的 SHA256，以换行符连接：
+//
+//	HMAC-SHA256(secret, METHOD \n PATH \n TIMESTAMP \n NONCE \n hex(SHA256(BODY)))
+func SignRequest(secret, method, path string, timestamp int64, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	payload := strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature 使用 token 值作为密钥校验请求签名（常量时间比较）
+func (t *Token) VerifySignature(signature, method, path string, timestamp int64, nonce string, body []byte) bool {
+	expected := SignRequest(t.Value, method, path, timestamp, nonce, body)
+	return hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected))
+}
+
+// UseNonce 记录签名请求使用的 nonce，同一 token 的 nonce 重复使用时返回错误（防重放）
+// ttl 为 nonce 的保留时长，过期记录会被清理；应不小于允许的时钟偏差的两倍
+func (m *Manager) UseNonce(tokenID, nonce string, ttl time.Duration) error {
+	if nonce == "" {
+		return fmt.Errorf("nonce 不能为空")
+	}
+	return m.store.UseNonce(tokenID, nonce, ttl)
+}
@@ -0,0 +1,64 @@
+package token
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToken_VerifySignature(t *testing.T) {
+	tok := &Token{Value: "secret-value", AuthMode: AuthModeHMAC}
+	body := []byte(`{"to_user":"10232","content":"hi"}`)
+	ts := time.Now().Unix()
+
+	signature := SignRequest(tok.Value, "POST", "/api/v1/send_text_message", ts, "nonce-1", body)
+
+	if !tok.VerifySignature(signature, "POST", "/api/v1/send_text_message", ts, "nonce-1", body) {
+		t.Error("期望签名有效")
+	}
+
+	// 任意签名内容被篡改都应该校验失败
+	if tok.VerifySignature(signature, "POST", "/api/v1/delete_user", ts, "nonce-1", body) {
+		t.Error("期望 path 被篡改后签名无效")
+	}
+	if tok.VerifySignature(signature, "POST", "/api/v1/send_text_message", ts+1, "nonce-1", body) {
+		t.Error("期望 timestamp 被篡改后签名无效")
+	}
+	if tok.VerifySignature(signature, "POST", "/api/v1/send_text_message", ts, "nonce-1", []byte(`{}`)) {
+		t.Error("期望 body 被篡改后签名无效")
+	}
+
+	other := &Token{Value: "other-secret"}
+	if other.VerifySignature(signature, "POST", "/api/v1/send_text_message", ts, "nonce-1", body) {
+		t.Error("期望使用其他密钥时签名无效")
+	}
+}
+
+func TestValidateAuthMode(t *testing.T) {
+	for _, mode := range []string{"", AuthModeBearer, AuthModeHMAC} {
+		if err := ValidateAuthMode(mode); err != nil {
+			t.Errorf("期望认证方式 '%s' 有效，得到错误: %v", mode, err)
+		}
+	}
+	if err := ValidateAuthMode("basic"); err == nil {
+		t.Error("期望认证方式 'basic' 无效")
+	}
+}
+
+func TestManager_UseNonce(t *testing.T) {
+	db := setupStoreDB(t)
+	m := NewManager(db.GetConnection())
+
+	if err := m.UseNonce("token1", "nonce-1", time.Minute); err != nil {
+		t.Fatalf("期望首次使用 nonce 成功，得到错误: %v", err)
+	}
+
+	// 重放
+	if err := m.UseNonce("token1", "nonce-1", time.Minute); err == nil {
+		t.Error("期望重复使用 nonce 返回错误")
+	}
+
+	// 不同 token 的 nonce 互不影响
+	if err := m.UseNonce("token2", "nonce-1", time.Minute); err != nil {
+		t.Errorf("期望不同 token 可以使用相同 nonce，得到错误: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package token
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound token 不存在
+var ErrNotFound = errors.New("token 不存在")
+
+// Store token 存储
+// Manager 通过 Store 读写 token，负责校验、缓存等逻辑；Store 只负责持久化
+type Store interface {
+	// Save 保存 token（ID 相同时覆盖）
+	Save(token *Token) error
+	// GetByValue 通过 value 获取 token，不存在时返回 ErrNotFound
+	GetByValue(value string) (*Token, error)
+	// GetByID 通过 ID 获取 token，不存在时返回 ErrNotFound
+	GetByID(id string) (*Token, error)
+	// List 按创建时间倒序列出所有 token
+	List() ([]*Token, error)
+	// DeleteByValue 通过 value 删除 token，返回是否存在
+	DeleteByValue(value string) (bool, error)
+	// DeleteByID 通过 ID 删除 token，返回是否存在
+	DeleteByID(id string) (bool, error)
+	// Clear 删除所有 token
+	Clear() error
+	// Count 返回 token 数量
+	Count() (int, error)
+	// Version 返回存储的版本号，token 发生任何变更（包括其他进程的变更）时递增
+	// 返回错误时 Manager 不使用缓存
+	Version() (int64, error)
+	// UseNonce 记录签名请求使用的 nonce，同一 token 的 nonce 重复使用时返回错误，并清理超过 ttl 的记录
+	UseNonce(tokenID, nonce string, ttl time.Duration) error
+}
@@ -0,0 +1,179 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore 内存 token 存储
+// 数据不会持久化，适用于测试和未配置数据库的场景
+type MemoryStore struct {
+	mu      sync.Mutex
+	tokens  map[string]*Token               // token ID -> token
+	nonces  map[string]map[string]time.Time // token ID -> nonce -> 使用时间
+	version int64
+}
+
+// NewMemoryStore 创建内存 token 存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens: make(map[string]*Token),
+		nonces: make(map[string]map[string]time.Time),
+	}
+}
+
+// Save 保存 token（ID 相同时覆盖）
+func (s *MemoryStore) Save(token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 与数据库的唯一约束保持一致：不同 ID 的 token 不能使用相同的 value
+	for id, existing := range s.tokens {
+		if id != token.ID && existing.Value == token.Value {
+			return fmt.Errorf("token value 已存在")
+		}
+	}
+
+	s.tokens[token.ID] = copyToken(token)
+	s.version++
+	return nil
+}
+
+// GetByValue 通过 value 获取 token
+func (s *MemoryStore) GetByValue(value string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, token := range s.tokens {
+		if token.Value == value {
+			return copyToken(token), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// GetByID 通过 ID 获取 token
+func (s *MemoryStore) GetByID(id string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, exists := s.tokens[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyToken(token), nil
+}
+
+// List 按创建时间倒序列出所有 token
+func (s *MemoryStore) List() ([]*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := make([]*Token, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		tokens = append(tokens, copyToken(token))
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// DeleteByValue 通过 value 删除 token
+func (s *MemoryStore) DeleteByValue(value string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, token := range s.tokens {
+		if token.Value == value {
+			delete(s.tokens, id)
+			s.version++
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteByID 通过 ID 删除 token
+func (s *MemoryStore) DeleteByID(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tokens[id]; !exists {
+		return false, nil
+	}
+	delete(s.tokens, id)
+	s.version++
+	return true, nil
+}
+
+// Clear 删除所有 token
+func (s *MemoryStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens = make(map[string]*Token)
+	s.version++
+	return nil
+}
+
+// Count 返回 token 数量
+func (s *MemoryStore) Count() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.tokens), nil
+}
+
+// Version 返回存储的版本号
+func (s *MemoryStore) Version() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.version, nil
+}
+
+// UseNonce 记录签名请求使用的 nonce
+func (s *MemoryStore) UseNonce(tokenID, nonce string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// 清理过期的 nonce
+	for id, nonces := range s.nonces {
+		for n, usedAt := range nonces {
+			if now.Sub(usedAt) > ttl {
+				delete(nonces, n)
+			}
+		}
+		if len(nonces) == 0 {
+			delete(s.nonces, id)
+		}
+	}
+
+	nonces, exists := s.nonces[tokenID]
+	if !exists {
+		nonces = make(map[string]time.Time)
+		s.nonces[tokenID] = nonces
+	}
+	if _, used := nonces[nonce]; used {
+		return fmt.Errorf("nonce 已被使用（疑似重放请求）")
+	}
+	nonces[nonce] = now
+	return nil
+}
+
+// copyToken 复制 token，避免调用方修改存储中的数据
+func copyToken(token *Token) *Token {
+	c := *token
+	if token.ExpiresAt != nil {
+		expiresAt := *token.ExpiresAt
+		c.ExpiresAt = &expiresAt
+	}
+	c.Scopes = append([]string(nil), token.Scopes...)
+	c.AllowedCIDRs = append([]string(nil), token.AllowedCIDRs...)
+	return &c
+}
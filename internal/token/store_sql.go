@@ -0,0 +1,277 @@
+package token
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// valueColumn token 值所在列（加密时作为列标识）
+const valueColumn = "tokens.value"
+
+// ValueCipher 列值加解密（database.DB 实现）
+type ValueCipher interface {
+	EncryptValue(column, plaintext string) (string, error)
+	DecryptValue(column, value string) (string, error)
+}
+
+// SQLStore 基于 SQLite 的 token 存储
+// 表结构由 database 包的迁移创建；token 值通过 value_hash 查找，配置 cipher 后加密存储
+type SQLStore struct {
+	db     *sql.DB
+	cipher ValueCipher
+}
+
+// NewSQLStore 创建 SQLite token 存储（token 值明文存储）
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// NewEncryptedSQLStore 创建 token 值加密存储的 SQLite token 存储
+func NewEncryptedSQLStore(db *sql.DB, cipher ValueCipher) *SQLStore {
+	return &SQLStore{db: db, cipher: cipher}
+}
+
+// Save 保存 token（ID 相同时覆盖）
+func (s *SQLStore) Save(token *Token) error {
+	var expiresAt interface{}
+	if token.ExpiresAt != nil {
+		// 格式化为 UTC 时间字符串，便于 SQLite 处理
+		expiresAt = token.ExpiresAt.UTC().Format("2006-01-02 15:04:05")
+	}
+
+	// 同样格式化 created_at 时间
+	createdAt := token.CreatedAt.UTC().Format("2006-01-02 15:04:05")
+
+	value := token.Value
+	if s.cipher != nil {
+		encrypted, err := s.cipher.EncryptValue(valueColumn, token.Value)
+		if err != nil {
+			return err
+		}
+		value = encrypted
+	}
+
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO tokens (id, value, value_hash, description, created_at, expires_at, scopes, allowed_cidrs, auth_mode)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, token.ID, value, hashValue(token.Value), token.Description, createdAt, expiresAt,
+		formatList(token.Scopes), formatList(token.AllowedCIDRs), token.AuthMode)
+	return err
+}
+
+// GetByValue 通过 value 获取 token
+func (s *SQLStore) GetByValue(value string) (*Token, error) {
+	return s.getOne(`WHERE value_hash = ?`, hashValue(value))
+}
+
+// GetByID 通过 ID 获取 token
+func (s *SQLStore) GetByID(id string) (*Token, error) {
+	return s.getOne(`WHERE id = ?`, id)
+}
+
+// getOne 按条件查询单个 token
+func (s *SQLStore) getOne(where string, arg interface{}) (*Token, error) {
+	token, err := scanToken(s.db.QueryRow(`SELECT `+tokenColumns+` FROM tokens `+where, arg))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询 token 失败: %w", err)
+	}
+	if err := s.decrypt(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// decrypt 解密 token 值
+func (s *SQLStore) decrypt(token *Token) error {
+	if s.cipher == nil {
+		return nil
+	}
+	value, err := s.cipher.DecryptValue(valueColumn, token.Value)
+	if err != nil {
+		return fmt.Errorf("解密 token %s 失败: %w", token.ID, err)
+	}
+	token.Value = value
+	return nil
+}
+
+// List 按创建时间倒序列出所有 token
+func (s *SQLStore) List() ([]*Token, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + tokenColumns + `
+		FROM tokens
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("查询 token 列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*Token
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取 token 失败: %w", err)
+		}
+		if err := s.decrypt(token); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// DeleteByValue 通过 value 删除 token
+func (s *SQLStore) DeleteByValue(value string) (bool, error) {
+	return s.delete(`DELETE FROM tokens WHERE value_hash = ?`, hashValue(value))
+}
+
+// DeleteByID 通过 ID 删除 token
+func (s *SQLStore) DeleteByID(id string) (bool, error) {
+	return s.delete(`DELETE FROM tokens WHERE id = ?`, id)
+}
+
+// delete 执行删除语句，返回是否删除了记录
+func (s *SQLStore) delete(query string, arg interface{}) (bool, error) {
+	result, err := s.db.Exec(query, arg)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("检查删除结果失败: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Clear 删除所有 token
+func (s *SQLStore) Clear() error {
+	_, err := s.db.Exec(`DELETE FROM tokens`)
+	return err
+}
+
+// Count 返回 token 数量
+func (s *SQLStore) Count() (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM tokens`).Scan(&count)
+	return count, err
+}
+
+// Version 返回 token_state 表中的版本号（由 tokens 表上的触发器维护）
+func (s *SQLStore) Version() (int64, error) {
+	var version int64
+	err := s.db.QueryRow(`SELECT version FROM token_state WHERE id = 1`).Scan(&version)
+	return version, err
+}
+
+// UseNonce 记录签名请求使用的 nonce
+func (s *SQLStore) UseNonce(tokenID, nonce string, ttl time.Duration) error {
+	now := time.Now().UTC()
+
+	// 清理过期的 nonce
+	if _, err := s.db.Exec(`DELETE FROM auth_nonces WHERE created_at < ?`, now.Add(-ttl).Unix()); err != nil {
+		return fmt.Errorf("清理过期 nonce 失败: %w", err)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT OR IGNORE INTO auth_nonces (token_id, nonce, created_at)
+		VALUES (?, ?, ?)
+	`, tokenID, nonce, now.Unix())
+	if err != nil {
+		return fmt.Errorf("记录 nonce 失败: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("检查 nonce 记录结果失败: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("nonce 已被使用（疑似重放请求）")
+	}
+
+	return nil
+}
+
+// tokenColumns tokens 表查询列（顺序与 scanToken 一致）
+const tokenColumns = "id, value, description, created_at, expires_at, scopes, allowed_cidrs, auth_mode"
+
+// rowScanner 抽象 *sql.Row 和 *sql.Rows 的 Scan 方法
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanToken 从查询结果中读取 token
+func scanToken(row rowScanner) (*Token, error) {
+	var token Token
+	var createdAtStr, expiresAtStr, scopesStr, cidrsStr, authModeStr sql.NullString
+
+	err := row.Scan(
+		&token.ID,
+		&token.Value,
+		&token.Description,
+		&createdAtStr,
+		&expiresAtStr,
+		&scopesStr,
+		&cidrsStr,
+		&authModeStr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析创建时间
+	if createdAtStr.Valid {
+		if parsedTime, err := parseDBTime(createdAtStr.String); err == nil {
+			token.CreatedAt = parsedTime
+		}
+	}
+
+	// 解析过期时间
+	if expiresAtStr.Valid {
+		parsedTime, err := parseDBTime(expiresAtStr.String)
+		if err != nil {
+			// 无法解析的过期时间视为已过期，避免 token 被当作永久有效
+			parsedTime = time.Time{}
+		}
+		token.ExpiresAt = &parsedTime
+	}
+
+	// 解析访问范围和来源 IP 允许列表
+	token.Scopes = parseList(scopesStr.String)
+	token.AllowedCIDRs = parseList(cidrsStr.String)
+
+	// 解析认证方式
+	token.AuthMode = authModeStr.String
+	if token.AuthMode == "" {
+		token.AuthMode = AuthModeBearer
+	}
+
+	return &token, nil
+}
+
+// hashValue 计算 token 值的查找哈希 hex(SHA256(value))
+// token 值是高熵随机串，不需要加盐；数据库迁移回填 value_hash 时使用相同算法
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseDBTime 解析数据库中的时间（UTC）
+// 支持 "2006-01-02 15:04:05" 和 RFC3339 两种格式
+func parseDBTime(value string) (time.Time, error) {
+	if parsedTime, err := time.Parse("2006-01-02 15:04:05", value); err == nil {
+		return parsedTime.UTC(), nil
+	}
+	parsedTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsedTime.UTC(), nil
+}
@@ -0,0 +1,198 @@
+package token
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/youdu-app-mcp/internal/database"
+)
+
+// testStores 返回需要测试的所有存储实现
+func testStores(t *testing.T) map[string]Store {
+	db := setupStoreDB(t)
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"sqlite": NewSQLStore(db.GetConnection()),
+	}
+}
+
+func TestStore(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+			first := &Token{
+				ID:           "id-1",
+				Value:        "value-1",
+				Description:  "first",
+				CreatedAt:    time.Now().Add(-time.Minute).UTC().Truncate(time.Second),
+				ExpiresAt:    &expiresAt,
+				Scopes:       []string{"user:read"},
+				AllowedCIDRs: []string{"10.0.0.0/8"},
+				AuthMode:     AuthModeHMAC,
+			}
+			second := &Token{ID: "id-2", Value: "value-2", Description: "second", CreatedAt: time.Now().UTC().Truncate(time.Second), AuthMode: AuthModeBearer}
+
+			version, err := store.Version()
+			if err != nil {
+				t.Fatalf("读取版本号失败: %v", err)
+			}
+
+			for _, tok := range []*Token{first, second} {
+				if err := store.Save(tok); err != nil {
+					t.Fatalf("保存 token 失败: %v", err)
+				}
+			}
+
+			if newVersion, _ := store.Version(); newVersion <= version {
+				t.Errorf("期望保存后版本号递增，之前 %d，之后 %d", version, newVersion)
+			}
+
+			got, err := store.GetByValue("value-1")
+			if err != nil {
+				t.Fatalf("通过 value 获取 token 失败: %v", err)
+			}
+			if got.ID != first.ID || got.AuthMode != AuthModeHMAC || len(got.Scopes) != 1 || len(got.AllowedCIDRs) != 1 {
+				t.Errorf("获取的 token 与保存的不一致: %+v", got)
+			}
+			if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) {
+				t.Errorf("期望过期时间 %v，得到 %v", expiresAt, got.ExpiresAt)
+			}
+
+			if _, err := store.GetByID("missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("期望 ErrNotFound，得到 %v", err)
+			}
+
+			list, err := store.List()
+			if err != nil {
+				t.Fatalf("列出 token 失败: %v", err)
+			}
+			if len(list) != 2 || list[0].ID != "id-2" {
+				t.Errorf("期望按创建时间倒序列出 2 个 token，得到 %d 个", len(list))
+			}
+
+			if count, _ := store.Count(); count != 2 {
+				t.Errorf("期望 2 个 token，得到 %d", count)
+			}
+
+			if deleted, err := store.DeleteByValue("value-1"); err != nil || !deleted {
+				t.Errorf("期望删除成功，得到 deleted=%v err=%v", deleted, err)
+			}
+			if deleted, _ := store.DeleteByID("id-1"); deleted {
+				t.Error("期望删除不存在的 token 返回 false")
+			}
+
+			if err := store.Clear(); err != nil {
+				t.Fatalf("清除 token 失败: %v", err)
+			}
+			if count, _ := store.Count(); count != 0 {
+				t.Errorf("期望清除后没有 token，得到 %d", count)
+			}
+
+			if err := store.UseNonce("id-1", "nonce", time.Minute); err != nil {
+				t.Fatalf("期望首次使用 nonce 成功: %v", err)
+			}
+			if err := store.UseNonce("id-1", "nonce", time.Minute); err == nil {
+				t.Error("期望重复使用 nonce 返回错误")
+			}
+		})
+	}
+}
+
+func TestManager_MemoryStore(t *testing.T) {
+	m := NewManager(nil)
+
+	token, err := m.Generate("memory token", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	if !m.Validate(token.Value) {
+		t.Error("期望内存存储中的 token 有效")
+	}
+	if !m.AuthEnabled() {
+		t.Error("期望存在 token 时启用认证")
+	}
+
+	if err := m.RevokeByID(token.ID); err != nil {
+		t.Fatalf("撤销 token 失败: %v", err)
+	}
+	if m.Validate(token.Value) {
+		t.Error("期望撤销后 token 无效")
+	}
+}
+
+func TestManager_SetEnabled(t *testing.T) {
+	m := NewManager(nil)
+	m.SetEnabled(false)
+
+	if _, err := m.Generate("disabled", nil); err != nil {
+		t.Fatalf("禁用认证时也应可以生成 token: %v", err)
+	}
+	if m.AuthEnabled() {
+		t.Error("期望禁用时不需要认证")
+	}
+
+	m.SetRequired(true)
+	if !m.AuthEnabled() {
+		t.Error("期望强制模式下始终需要认证")
+	}
+}
+
+func TestEncryptedSQLStore(t *testing.T) {
+	key := make([]byte, 32)
+	t.Setenv(database.EncryptionKeyEnv, base64.StdEncoding.EncodeToString(key))
+
+	db := setupStoreDB(t)
+	m := NewManagerWithStore(NewEncryptedSQLStore(db.GetConnection(), db))
+
+	token, err := m.Generate("encrypted", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	var stored string
+	if err := db.GetConnection().QueryRow(`SELECT value FROM tokens WHERE id = ?`, token.ID).Scan(&stored); err != nil {
+		t.Fatalf("查询 token 失败: %v", err)
+	}
+	if stored == token.Value {
+		t.Error("期望 token 值加密存储")
+	}
+
+	if !m.Validate(token.Value) {
+		t.Error("期望通过明文 token 值验证成功")
+	}
+	if got, ok := m.GetByID(token.ID); !ok || got.Value != token.Value {
+		t.Error("期望读取时解密 token 值")
+	}
+	if err := m.Revoke(token.Value); err != nil {
+		t.Errorf("期望通过明文 token 值撤销成功: %v", err)
+	}
+}
+
+func TestEncryptedSQLStore_ListDecryptError(t *testing.T) {
+	key := make([]byte, 32)
+	t.Setenv(database.EncryptionKeyEnv, base64.StdEncoding.EncodeToString(key))
+
+	db := setupStoreDB(t)
+	m := NewManagerWithStore(NewEncryptedSQLStore(db.GetConnection(), db))
+
+	token, err := m.Generate("encrypted", nil)
+	if err != nil {
+		t.Fatalf("生成 token 失败: %v", err)
+	}
+
+	// 损坏加密的 token 值：列表返回错误，而不是悄悄跳过该 token
+	var stored string
+	if err := db.GetConnection().QueryRow(`SELECT value FROM tokens WHERE id = ?`, token.ID).Scan(&stored); err != nil {
+		t.Fatalf("查询 token 失败: %v", err)
+	}
+	if _, err := db.GetConnection().Exec(`UPDATE tokens SET value = ? WHERE id = ?`, stored[:len(stored)-4], token.ID); err != nil {
+		t.Fatalf("更新 token 失败: %v", err)
+	}
+
+	if tokens, err := m.List(); err == nil {
+		t.Errorf("期望返回解密错误，得到 %d 个 token", len(tokens))
+	}
+}
@@ -10,28 +10,96 @@ import (
 
 // Token 代表一个访问令牌
 type Token struct {
-	ID          string     `json:"id" yaml:"id"`                                     // Token ID
-	Value       string     `json:"value" yaml:"value"`                               // Token 值
-	Description string     `json:"description" yaml:"description"`                   // 描述
-	CreatedAt   time.Time  `json:"created_at" yaml:"created_at"`                     // 创建时间
-	ExpiresAt   *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"` // 过期时间 (可选)
+	ID           string     `json:"id" yaml:"id"`                                           // Token ID
+	Value        string     `json:"value" yaml:"value"`                                     // Token 值
+	Description  string     `json:"description" yaml:"description"`                         // 描述
+	CreatedAt    time.Time  `json:"created_at" yaml:"created_at"`                           // 创建时间
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`       // 过期时间 (可选)
+	Scopes       []string   `json:"scopes,omitempty" yaml:"scopes,omitempty"`               // 访问范围 (可选，为空表示不限制)
+	AllowedCIDRs []string   `json:"allowed_cidrs,omitempty" yaml:"allowed_cidrs,omitempty"` // 来源 IP 允许列表 (可选，为空表示不限制)
+	AuthMode     string     `json:"auth_mode,omitempty" yaml:"auth_mode,omitempty"`         // 认证方式: bearer (默认) 或 hmac
+}
+
+// GenerateOptions 生成 token 时的可选参数
+type GenerateOptions struct {
+	Scopes       []string // 访问范围，例如 message:send、user:read、send_*
+	AllowedCIDRs []string // 来源 IP 允许列表，例如 10.0.0.0/8、192.168.1.10
+	AuthMode     string   // 认证方式: bearer (默认) 或 hmac
 }
 
 // Manager 管理所有 token
 type Manager struct {
-	db *sql.DB // SQLite 数据库连接
+	store    Store            // token 存储
+	cache    *validationCache // token 验证缓存
+	required bool             // 是否强制要求 token 认证
+	disabled bool             // 是否禁用 token 认证（token 仍可管理）
 }
 
-// NewManager 创建新的 token 管理器
-// db 参数可以为 nil，此时将使用内存模式（仅用于测试）
+// NewManager 创建使用 SQLite 存储的 token 管理器
+// db 为 nil 时使用内存存储（不持久化）
 func NewManager(db *sql.DB) *Manager {
+	if db == nil {
+		return NewManagerWithStore(NewMemoryStore())
+	}
+	return NewManagerWithStore(NewSQLStore(db))
+}
+
+// NewManagerWithStore 创建使用指定存储的 token 管理器
+func NewManagerWithStore(store Store) *Manager {
 	return &Manager{
-		db: db,
+		store: store,
+		cache: newValidationCache(),
+	}
+}
+
+// SetEnabled 设置是否启用 token 认证
+// 禁用时 AuthEnabled 返回 false（强制模式除外），但仍可以生成、列出和撤销 token
+func (m *Manager) SetEnabled(enabled bool) {
+	m.disabled = !enabled
+}
+
+// Enabled 返回是否启用 token 认证
+func (m *Manager) Enabled() bool {
+	return !m.disabled
+}
+
+// SetRequired 设置是否强制要求 token 认证
+// 强制模式下即使没有任何 token 也会拒绝未认证的请求
+func (m *Manager) SetRequired(required bool) {
+	m.required = required
+}
+
+// Required 返回是否强制要求 token 认证
+func (m *Manager) Required() bool {
+	return m.required
+}
+
+// AuthEnabled 判断当前是否需要 token 认证
+// 强制模式下始终需要；已启用时只要存储中存在 token 即需要（每次调用时从存储读取）
+func (m *Manager) AuthEnabled() bool {
+	if m.required {
+		return true
 	}
+	return !m.disabled && m.Count() > 0
 }
 
 // Generate 生成新的 token
 func (m *Manager) Generate(description string, expiresIn *time.Duration) (*Token, error) {
+	return m.GenerateWithOptions(description, expiresIn, GenerateOptions{})
+}
+
+// GenerateWithOptions 生成带可选参数（scope 等）的新 token
+func (m *Manager) GenerateWithOptions(description string, expiresIn *time.Duration, opts GenerateOptions) (*Token, error) {
+	if err := ValidateScopes(opts.Scopes); err != nil {
+		return nil, err
+	}
+	if err := ValidateCIDRs(opts.AllowedCIDRs); err != nil {
+		return nil, err
+	}
+	if err := ValidateAuthMode(opts.AuthMode); err != nil {
+		return nil, err
+	}
+
 	// 生成随机 token
 	tokenValue, err := generateRandomToken(32)
 	if err != nil {
@@ -45,10 +113,13 @@ func (m *Manager) Generate(description string, expiresIn *time.Duration) (*Token
 	}
 
 	token := &Token{
-		ID:          tokenID,
-		Value:       tokenValue,
-		Description: description,
-		CreatedAt:   time.Now(),
+		ID:           tokenID,
+		Value:        tokenValue,
+		Description:  description,
+		CreatedAt:    time.Now(),
+		Scopes:       opts.Scopes,
+		AllowedCIDRs: opts.AllowedCIDRs,
+		AuthMode:     opts.AuthMode,
 	}
 
 	// 设置过期时间
@@ -57,11 +128,13 @@ func (m *Manager) Generate(description string, expiresIn *time.Duration) (*Token
 		token.ExpiresAt = &expiresAt
 	}
 
-	// 存储 token 到数据库
-	if m.db != nil {
-		if err := m.saveToken(token); err != nil {
-			return nil, fmt.Errorf("保存 token 失败: %w", err)
-		}
+	if token.AuthMode == "" {
+		token.AuthMode = AuthModeBearer
+	}
+
+	// 保存 token
+	if err := m.saveToken(token); err != nil {
+		return nil, fmt.Errorf("保存 token 失败: %w", err)
 	}
 
 	return token, nil
@@ -73,6 +146,16 @@ func (m *Manager) Add(token *Token) error {
 		return fmt.Errorf("token value 不能为空")
 	}
 
+	if err := ValidateScopes(token.Scopes); err != nil {
+		return err
+	}
+	if err := ValidateCIDRs(token.AllowedCIDRs); err != nil {
+		return err
+	}
+	if err := ValidateAuthMode(token.AuthMode); err != nil {
+		return err
+	}
+
 	// 如果没有 ID，生成一个
 	if token.ID == "" {
 		tokenID, err := generateRandomToken(8)
@@ -87,82 +170,39 @@ func (m *Manager) Add(token *Token) error {
 		token.CreatedAt = time.Now()
 	}
 
-	// 保存到数据库
-	if m.db != nil {
-		return m.saveToken(token)
+	if token.AuthMode == "" {
+		token.AuthMode = AuthModeBearer
 	}
 
-	return nil
+	return m.saveToken(token)
 }
 
 // Validate 验证 token 是否有效
 func (m *Manager) Validate(tokenValue string) bool {
-	if m.db == nil {
-		fmt.Printf("[DEBUG] Validate: 数据库连接为空\n")
-		return false
-	}
-
-	var expiresAtStr sql.NullString
-	err := m.db.QueryRow(`
-		SELECT expires_at FROM tokens WHERE value = ?
-	`, tokenValue).Scan(&expiresAtStr)
-
-	if err != nil {
-		fmt.Printf("[DEBUG] Validate: 查询数据库失败: %v\n", err)
+	token, ok := m.Get(tokenValue)
+	if !ok {
 		return false
 	}
 
-	fmt.Printf("[DEBUG] Validate: 找到token，过期时间: %s (valid: %v)\n", expiresAtStr.String, expiresAtStr.Valid)
-
 	// 如果没有设置过期时间，token 永久有效
-	if !expiresAtStr.Valid {
-		fmt.Printf("[DEBUG] Validate: token永不过期，返回true\n")
+	if token.ExpiresAt == nil {
 		return true
 	}
 
-	// 解析过期时间并比较（使用 UTC 时间）
-	// 尝试多种时间格式
-	var expiresAt time.Time
-
-	// 首先尝试 RFC3339 格式（ISO 8601）
-	expiresAt, err = time.Parse(time.RFC3339, expiresAtStr.String)
-	if err != nil {
-		// 如果失败，尝试简单的日期时间格式
-		expiresAt, err = time.Parse("2006-01-02 15:04:05", expiresAtStr.String)
-		if err != nil {
-			fmt.Printf("[DEBUG] Validate: 解析过期时间失败: %v\n", err)
-			return false
-		}
-	}
-
-	currentTime := time.Now().UTC()
-	fmt.Printf("[DEBUG] Validate: 当前UTC时间: %s, 过期UTC时间: %s\n",
-		currentTime.Format("2006-01-02 15:04:05"),
-		expiresAt.UTC().Format("2006-01-02 15:04:05"))
-
 	// 使用 UTC 时间进行比较
-	result := currentTime.Before(expiresAt.UTC())
-	fmt.Printf("[DEBUG] Validate: 比较结果: %v\n", result)
-	return result
+	return time.Now().UTC().Before(token.ExpiresAt.UTC())
 }
 
 // Revoke 撤销 token
 func (m *Manager) Revoke(tokenValue string) error {
-	if m.db == nil {
-		return fmt.Errorf("数据库未初始化")
-	}
-
-	result, err := m.db.Exec(`DELETE FROM tokens WHERE value = ?`, tokenValue)
+	deleted, err := m.store.DeleteByValue(tokenValue)
 	if err != nil {
 		return fmt.Errorf("撤销 token 失败: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("检查删除结果失败: %w", err)
-	}
+	m.Invalidate()
 
-	if rowsAffected == 0 {
+	if !deleted {
 		return fmt.Errorf("token 不存在")
 	}
 
@@ -171,21 +211,14 @@ func (m *Manager) Revoke(tokenValue string) error {
 
 // RevokeByID 通过 ID 撤销 token
 func (m *Manager) RevokeByID(tokenID string) error {
-	if m.db == nil {
-		return fmt.Errorf("数据库未初始化")
-	}
-
-	result, err := m.db.Exec(`DELETE FROM tokens WHERE id = ?`, tokenID)
+	deleted, err := m.store.DeleteByID(tokenID)
 	if err != nil {
 		return fmt.Errorf("撤销 token 失败: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("检查删除结果失败: %w", err)
-	}
+	m.Invalidate()
 
-	if rowsAffected == 0 {
+	if !deleted {
 		return fmt.Errorf("token ID %s 不存在", tokenID)
 	}
 
@@ -193,182 +226,91 @@ func (m *Manager) RevokeByID(tokenID string) error {
 }
 
 // List 列出所有 token
-func (m *Manager) List() []*Token {
-	if m.db == nil {
-		return []*Token{}
-	}
-
-	rows, err := m.db.Query(`
-		SELECT id, value, description, created_at, expires_at
-		FROM tokens
-		ORDER BY created_at DESC
-	`)
+func (m *Manager) List() ([]*Token, error) {
+	tokens, err := m.store.List()
 	if err != nil {
-		return []*Token{}
-	}
-	defer rows.Close()
-
-	var tokens []*Token
-	for rows.Next() {
-		var token Token
-		var createdAtStr, expiresAtStr sql.NullString
-
-		err := rows.Scan(
-			&token.ID,
-			&token.Value,
-			&token.Description,
-			&createdAtStr,
-			&expiresAtStr,
-		)
-		if err != nil {
-			continue
-		}
-
-		// 解析创建时间
-		if createdAtStr.Valid {
-			if parsedTime, err := time.Parse("2006-01-02 15:04:05", createdAtStr.String); err == nil {
-				token.CreatedAt = parsedTime.UTC()
-			}
-		}
-
-		// 解析过期时间
-		if expiresAtStr.Valid {
-			if parsedTime, err := time.Parse("2006-01-02 15:04:05", expiresAtStr.String); err == nil {
-				utcTime := parsedTime.UTC()
-				token.ExpiresAt = &utcTime
-			}
-		}
-
-		tokens = append(tokens, &token)
+		return nil, fmt.Errorf("列出 token 失败: %w", err)
 	}
-
-	return tokens
+	if tokens == nil {
+		return []*Token{}, nil
+	}
+	return tokens, nil
 }
 
 // Get 通过 value 获取 token
+// 存在的 token 会被缓存，直到存储中的 token 发生变更
 func (m *Manager) Get(tokenValue string) (*Token, bool) {
-	if m.db == nil {
-		return nil, false
-	}
-
-	var token Token
-	var createdAtStr, expiresAtStr sql.NullString
-
-	err := m.db.QueryRow(`
-		SELECT id, value, description, created_at, expires_at
-		FROM tokens
-		WHERE value = ?
-	`, tokenValue).Scan(
-		&token.ID,
-		&token.Value,
-		&token.Description,
-		&createdAtStr,
-		&expiresAtStr,
-	)
-
-	if err != nil {
-		return nil, false
-	}
+	token, err := m.Lookup(tokenValue)
+	return token, err == nil
+}
 
-	// 解析创建时间
-	if createdAtStr.Valid {
-		if parsedTime, err := time.Parse("2006-01-02 15:04:05", createdAtStr.String); err == nil {
-			token.CreatedAt = parsedTime.UTC()
+// Lookup 通过 value 获取 token，不存在时返回 ErrNotFound
+// 与 Get 不同，存储错误（例如加密密钥已被轮换导致解密失败）会原样返回，调用方可以区分无效 token 和服务端故障
+func (m *Manager) Lookup(tokenValue string) (*Token, error) {
+	cacheEnabled := m.syncCache()
+	if cacheEnabled {
+		if token, found := m.cache.lookup(tokenValue); found {
+			return token, nil
 		}
 	}
 
-	// 解析过期时间
-	if expiresAtStr.Valid {
-		if parsedTime, err := time.Parse("2006-01-02 15:04:05", expiresAtStr.String); err == nil {
-			utcTime := parsedTime.UTC()
-			token.ExpiresAt = &utcTime
-		}
+	// 不缓存不存在的结果：无效 token 的取值不受限制，缓存会无限增长
+	// （反复尝试无效 token 由认证失败锁定限制）
+	token, err := m.store.GetByValue(tokenValue)
+	if err != nil {
+		return nil, err
 	}
 
-	return &token, true
+	if cacheEnabled {
+		m.cache.store(tokenValue, token)
+	}
+	return token, nil
 }
 
 // GetByID 通过 ID 获取 token
 func (m *Manager) GetByID(tokenID string) (*Token, bool) {
-	if m.db == nil {
-		return nil, false
-	}
-
-	var token Token
-	var createdAtStr, expiresAtStr sql.NullString
-
-	err := m.db.QueryRow(`
-		SELECT id, value, description, created_at, expires_at
-		FROM tokens
-		WHERE id = ?
-	`, tokenID).Scan(
-		&token.ID,
-		&token.Value,
-		&token.Description,
-		&createdAtStr,
-		&expiresAtStr,
-	)
-
-	if err != nil {
-		return nil, false
-	}
-
-	// 解析创建时间
-	if createdAtStr.Valid {
-		if parsedTime, err := time.Parse("2006-01-02 15:04:05", createdAtStr.String); err == nil {
-			token.CreatedAt = parsedTime.UTC()
-		}
-	}
-
-	// 解析过期时间
-	if expiresAtStr.Valid {
-		if parsedTime, err := time.Parse("2006-01-02 15:04:05", expiresAtStr.String); err == nil {
-			utcTime := parsedTime.UTC()
-			token.ExpiresAt = &utcTime
-		}
-	}
+	token, err := m.LookupByID(tokenID)
+	return token, err == nil
+}
 
-	return &token, true
+// LookupByID 通过 ID 获取 token，不存在时返回 ErrNotFound，存储错误原样返回
+func (m *Manager) LookupByID(tokenID string) (*Token, error) {
+	return m.store.GetByID(tokenID)
 }
 
 // Clear 清除所有 token
-func (m *Manager) Clear() {
-	if m.db != nil {
-		m.db.Exec(`DELETE FROM tokens`)
+func (m *Manager) Clear() error {
+	if err := m.store.Clear(); err != nil {
+		return fmt.Errorf("清除 token 失败: %w", err)
 	}
+	m.Invalidate()
+	return nil
 }
 
 // Count 返回 token 数量
 func (m *Manager) Count() int {
-	if m.db == nil {
-		return 0
+	cacheEnabled := m.syncCache()
+	if cacheEnabled {
+		if count, found := m.cache.lookupCount(); found {
+			return count
+		}
 	}
 
-	var count int
-	err := m.db.QueryRow(`SELECT COUNT(*) FROM tokens`).Scan(&count)
+	count, err := m.store.Count()
 	if err != nil {
 		return 0
 	}
 
+	if cacheEnabled {
+		m.cache.storeCount(count)
+	}
 	return count
 }
 
-// saveToken 保存 token 到数据库
+// saveToken 保存 token 到存储
 func (m *Manager) saveToken(token *Token) error {
-	var expiresAt interface{}
-	if token.ExpiresAt != nil {
-		// 格式化为 UTC 时间字符串，便于 SQLite 处理
-		expiresAt = token.ExpiresAt.UTC().Format("2006-01-02 15:04:05")
-	}
-
-	// 同样格式化 created_at 时间
-	createdAt := token.CreatedAt.UTC().Format("2006-01-02 15:04:05")
-
-	_, err := m.db.Exec(`
-		INSERT OR REPLACE INTO tokens (id, value, description, created_at, expires_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, token.ID, token.Value, token.Description, createdAt, expiresAt)
-
+	err := m.store.Save(token)
+	m.Invalidate()
 	return err
 }
 
@@ -27,7 +27,11 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 		value TEXT UNIQUE NOT NULL,
 		description TEXT NOT NULL,
 		created_at DATETIME NOT NULL,
-		expires_at DATETIME
+		expires_at DATETIME,
+		scopes TEXT NOT NULL DEFAULT '',
+		allowed_cidrs TEXT NOT NULL DEFAULT '',
+		auth_mode TEXT NOT NULL DEFAULT 'bearer',
+		value_hash TEXT UNIQUE
 	);
 	`
 	_, err = db.Exec(schema)
@@ -232,7 +236,10 @@ func TestManager_List(t *testing.T) {
 	m.Generate("token 2", nil)
 	m.Generate("token 3", nil)
 
-	tokens := m.List()
+	tokens, err := m.List()
+	if err != nil {
+		t.Fatalf("列出 token 失败: %v", err)
+	}
 
 	if len(tokens) != 3 {
 		t.Errorf("期望列表长度为 3，得到 %d", len(tokens))
@@ -298,7 +305,9 @@ func TestManager_Clear(t *testing.T) {
 	m.Generate("token 1", nil)
 	m.Generate("token 2", nil)
 
-	m.Clear()
+	if err := m.Clear(); err != nil {
+		t.Fatalf("清除 token 失败: %v", err)
+	}
 
 	if m.Count() != 0 {
 		t.Errorf("期望 token 数量为 0，得到 %d", m.Count())